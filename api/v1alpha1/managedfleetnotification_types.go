@@ -44,8 +44,22 @@ type ManagedFleetNotificationSpec struct {
 	FleetNotification FleetNotification `json:"fleetNotification"`
 }
 
+// ManagedFleetNotificationStatus defines the observed state of ManagedFleetNotification
+type ManagedFleetNotificationStatus struct {
+	// ObservedGeneration is the most recent ManagedFleetNotification generation observed
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the ManagedFleetNotification's state
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:resource:shortName=mfn
 
 // ManagedFleetNotification is the Schema for the managedfleetnotifications API
@@ -53,7 +67,8 @@ type ManagedFleetNotification struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ManagedFleetNotificationSpec `json:"spec,omitempty"`
+	Spec   ManagedFleetNotificationSpec   `json:"spec,omitempty"`
+	Status ManagedFleetNotificationStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
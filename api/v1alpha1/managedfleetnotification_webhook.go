@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var managedfleetnotificationlog = logf.Log.WithName("managedfleetnotification-resource")
+
+// SetupWebhookWithManager registers the ManagedFleetNotification validating webhook with the manager
+func (r *ManagedFleetNotification) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		// The manager cache only holds the operator namespace, so the other ManagedFleetNotifications
+		// are read from the API server
+		WithValidator(&ManagedFleetNotificationValidator{Client: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-ocmagent-managed-openshift-io-v1alpha1-managedfleetnotification,mutating=false,failurePolicy=fail,sideEffects=None,groups=ocmagent.managed.openshift.io,resources=managedfleetnotifications,verbs=create;update,versions=v1alpha1,name=vmanagedfleetnotification.managed.openshift.io,admissionReviewVersions=v1
+
+//+kubebuilder:object:generate=false
+
+// ManagedFleetNotificationValidator validates ManagedFleetNotifications, including that their notification
+// name is not used by another ManagedFleetNotification in the same namespace
+type ManagedFleetNotificationValidator struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &ManagedFleetNotificationValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ManagedFleetNotificationValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, obj, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ManagedFleetNotificationValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	old, _ := oldObj.(*ManagedFleetNotification)
+	return nil, v.validate(ctx, newObj, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ManagedFleetNotificationValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate validates the ManagedFleetNotification, and the uniqueness of its notification name
+// unless an update keeps the name of old, which is nil on creation
+func (v *ManagedFleetNotificationValidator) validate(ctx context.Context, obj runtime.Object, old *ManagedFleetNotification) error {
+	mfn, ok := obj.(*ManagedFleetNotification)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ManagedFleetNotification but got a %T", obj))
+	}
+	managedfleetnotificationlog.V(1).Info("validate", "name", mfn.Name)
+
+	allErrs := mfn.validateFleetNotification()
+
+	if old == nil || old.Spec.FleetNotification.Name != mfn.Spec.FleetNotification.Name {
+		namePath := field.NewPath("spec", "fleetNotification", "name")
+		others := &ManagedFleetNotificationList{}
+		if err := v.Client.List(ctx, others, client.InNamespace(mfn.Namespace)); err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		for _, other := range others.Items {
+			if other.Name != mfn.Name && other.Spec.FleetNotification.Name == mfn.Spec.FleetNotification.Name {
+				allErrs = append(allErrs, field.Invalid(namePath, mfn.Spec.FleetNotification.Name,
+					fmt.Sprintf("is already used by ManagedFleetNotification %s", other.Name)))
+			}
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ManagedFleetNotification").GroupKind(), mfn.Name, allErrs)
+}
+
+// validateFleetNotification validates the notification of a ManagedFleetNotification on its own
+func (fn *ManagedFleetNotification) validateFleetNotification() field.ErrorList {
+	var allErrs field.ErrorList
+	path := field.NewPath("spec", "fleetNotification")
+	n := fn.Spec.FleetNotification
+	if n.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("name"), "a notification name must be set"))
+	}
+	allErrs = append(allErrs, validateNotificationSummary(path.Child("summary"), n.Summary)...)
+	allErrs = append(allErrs, validateNotificationBody(path.Child("notificationMessage"), n.NotificationMessage, false)...)
+	allErrs = append(allErrs, validateResendWait(path.Child("resendWait"), n.ResendWait)...)
+	return allErrs
+}
@@ -0,0 +1,129 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ManagedFleetNotification Webhook", func() {
+
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testValidator                *v1alpha1.ManagedFleetNotificationValidator
+		testManagedFleetNotification *v1alpha1.ManagedFleetNotification
+		existingNotifications        []v1alpha1.ManagedFleetNotification
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testValidator = &v1alpha1.ManagedFleetNotificationValidator{Client: mockClient}
+		testManagedFleetNotification = &v1alpha1.ManagedFleetNotification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "test-ns",
+			},
+			Spec: v1alpha1.ManagedFleetNotificationSpec{
+				FleetNotification: v1alpha1.FleetNotification{
+					Name:                "test-notification",
+					Summary:             "Test Summary",
+					NotificationMessage: "Test message for ${cluster_id}",
+					Severity:            "Info",
+					ResendWait:          1,
+				},
+			},
+		}
+		existingNotifications = nil
+		mockClient.EXPECT().List(gomock.Any(), gomock.Any(), client.InNamespace("test-ns")).DoAndReturn(
+			func(ctx context.Context, list *v1alpha1.ManagedFleetNotificationList, opts ...client.ListOption) error {
+				list.Items = existingNotifications
+				return nil
+			}).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("accepts a valid ManagedFleetNotification", func() {
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedFleetNotification)
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects a notification name used by another ManagedFleetNotification", func() {
+		other := testManagedFleetNotification.DeepCopy()
+		other.Name = "other"
+		existingNotifications = []v1alpha1.ManagedFleetNotification{*other}
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedFleetNotification)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("is already used by ManagedFleetNotification other"))
+	})
+
+	It("accepts an update of itself", func() {
+		existingNotifications = []v1alpha1.ManagedFleetNotification{*testManagedFleetNotification}
+		_, err := testValidator.ValidateUpdate(context.TODO(), testManagedFleetNotification, testManagedFleetNotification)
+		Expect(err).To(BeNil())
+	})
+
+	It("accepts an update of a ManagedFleetNotification that already shares its notification name", func() {
+		other := testManagedFleetNotification.DeepCopy()
+		other.Name = "other"
+		existingNotifications = []v1alpha1.ManagedFleetNotification{*other, *testManagedFleetNotification}
+		updated := testManagedFleetNotification.DeepCopy()
+		updated.Spec.FleetNotification.Summary = "Updated Summary"
+		_, err := testValidator.ValidateUpdate(context.TODO(), testManagedFleetNotification, updated)
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects an update renaming the notification to one used by another ManagedFleetNotification", func() {
+		other := testManagedFleetNotification.DeepCopy()
+		other.Name = "other"
+		other.Spec.FleetNotification.Name = "other-notification"
+		existingNotifications = []v1alpha1.ManagedFleetNotification{*other, *testManagedFleetNotification}
+		updated := testManagedFleetNotification.DeepCopy()
+		updated.Spec.FleetNotification.Name = "other-notification"
+		_, err := testValidator.ValidateUpdate(context.TODO(), testManagedFleetNotification, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("is already used by ManagedFleetNotification other"))
+	})
+
+	It("rejects a resend wait longer than a year", func() {
+		testManagedFleetNotification.Spec.FleetNotification.ResendWait = v1alpha1.MaxResendWaitHours + 1
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedFleetNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.fleetNotification.resendWait"))
+	})
+
+	It("rejects a summary longer than Service Log accepts", func() {
+		testManagedFleetNotification.Spec.FleetNotification.Summary = strings.Repeat("a", v1alpha1.MaxServiceLogSummaryLength+1)
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedFleetNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.fleetNotification.summary"))
+	})
+
+	It("rejects an empty notification message", func() {
+		testManagedFleetNotification.Spec.FleetNotification.NotificationMessage = ""
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedFleetNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.fleetNotification.notificationMessage"))
+	})
+
+	It("rejects an empty placeholder", func() {
+		testManagedFleetNotification.Spec.FleetNotification.NotificationMessage = "Test message for ${}"
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedFleetNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.fleetNotification.notificationMessage"))
+	})
+})
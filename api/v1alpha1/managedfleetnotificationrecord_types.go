@@ -54,6 +54,7 @@ type NotificationRecordItem struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:resource:shortName=mfnr
 
 // ManagedFleetNotificationRecord is the Schema for the managedfleetnotificationrecords API
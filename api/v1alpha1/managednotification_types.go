@@ -71,6 +71,43 @@ type ManagedNotificationStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	NotificationRecords NotificationRecords `json:"notificationRecords,omitempty"`
+
+	// ResolutionSummaries summarize how long the alert of each notification took to resolve,
+	// and how often it fired again shortly after resolving. They are maintained by the
+	// OCM Agent Operator from the transitions of the notification records.
+	// +kubebuilder:validation:Optional
+	ResolutionSummaries []NotificationResolutionSummary `json:"resolutionSummaries,omitempty"`
+}
+
+// NotificationResolutionSummary summarizes the firing to resolved transitions of the alert of a notification
+type NotificationResolutionSummary struct {
+	// Name of the notification
+	Name string `json:"name"`
+
+	// FiringSince is the time the alert started firing, while it is firing
+	// +kubebuilder:validation:Optional
+	FiringSince *metav1.Time `json:"firingSince,omitempty"`
+
+	// LastResolvedTime is the time the alert last resolved, up to which its resolutions were counted
+	// +kubebuilder:validation:Optional
+	LastResolvedTime *metav1.Time `json:"lastResolvedTime,omitempty"`
+
+	// ResolvedCount is the number of times the alert resolved after firing
+	// +kubebuilder:validation:Optional
+	ResolvedCount int32 `json:"resolvedCount,omitempty"`
+
+	// LastTimeToResolve is the time the alert fired for before it last resolved
+	// +kubebuilder:validation:Optional
+	LastTimeToResolve *metav1.Duration `json:"lastTimeToResolve,omitempty"`
+
+	// MeanTimeToResolve is the mean time the alert fired for before resolving
+	// +kubebuilder:validation:Optional
+	MeanTimeToResolve *metav1.Duration `json:"meanTimeToResolve,omitempty"`
+
+	// RecentFlaps are the times the alert fired again within the flapping window of resolving,
+	// over the flapping count window of the operator configuration
+	// +kubebuilder:validation:Optional
+	RecentFlaps []metav1.Time `json:"recentFlaps,omitempty"`
 }
 
 type NotificationRecords []NotificationRecord
@@ -99,6 +136,14 @@ type NotificationCondition struct {
 	// (brief) reason for the condition's last transition.
 	// +kubebuilder:validation:Optional
 	Reason string `json:"reason,omitempty"`
+
+	// Human readable message indicating details about the last transition.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// The generation of the ManagedNotification the condition was set for.
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 type NotificationRecord struct {
@@ -115,6 +160,7 @@ type NotificationRecord struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:resource:path=managednotifications,scope=Namespaced
 
 // ManagedNotification is the Schema for the managednotifications API
@@ -256,6 +302,17 @@ func (nrs NotificationRecords) GetNotificationRecord(name string) *NotificationR
 	return nil
 }
 
+// GetResolutionSummary returns the resolution summary of the notification matching the given
+// name, or nil if it has none
+func (m *ManagedNotificationStatus) GetResolutionSummary(name string) *NotificationResolutionSummary {
+	for i := range m.ResolutionSummaries {
+		if m.ResolutionSummaries[i].Name == name {
+			return &m.ResolutionSummaries[i]
+		}
+	}
+	return nil
+}
+
 // SetNotificationRecord adds or overwrites the supplied notification record
 func (nrs *NotificationRecords) SetNotificationRecord(rec NotificationRecord) {
 	rec.ServiceLogSentCount++
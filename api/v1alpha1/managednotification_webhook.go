@@ -0,0 +1,140 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var managednotificationlog = logf.Log.WithName("managednotification-resource")
+
+// SetupWebhookWithManager registers the ManagedNotification validating webhook with the manager
+func (r *ManagedNotification) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		// The manager cache only holds the operator namespace, so the other ManagedNotifications
+		// are read from the API server
+		WithValidator(&ManagedNotificationValidator{Client: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-ocmagent-managed-openshift-io-v1alpha1-managednotification,mutating=false,failurePolicy=fail,sideEffects=None,groups=ocmagent.managed.openshift.io,resources=managednotifications,verbs=create;update,versions=v1alpha1,name=vmanagednotification.managed.openshift.io,admissionReviewVersions=v1
+
+//+kubebuilder:object:generate=false
+
+// ManagedNotificationValidator validates ManagedNotifications, including that their notification
+// names are not used by another ManagedNotification in the same namespace
+type ManagedNotificationValidator struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &ManagedNotificationValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ManagedNotificationValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, obj, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ManagedNotificationValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	old, _ := oldObj.(*ManagedNotification)
+	return nil, v.validate(ctx, newObj, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ManagedNotificationValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate validates the ManagedNotification, and the uniqueness of the notification names that
+// it adds to old, which is nil on creation. A name that is kept by an update is not checked
+// again, so that a ManagedNotification that already shares a name can still be edited.
+func (v *ManagedNotificationValidator) validate(ctx context.Context, obj runtime.Object, old *ManagedNotification) error {
+	mn, ok := obj.(*ManagedNotification)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ManagedNotification but got a %T", obj))
+	}
+	managednotificationlog.V(1).Info("validate", "name", mn.Name)
+
+	allErrs := mn.validateNotifications()
+
+	existing := map[string]bool{}
+	if old != nil {
+		for _, n := range old.Spec.Notifications {
+			existing[n.Name] = true
+		}
+	}
+
+	// The OCM Agent uses the first notification it finds for an alert, so a notification
+	// name must also be unique across the ManagedNotifications of the namespace
+	others := &ManagedNotificationList{}
+	if err := v.Client.List(ctx, others, client.InNamespace(mn.Namespace)); err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	taken := map[string]string{}
+	for _, other := range others.Items {
+		if other.Name == mn.Name {
+			continue
+		}
+		for _, n := range other.Spec.Notifications {
+			taken[n.Name] = other.Name
+		}
+	}
+	notificationsPath := field.NewPath("spec", "notifications")
+	for i, n := range mn.Spec.Notifications {
+		if owner, found := taken[n.Name]; found && !existing[n.Name] {
+			allErrs = append(allErrs, field.Invalid(notificationsPath.Index(i).Child("name"), n.Name,
+				fmt.Sprintf("is already used by ManagedNotification %s", owner)))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ManagedNotification").GroupKind(), mn.Name, allErrs)
+}
+
+// validateNotifications validates the notifications of a ManagedNotification on their own
+func (m *ManagedNotification) validateNotifications() field.ErrorList {
+	var allErrs field.ErrorList
+	notificationsPath := field.NewPath("spec", "notifications")
+	seen := map[string]bool{}
+	for i, n := range m.Spec.Notifications {
+		path := notificationsPath.Index(i)
+		if n.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "a notification name must be set"))
+		} else if seen[n.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), n.Name))
+		}
+		seen[n.Name] = true
+		allErrs = append(allErrs, validateNotificationSummary(path.Child("summary"), n.Summary)...)
+		allErrs = append(allErrs, validateNotificationBody(path.Child("activeBody"), n.ActiveDesc, false)...)
+		allErrs = append(allErrs, validateNotificationBody(path.Child("resolvedBody"), n.ResolvedDesc, true)...)
+		allErrs = append(allErrs, validateResendWait(path.Child("resendWait"), n.ResendWait)...)
+	}
+	return allErrs
+}
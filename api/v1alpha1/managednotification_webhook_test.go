@@ -0,0 +1,142 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ManagedNotification Webhook", func() {
+
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testValidator           *v1alpha1.ManagedNotificationValidator
+		testManagedNotification *v1alpha1.ManagedNotification
+		existingNotifications   []v1alpha1.ManagedNotification
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testValidator = &v1alpha1.ManagedNotificationValidator{Client: mockClient}
+		testManagedNotification = &v1alpha1.ManagedNotification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "test-ns",
+			},
+			Spec: v1alpha1.ManagedNotificationSpec{
+				Notifications: []v1alpha1.Notification{
+					{
+						Name:         "test-notification",
+						Summary:      "Test Summary",
+						ActiveDesc:   "Test Firing in ${namespace}",
+						ResolvedDesc: "Test Resolved",
+						Severity:     "Info",
+						ResendWait:   1,
+					},
+				},
+			},
+		}
+		existingNotifications = nil
+		mockClient.EXPECT().List(gomock.Any(), gomock.Any(), client.InNamespace("test-ns")).DoAndReturn(
+			func(ctx context.Context, list *v1alpha1.ManagedNotificationList, opts ...client.ListOption) error {
+				list.Items = existingNotifications
+				return nil
+			}).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("accepts a valid ManagedNotification", func() {
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects duplicate notification names", func() {
+		testManagedNotification.Spec.Notifications = append(testManagedNotification.Spec.Notifications,
+			testManagedNotification.Spec.Notifications[0])
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.notifications[1].name"))
+	})
+
+	It("rejects a notification name used by another ManagedNotification", func() {
+		other := testManagedNotification.DeepCopy()
+		other.Name = "other"
+		existingNotifications = []v1alpha1.ManagedNotification{*other}
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is already used by ManagedNotification other"))
+	})
+
+	It("rejects an update adding a notification name used by another ManagedNotification", func() {
+		other := testManagedNotification.DeepCopy()
+		other.Name = "other"
+		other.Spec.Notifications[0].Name = "other-notification"
+		existingNotifications = []v1alpha1.ManagedNotification{*other, *testManagedNotification}
+		updated := testManagedNotification.DeepCopy()
+		updated.Spec.Notifications = append(updated.Spec.Notifications, other.Spec.Notifications[0])
+		_, err := testValidator.ValidateUpdate(context.TODO(), testManagedNotification, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.notifications[1].name"))
+		Expect(err.Error()).NotTo(ContainSubstring("spec.notifications[0].name"))
+	})
+
+	It("accepts an update of a ManagedNotification that already shares a notification name", func() {
+		other := testManagedNotification.DeepCopy()
+		other.Name = "other"
+		existingNotifications = []v1alpha1.ManagedNotification{*other, *testManagedNotification}
+		updated := testManagedNotification.DeepCopy()
+		updated.Spec.Notifications[0].Summary = "Updated Summary"
+		_, err := testValidator.ValidateUpdate(context.TODO(), testManagedNotification, updated)
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects a negative resend wait", func() {
+		testManagedNotification.Spec.Notifications[0].ResendWait = -1
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.notifications[0].resendWait"))
+	})
+
+	It("rejects an empty summary", func() {
+		testManagedNotification.Spec.Notifications[0].Summary = ""
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.notifications[0].summary"))
+	})
+
+	It("rejects a body longer than Service Log accepts", func() {
+		testManagedNotification.Spec.Notifications[0].ActiveDesc = strings.Repeat("a", v1alpha1.MaxServiceLogDescriptionLength+1)
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.notifications[0].activeBody"))
+	})
+
+	It("accepts an empty resolved body", func() {
+		testManagedNotification.Spec.Notifications[0].ResolvedDesc = ""
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects placeholders that can't be resolved", func() {
+		testManagedNotification.Spec.Notifications[0].ActiveDesc = "Test Firing in ${name-space}"
+		testManagedNotification.Spec.Notifications[0].ResolvedDesc = "Test Resolved in ${namespace"
+		_, err := testValidator.ValidateCreate(context.TODO(), testManagedNotification)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(And(ContainSubstring("spec.notifications[0].activeBody"), ContainSubstring("spec.notifications[0].resolvedBody")))
+	})
+})
@@ -0,0 +1,94 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// MaxServiceLogSummaryLength is the longest summary accepted by Service Log
+	MaxServiceLogSummaryLength = 255
+	// MaxServiceLogDescriptionLength is the longest description accepted by Service Log
+	MaxServiceLogDescriptionLength = 4000
+	// MaxResendWaitHours is the longest resend window of a notification, one year
+	MaxResendWaitHours = 24 * 365
+)
+
+var (
+	// placeholderRegexp matches the ${label} placeholders that the OCM Agent resolves from alert labels
+	placeholderRegexp = regexp.MustCompile(`\$\{([^}]*)\}`)
+	// labelNameRegexp matches a valid Prometheus alert label name
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// validateNotificationSummary validates a notification summary against the Service Log limits
+func validateNotificationSummary(fldPath *field.Path, summary string) field.ErrorList {
+	var allErrs field.ErrorList
+	if strings.TrimSpace(summary) == "" {
+		return append(allErrs, field.Required(fldPath, "a summary must be set"))
+	}
+	if len(summary) > MaxServiceLogSummaryLength {
+		allErrs = append(allErrs, field.TooLong(fldPath, summary, MaxServiceLogSummaryLength))
+	}
+	return append(allErrs, validatePlaceholders(fldPath, summary)...)
+}
+
+// validateNotificationBody validates a notification body against the Service Log limits.
+// An empty body is only accepted if it is optional.
+func validateNotificationBody(fldPath *field.Path, body string, optional bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if strings.TrimSpace(body) == "" {
+		if !optional {
+			allErrs = append(allErrs, field.Required(fldPath, "a body must be set"))
+		}
+		return allErrs
+	}
+	if len(body) > MaxServiceLogDescriptionLength {
+		allErrs = append(allErrs, field.TooLong(fldPath, body, MaxServiceLogDescriptionLength))
+	}
+	return append(allErrs, validatePlaceholders(fldPath, body)...)
+}
+
+// validateResendWait validates that a resend window is within sane bounds
+func validateResendWait(fldPath *field.Path, resendWait int32) field.ErrorList {
+	var allErrs field.ErrorList
+	if resendWait < 0 || resendWait > MaxResendWaitHours {
+		allErrs = append(allErrs, field.Invalid(fldPath, resendWait,
+			fmt.Sprintf("must be between 0 and %d hours", MaxResendWaitHours)))
+	}
+	return allErrs
+}
+
+// validatePlaceholders validates that every placeholder in a notification text can be resolved
+// by the OCM Agent, that is each one is terminated and refers to a valid alert label name
+func validatePlaceholders(fldPath *field.Path, text string) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, match := range placeholderRegexp.FindAllStringSubmatch(text, -1) {
+		if !labelNameRegexp.MatchString(match[1]) {
+			allErrs = append(allErrs, field.Invalid(fldPath, match[0], "placeholders must refer to a valid alert label name"))
+		}
+	}
+	if strings.Count(placeholderRegexp.ReplaceAllString(text, ""), "${") > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, text, "contains an unterminated placeholder"))
+	}
+	return allErrs
+}
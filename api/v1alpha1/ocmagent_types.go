@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,10 +25,34 @@ type AgentConfig struct {
 	// OcmBaseUrl defines the OCM api endpoint for OCM agent to access
 	OcmBaseUrl string `json:"ocmBaseUrl"`
 
-	// Services defines the supported OCM services, eg, service_log, cluster_management
+	// Services defines the supported OCM services, one of service_logs or clusters_mgmt
 	Services []string `json:"services"`
 }
 
+// AutoscalingConfig defines how the OCM Agent service is autoscaled
+type AutoscalingConfig struct {
+	// MinReplicas is the lower limit for the number of OCM Agent replicas, default to 1
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of OCM Agent replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization of the OCM Agent pods,
+	// as a percentage of the requested CPU. Defaults to 80 if neither target is set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the target average memory utilization of the OCM Agent pods,
+	// as a percentage of the requested memory
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
 // OcmAgentSpec defines the desired state of OcmAgent
 type OcmAgentSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -42,27 +67,171 @@ type OcmAgentSpec struct {
 	// TokenSecret points to the secret name which stores the access token to OCM server
 	TokenSecret string `json:"tokenSecret"`
 
-	// Replicas defines the replica count for the OCM Agent service
+	// Replicas defines the replica count for the OCM Agent service.
+	// It is not enforced while Autoscaling is set.
 	Replicas int32 `json:"replicas"`
 
+	// Autoscaling scales the OCM Agent service with a HorizontalPodAutoscaler instead of a fixed replica count
+	// +kubebuilder:validation:Optional
+	Autoscaling *AutoscalingConfig `json:"autoscaling,omitempty"`
+
 	// FleetMode indicates if the OCM agent is running in fleet mode, default to false
 	FleetMode bool `json:"fleetMode,omitempty"`
+
+	// Resources defines the compute resources of the OCM Agent container.
+	// Defaults to the operator's built-in requests and limits if not set.
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector restricts the OCM Agent pods to nodes with matching labels
+	// +kubebuilder:validation:Optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations defines the tolerations of the OCM Agent pods.
+	// Defaults to tolerating infra nodes if not set.
+	// +kubebuilder:validation:Optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the scheduling constraints of the OCM Agent pods.
+	// Defaults to preferring infra nodes if not set.
+	// +kubebuilder:validation:Optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describes how the OCM Agent pods are spread across topology domains
+	// +kubebuilder:validation:Optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the name of the priority class of the OCM Agent pods
+	// +kubebuilder:validation:Optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// TargetNamespace is the namespace the OCM Agent is deployed to.
+	// Defaults to the namespace of the OCM Agent Operator if not set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+}
+
+const (
+	// ConditionAvailable indicates that the OCM Agent deployment has its minimum number of replicas available
+	ConditionAvailable = "Available"
+	// ConditionProgressing indicates that the OCM Agent deployment is rolling out a change
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded indicates that the OCM Agent could not be reconciled or is failing to run
+	ConditionDegraded = "Degraded"
+	// ConditionResourceConflict indicates that a singleton resource, such as the CAMO ConfigMap,
+	// is managed for another OcmAgent and so is not managed for this one
+	ConditionResourceConflict = "ResourceConflict"
+)
+
+// ManagedResourceState describes the outcome of reconciling a resource managed for the OCM Agent
+type ManagedResourceState string
+
+const (
+	// ManagedResourceReconciled indicates that the resource matches its expected configuration
+	ManagedResourceReconciled ManagedResourceState = "Reconciled"
+	// ManagedResourceFailed indicates that the resource could not be reconciled
+	ManagedResourceFailed ManagedResourceState = "Failed"
+)
+
+// ManagedObjectReference identifies an object managed for the OCM Agent
+type ManagedObjectReference struct {
+	// APIVersion of the managed object
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the managed object
+	Kind string `json:"kind"`
+
+	// Namespace of the managed object
+	Namespace string `json:"namespace"`
+
+	// Name of the managed object
+	Name string `json:"name"`
+}
+
+// OcmAgentMode describes the mode that the OCM Agent is deployed in
+type OcmAgentMode string
+
+const (
+	// OcmAgentModeCluster indicates that the OCM Agent serves the cluster it runs on
+	OcmAgentModeCluster OcmAgentMode = "Cluster"
+	// OcmAgentModeFleet indicates that the OCM Agent serves a fleet of hosted clusters
+	OcmAgentModeFleet OcmAgentMode = "Fleet"
+)
+
+// ManagedResourceStatus records the reconcile state of a resource managed for the OCM Agent
+type ManagedResourceStatus struct {
+	// Kind of the managed resource
+	Kind string `json:"kind"`
+
+	// Name of the managed resource
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Enum={"Reconciled","Failed"}
+	// State of the managed resource after the last reconcile
+	State ManagedResourceState `json:"state"`
+
+	// LastError is the error encountered when the resource last failed to reconcile
+	// +kubebuilder:validation:Optional
+	LastError string `json:"lastError,omitempty"`
+
+	// LastSyncTime is the last time the state or error of the resource changed
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
 }
 
 // OcmAgentStatus defines the observed state of OcmAgent
 type OcmAgentStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
-
 	// ServiceStatus indicates the status of OCM Agent service
 	ServiceStatus string `json:"serviceStatus"`
 
+	// AvailableReplicas is the number of available OCM Agent pods
 	AvailableReplicas int32 `json:"availableReplicas"`
+
+	// ReadyReplicas is the number of ready OCM Agent pods
+	// +kubebuilder:validation:Optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// ObservedGeneration is the most recent OcmAgent generation observed by the controller
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the OCM Agent's state
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ManagedResources records the reconcile state of each resource managed for the OCM Agent
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=kind
+	// +listMapKey=name
+	ManagedResources []ManagedResourceStatus `json:"managedResources,omitempty"`
+
+	// Mode is the mode that the OCM Agent was last deployed in
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"Cluster","Fleet"}
+	Mode OcmAgentMode `json:"mode,omitempty"`
+
+	// LastModeTransitionTime is the last time the OCM Agent switched between modes
+	// +kubebuilder:validation:Optional
+	LastModeTransitionTime *metav1.Time `json:"lastModeTransitionTime,omitempty"`
+
+	// Inventory lists the objects that were managed for the OCM Agent after the last successful
+	// reconcile, any other object labelled as managed for the OcmAgent is removed
+	// +kubebuilder:validation:Optional
+	// +listType=atomic
+	Inventory []ManagedObjectReference `json:"inventory,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:resource:path=ocmagents,scope=Namespaced
+//+kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+//+kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.availableReplicas"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // OcmAgent is the Schema for the ocmagents API
 type OcmAgent struct {
@@ -0,0 +1,355 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+const (
+	// MigrateImmutableFieldsAnnotation allows the immutable fields of a running OcmAgent, such as
+	// its TokenSecret, to be changed when set to "true"
+	MigrateImmutableFieldsAnnotation = "ocmagent.managed.openshift.io/migrate-immutable-fields"
+)
+
+// SupportedServices are the OCM services that the OCM Agent can serve
+var SupportedServices = []string{"service_logs", "clusters_mgmt"}
+
+var ocmagentlog = logf.Log.WithName("ocmagent-resource")
+
+//+kubebuilder:object:generate=false
+
+// TargetNamespaceRulesFunc returns the permissions the operator needs in the target namespace
+// of an OcmAgent
+type TargetNamespaceRulesFunc func(ocmAgent OcmAgent) ([]rbacv1.PolicyRule, error)
+
+// SetupWebhookWithManager registers the OcmAgent defaulting and validating webhooks with the manager
+func (r *OcmAgent) SetupWebhookWithManager(mgr ctrl.Manager, operatorNamespace string, targetNamespaceRules TargetNamespaceRulesFunc) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&OcmAgentValidator{
+			Client:               mgr.GetClient(),
+			OperatorNamespace:    operatorNamespace,
+			TargetNamespaceRules: targetNamespaceRules,
+		}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-ocmagent-managed-openshift-io-v1alpha1-ocmagent,mutating=true,failurePolicy=fail,sideEffects=None,groups=ocmagent.managed.openshift.io,resources=ocmagents,verbs=create;update,versions=v1alpha1,name=mocmagent.managed.openshift.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &OcmAgent{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// The resources default to the operator's built-in requests and limits, and the autoscaling
+// limits and target to those of the HorizontalPodAutoscaler. The placement of the OCM Agent
+// pods depends on its replicas, so it is left to the operator.
+func (r *OcmAgent) Default() {
+	ocmagentlog.V(1).Info("default", "name", r.Name)
+
+	if r.Spec.Resources == nil {
+		resources := oah.BuildDefaultResources()
+		r.Spec.Resources = &resources
+	}
+
+	if as := r.Spec.Autoscaling; as != nil {
+		if as.MinReplicas == nil {
+			minReplicas := int32(oah.OCMAgentHPADefaultMinReplicas)
+			as.MinReplicas = &minReplicas
+		}
+		if as.TargetCPUUtilizationPercentage == nil && as.TargetMemoryUtilizationPercentage == nil {
+			cpu := int32(oah.OCMAgentHPADefaultCPUUtilization)
+			as.TargetCPUUtilizationPercentage = &cpu
+		}
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-ocmagent-managed-openshift-io-v1alpha1-ocmagent,mutating=false,failurePolicy=fail,sideEffects=None,groups=ocmagent.managed.openshift.io,resources=ocmagents,verbs=create;update,versions=v1alpha1,name=vocmagent.managed.openshift.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &OcmAgent{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *OcmAgent) ValidateCreate() (admission.Warnings, error) {
+	ocmagentlog.V(1).Info("validate create", "name", r.Name)
+	return nil, r.toInvalidError(r.validateSpec())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Only the fields changed by the update are validated, so that an OcmAgent stored before
+// a validation rule was added can still be updated and deleted.
+func (r *OcmAgent) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	ocmagentlog.V(1).Info("validate update", "name", r.Name)
+	oldOcmAgent, ok := old.(*OcmAgent)
+	if !ok {
+		return nil, r.toInvalidError(r.validateSpec())
+	}
+	// The finalizer of an OcmAgent being deleted must be removable whatever its spec
+	if r.DeletionTimestamp != nil || equality.Semantic.DeepEqual(r.Spec, oldOcmAgent.Spec) {
+		return nil, nil
+	}
+	allErrs := ratchet(r.validateSpec(), oldOcmAgent.validateSpec())
+	allErrs = append(allErrs, r.validateImmutableFields(oldOcmAgent)...)
+	return nil, r.toInvalidError(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *OcmAgent) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+//+kubebuilder:object:generate=false
+
+// OcmAgentValidator validates OcmAgents, including that the operator has been granted the
+// permissions it needs in their target namespace. The operator only holds its Role in its
+// own namespace, so an OcmAgent deployed to any other namespace would fail to reconcile.
+type OcmAgentValidator struct {
+	Client            client.Writer
+	OperatorNamespace string
+	// TargetNamespaceRules returns the permissions to check, which are derived from the
+	// resources the OCM Agent handler manages
+	TargetNamespaceRules TargetNamespaceRulesFunc
+}
+
+var _ webhook.CustomValidator = &OcmAgentValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *OcmAgentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ocmAgent, ok := obj.(*OcmAgent)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected an OcmAgent but got a %T", obj))
+	}
+	if warnings, err := ocmAgent.ValidateCreate(); err != nil {
+		return warnings, err
+	}
+	return nil, v.validateTargetNamespace(ctx, ocmAgent)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+// The target namespace is only checked again when the update changes it.
+func (v *OcmAgentValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	ocmAgent, ok := newObj.(*OcmAgent)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected an OcmAgent but got a %T", newObj))
+	}
+	if warnings, err := ocmAgent.ValidateUpdate(oldObj); err != nil {
+		return warnings, err
+	}
+	old, ok := oldObj.(*OcmAgent)
+	if ocmAgent.DeletionTimestamp != nil || (ok && old.Spec.TargetNamespace == ocmAgent.Spec.TargetNamespace) {
+		return nil, nil
+	}
+	return nil, v.validateTargetNamespace(ctx, ocmAgent)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *OcmAgentValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateTargetNamespace rejects a target namespace in which the operator is missing any of
+// the TargetNamespaceRules of the OcmAgent. The webhook is served by the operator, so the rules it is granted
+// in the namespace are those of its own service account.
+func (v *OcmAgentValidator) validateTargetNamespace(ctx context.Context, ocmAgent *OcmAgent) error {
+	targetNamespace := ocmAgent.Spec.TargetNamespace
+	if targetNamespace == "" || targetNamespace == v.OperatorNamespace {
+		return nil
+	}
+	requiredRules, err := v.TargetNamespaceRules(*ocmAgent)
+	if err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("failed to determine the permissions needed in namespace %s: %w", targetNamespace, err))
+	}
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: targetNamespace},
+	}
+	if err := v.Client.Create(ctx, review); err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("failed to review the operator permissions in namespace %s: %w", targetNamespace, err))
+	}
+	var missing []string
+	for _, rule := range requiredRules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				var verbs []string
+				for _, verb := range rule.Verbs {
+					if !allowedBy(review.Status.ResourceRules, group, resource, verb) {
+						verbs = append(verbs, verb)
+					}
+				}
+				if len(verbs) > 0 {
+					missing = append(missing, fmt.Sprintf("%s (%s)", qualifiedResource(group, resource), strings.Join(verbs, ", ")))
+				}
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return ocmAgent.toInvalidError(field.ErrorList{field.Forbidden(field.NewPath("spec", "targetNamespace"),
+		fmt.Sprintf("the operator has not been granted the permissions it needs in namespace %s, missing %s",
+			targetNamespace, strings.Join(missing, "; ")))})
+}
+
+// allowedBy returns whether the rules allow the verb on every object of the resource
+func allowedBy(rules []authorizationv1.ResourceRule, group, resource, verb string) bool {
+	for _, rule := range rules {
+		if len(rule.ResourceNames) == 0 && matchesRule(rule.APIGroups, group) &&
+			matchesRule(rule.Resources, resource) && matchesRule(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRule(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func qualifiedResource(group, resource string) string {
+	if group == "" {
+		return resource
+	}
+	return resource + "." + group
+}
+
+func (r *OcmAgent) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("OcmAgent").GroupKind(), r.Name, allErrs)
+}
+
+func (r *OcmAgent) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if strings.TrimSpace(r.Spec.OcmAgentImage) == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("ocmAgentImage"), "an OCM Agent image must be set"))
+	}
+
+	baseURLPath := specPath.Child("agentConfig", "ocmBaseUrl")
+	if u, err := url.ParseRequestURI(r.Spec.AgentConfig.OcmBaseUrl); err != nil {
+		allErrs = append(allErrs, field.Invalid(baseURLPath, r.Spec.AgentConfig.OcmBaseUrl, "must be an absolute URL"))
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(baseURLPath, r.Spec.AgentConfig.OcmBaseUrl, "must be an http or https URL with a host"))
+	}
+
+	servicesPath := specPath.Child("agentConfig", "services")
+	seen := map[string]bool{}
+	for i, service := range r.Spec.AgentConfig.Services {
+		if !isSupportedService(service) {
+			allErrs = append(allErrs, field.NotSupported(servicesPath.Index(i), service, SupportedServices))
+		} else if seen[service] {
+			allErrs = append(allErrs, field.Duplicate(servicesPath.Index(i), service))
+		}
+		seen[service] = true
+	}
+
+	if r.Spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), r.Spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if as := r.Spec.Autoscaling; as != nil && as.MinReplicas != nil && *as.MinReplicas > as.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("autoscaling", "minReplicas"), *as.MinReplicas, "must be less than or equal to maxReplicas"))
+	}
+
+	tokenSecretPath := specPath.Child("tokenSecret")
+	if r.Spec.TokenSecret == "" {
+		allErrs = append(allErrs, field.Required(tokenSecretPath, "a token secret name must be set"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(r.Spec.TokenSecret) {
+			allErrs = append(allErrs, field.Invalid(tokenSecretPath, r.Spec.TokenSecret, msg))
+		}
+		// The token secret is mounted into the OCM Agent pods alongside the agent and trusted CA
+		// bundle ConfigMaps, using each resource name as its volume name
+		if r.Spec.TokenSecret == r.Name+oah.ConfigMapSuffix || r.Spec.TokenSecret == oah.TrustedCaBundleConfigMapName {
+			allErrs = append(allErrs, field.Invalid(tokenSecretPath, r.Spec.TokenSecret, "must not match the name of a ConfigMap mounted into the OCM Agent"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateImmutableFields rejects changes to the fields that are immutable while the OCM Agent
+// runs, unless the migration annotation is set
+func (r *OcmAgent) validateImmutableFields(old *OcmAgent) field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Annotations[MigrateImmutableFieldsAnnotation] == "true" || !old.isRunning() {
+		return allErrs
+	}
+	if r.Spec.TokenSecret != old.Spec.TokenSecret {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "tokenSecret"),
+			"is immutable, set the "+MigrateImmutableFieldsAnnotation+" annotation to \"true\" to change it"))
+	}
+	return allErrs
+}
+
+// isRunning returns whether the OCM Agent of the OcmAgent is running, as reported in its status.
+// The fields that are immutable while it runs can be changed until it first becomes available.
+func (r *OcmAgent) isRunning() bool {
+	return r.Status.AvailableReplicas > 0 || meta.IsStatusConditionTrue(r.Status.Conditions, ConditionAvailable)
+}
+
+// ratchet returns the errors of the updated object that the old object didn't have, so that
+// a field that was invalid before the update is only rejected once it is changed
+func ratchet(allErrs, oldErrs field.ErrorList) field.ErrorList {
+	var ratcheted field.ErrorList
+	for _, err := range allErrs {
+		existing := false
+		for _, oldErr := range oldErrs {
+			if err.Type == oldErr.Type && err.Field == oldErr.Field && reflect.DeepEqual(err.BadValue, oldErr.BadValue) {
+				existing = true
+				break
+			}
+		}
+		if !existing {
+			ratcheted = append(ratcheted, err)
+		}
+	}
+	return ratcheted
+}
+
+func isSupportedService(service string) bool {
+	for _, s := range SupportedServices {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OcmAgent Webhook", func() {
+
+	var (
+		testOcmAgent *v1alpha1.OcmAgent
+	)
+
+	BeforeEach(func() {
+		testOcmAgent = &v1alpha1.OcmAgent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ocm-agent",
+				Namespace: "test-ns",
+			},
+			Spec: v1alpha1.OcmAgentSpec{
+				AgentConfig: v1alpha1.AgentConfig{
+					OcmBaseUrl: "https://api.example.com",
+					Services:   []string{"service_logs"},
+				},
+				OcmAgentImage: "quay.io/ocm-agent:latest",
+				TokenSecret:   "ocm-access-token",
+				Replicas:      1,
+			},
+		}
+	})
+
+	Context("When defaulting an OcmAgent", func() {
+		It("defaults the resources to the built-in requests and limits", func() {
+			testOcmAgent.Default()
+			Expect(*testOcmAgent.Spec.Resources).To(Equal(oah.BuildDefaultResources()))
+		})
+		It("keeps configured resources", func() {
+			resources := corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")}}
+			testOcmAgent.Spec.Resources = resources.DeepCopy()
+			testOcmAgent.Default()
+			Expect(*testOcmAgent.Spec.Resources).To(Equal(resources))
+		})
+		It("defaults the autoscaling limits and target", func() {
+			testOcmAgent.Spec.Autoscaling = &v1alpha1.AutoscalingConfig{MaxReplicas: 3}
+			testOcmAgent.Default()
+			Expect(*testOcmAgent.Spec.Autoscaling.MinReplicas).To(Equal(int32(1)))
+			Expect(*testOcmAgent.Spec.Autoscaling.TargetCPUUtilizationPercentage).To(Equal(int32(80)))
+		})
+		It("keeps a configured autoscaling target", func() {
+			memory := int32(70)
+			testOcmAgent.Spec.Autoscaling = &v1alpha1.AutoscalingConfig{MaxReplicas: 3, TargetMemoryUtilizationPercentage: &memory}
+			testOcmAgent.Default()
+			Expect(testOcmAgent.Spec.Autoscaling.TargetCPUUtilizationPercentage).To(BeNil())
+			Expect(*testOcmAgent.Spec.Autoscaling.TargetMemoryUtilizationPercentage).To(Equal(memory))
+		})
+	})
+
+	Context("When validating a new OcmAgent", func() {
+		It("accepts a valid spec", func() {
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(BeNil())
+		})
+		It("rejects an empty image", func() {
+			testOcmAgent.Spec.OcmAgentImage = ""
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.ocmAgentImage"))
+		})
+		It("rejects an OCM base URL that is not a URL", func() {
+			testOcmAgent.Spec.AgentConfig.OcmBaseUrl = "api.example.com"
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.agentConfig.ocmBaseUrl"))
+		})
+		It("rejects an OCM base URL without an http scheme", func() {
+			testOcmAgent.Spec.AgentConfig.OcmBaseUrl = "ftp://api.example.com"
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.agentConfig.ocmBaseUrl"))
+		})
+		It("rejects unknown services", func() {
+			testOcmAgent.Spec.AgentConfig.Services = []string{"service_logs", "unknown"}
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.agentConfig.services[1]"))
+		})
+		It("rejects negative replicas", func() {
+			testOcmAgent.Spec.Replicas = -1
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.replicas"))
+		})
+		It("rejects autoscaling with more minimum than maximum replicas", func() {
+			minReplicas := int32(4)
+			testOcmAgent.Spec.Autoscaling = &v1alpha1.AutoscalingConfig{MinReplicas: &minReplicas, MaxReplicas: 3}
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.autoscaling.minReplicas"))
+		})
+		It("rejects a token secret that collides with the agent ConfigMap", func() {
+			testOcmAgent.Spec.TokenSecret = testOcmAgent.Name + "-cm"
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.tokenSecret"))
+		})
+		It("reports every invalid field", func() {
+			testOcmAgent.Spec.OcmAgentImage = ""
+			testOcmAgent.Spec.Replicas = -1
+			_, err := testOcmAgent.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(And(ContainSubstring("spec.ocmAgentImage"), ContainSubstring("spec.replicas")))
+		})
+	})
+
+	Context("When updating an OcmAgent stored before the validation rules", func() {
+		var oldOcmAgent *v1alpha1.OcmAgent
+		BeforeEach(func() {
+			// An OcmAgent that was valid before the webhook, with an unknown service and a token
+			// secret name that is not a DNS-1123 subdomain
+			testOcmAgent.Spec.AgentConfig.Services = []string{"service_log"}
+			testOcmAgent.Spec.TokenSecret = "OCM_Access_Token"
+			oldOcmAgent = testOcmAgent.DeepCopy()
+		})
+		It("accepts the finalizer being added", func() {
+			testOcmAgent.Finalizers = []string{"ocmagent.managed.openshift.io/finalizer"}
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("accepts the finalizer being removed while it is deleted", func() {
+			oldOcmAgent.Finalizers = []string{"ocmagent.managed.openshift.io/finalizer"}
+			now := metav1.Now()
+			oldOcmAgent.DeletionTimestamp = &now
+			testOcmAgent.DeletionTimestamp = &now
+			testOcmAgent.Spec.Replicas = -1
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("accepts changes to the valid fields", func() {
+			testOcmAgent.Spec.Replicas = 2
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("rejects a change to another invalid value", func() {
+			testOcmAgent.Spec.AgentConfig.Services = []string{"service_log", "cluster"}
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.agentConfig.services[1]"))
+			Expect(err.Error()).NotTo(ContainSubstring("spec.agentConfig.services[0]"))
+		})
+		It("rejects a newly invalid field", func() {
+			testOcmAgent.Spec.Replicas = -1
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.replicas"))
+		})
+	})
+
+	Context("When validating an OcmAgent update", func() {
+		var oldOcmAgent *v1alpha1.OcmAgent
+		BeforeEach(func() {
+			oldOcmAgent = testOcmAgent.DeepCopy()
+			oldOcmAgent.Status.Conditions = []metav1.Condition{{Type: v1alpha1.ConditionAvailable, Status: metav1.ConditionTrue}}
+		})
+		It("accepts changes to mutable fields", func() {
+			testOcmAgent.Spec.Replicas = 2
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("rejects a token secret change", func() {
+			testOcmAgent.Spec.TokenSecret = "new-access-token"
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.tokenSecret"))
+		})
+		It("accepts a token secret change with the migration annotation", func() {
+			testOcmAgent.Spec.TokenSecret = "new-access-token"
+			testOcmAgent.Annotations = map[string]string{v1alpha1.MigrateImmutableFieldsAnnotation: "true"}
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("accepts a token secret change before the OCM Agent is running", func() {
+			oldOcmAgent.Status = v1alpha1.OcmAgentStatus{}
+			testOcmAgent.Spec.TokenSecret = "new-access-token"
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("rejects a token secret change while the OCM Agent has available replicas", func() {
+			oldOcmAgent.Status = v1alpha1.OcmAgentStatus{AvailableReplicas: 1}
+			testOcmAgent.Spec.TokenSecret = "new-access-token"
+			_, err := testOcmAgent.ValidateUpdate(oldOcmAgent)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When validating the target namespace of an OcmAgent", func() {
+		var (
+			mockClient    *clientmocks.MockClient
+			mockCtrl      *gomock.Controller
+			testValidator *v1alpha1.OcmAgentValidator
+			grantedRules  []authorizationv1.ResourceRule
+		)
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = clientmocks.NewMockClient(mockCtrl)
+			testValidator = &v1alpha1.OcmAgentValidator{
+				Client:            mockClient,
+				OperatorNamespace: "test-ns",
+				TargetNamespaceRules: func(ocmAgent v1alpha1.OcmAgent) ([]rbacv1.PolicyRule, error) {
+					return []rbacv1.PolicyRule{
+						{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"create", "delete", "get", "list", "patch"}},
+						{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"create", "delete", "get", "list", "patch"}},
+						{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles", "rolebindings"}, Verbs: []string{"create", "delete", "get", "list", "patch"}},
+						{APIGroups: []string{"ocmagent.managed.openshift.io"}, Resources: []string{"managednotifications"}, Verbs: []string{"get", "list", "watch", "patch", "update"}},
+					}, nil
+				},
+			}
+			// The rules of the operator Role
+			grantedRules = []authorizationv1.ResourceRule{
+				{APIGroups: []string{""}, Resources: []string{"services", "services/finalizers", "configmaps", "secrets", "serviceaccounts"}, Verbs: []string{"*"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}},
+				{APIGroups: []string{"monitoring.coreos.com"}, Resources: []string{"servicemonitors", "prometheusrules"}, Verbs: []string{"*"}},
+				{APIGroups: []string{"ocmagent.managed.openshift.io"}, Resources: []string{"*"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch"}},
+				{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"networkpolicies"}, Verbs: []string{"*"}},
+				{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: []string{"*"}},
+				{APIGroups: []string{"autoscaling"}, Resources: []string{"horizontalpodautoscalers"}, Verbs: []string{"*"}},
+				{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles", "rolebindings"}, Verbs: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+			}
+			testOcmAgent.Spec.TargetNamespace = "ocm-agent-target"
+		})
+		AfterEach(func() {
+			mockCtrl.Finish()
+		})
+		expectRulesReview := func() {
+			mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, review *authorizationv1.SelfSubjectRulesReview, opts ...client.CreateOption) error {
+					Expect(review.Spec.Namespace).To(Equal("ocm-agent-target"))
+					review.Status.ResourceRules = grantedRules
+					return nil
+				})
+		}
+		It("accepts a target namespace in which the operator has been granted its Role", func() {
+			expectRulesReview()
+			_, err := testValidator.ValidateCreate(context.TODO(), testOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("rejects a target namespace in which the operator has no permissions", func() {
+			grantedRules = nil
+			expectRulesReview()
+			_, err := testValidator.ValidateCreate(context.TODO(), testOcmAgent)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(And(ContainSubstring("spec.targetNamespace"), ContainSubstring("deployments.apps")))
+		})
+		It("reports the permissions missing in the target namespace", func() {
+			grantedRules[len(grantedRules)-1].Verbs = []string{"get", "list", "watch"}
+			expectRulesReview()
+			_, err := testValidator.ValidateCreate(context.TODO(), testOcmAgent)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("roles.rbac.authorization.k8s.io (create, delete, patch)"))
+			Expect(err.Error()).NotTo(ContainSubstring("deployments.apps"))
+		})
+		It("fails when the permissions can't be reviewed", func() {
+			mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(fmt.Errorf("fake error"))
+			_, err := testValidator.ValidateCreate(context.TODO(), testOcmAgent)
+			Expect(apierrors.IsInternalError(err)).To(BeTrue())
+		})
+		It("fails when the permissions needed can't be determined", func() {
+			testValidator.TargetNamespaceRules = func(ocmAgent v1alpha1.OcmAgent) ([]rbacv1.PolicyRule, error) {
+				return nil, fmt.Errorf("fake error")
+			}
+			_, err := testValidator.ValidateCreate(context.TODO(), testOcmAgent)
+			Expect(apierrors.IsInternalError(err)).To(BeTrue())
+		})
+		It("doesn't review the operator namespace", func() {
+			testOcmAgent.Spec.TargetNamespace = "test-ns"
+			_, err := testValidator.ValidateCreate(context.TODO(), testOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("validates the spec before the target namespace", func() {
+			testOcmAgent.Spec.Replicas = -1
+			_, err := testValidator.ValidateCreate(context.TODO(), testOcmAgent)
+			Expect(err.Error()).To(ContainSubstring("spec.replicas"))
+		})
+		It("doesn't review an unchanged target namespace on update", func() {
+			oldOcmAgent := testOcmAgent.DeepCopy()
+			testOcmAgent.Spec.Replicas = 2
+			_, err := testValidator.ValidateUpdate(context.TODO(), oldOcmAgent, testOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("reviews a changed target namespace on update", func() {
+			oldOcmAgent := testOcmAgent.DeepCopy()
+			oldOcmAgent.Spec.TargetNamespace = ""
+			grantedRules = nil
+			expectRulesReview()
+			_, err := testValidator.ValidateUpdate(context.TODO(), oldOcmAgent, testOcmAgent)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,205 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OcmAgentOperatorConfigName is the name of the single OcmAgentOperatorConfig read by the operator
+const OcmAgentOperatorConfigName = "cluster"
+
+// ControllerConfig tunes a controller of the OCM Agent Operator
+type ControllerConfig struct {
+	// MaxConcurrentReconciles is the number of resources the controller reconciles concurrently.
+	// It only takes effect when the operator starts.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	MaxConcurrentReconciles int32 `json:"maxConcurrentReconciles,omitempty"`
+
+	// RequeueInterval is the interval at which each resource is reconciled again after a
+	// successful reconcile, such as "30m". A resource is only reconciled on changes if it is 0s.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	RequeueInterval *metav1.Duration `json:"requeueInterval,omitempty"`
+}
+
+// OperatorFeature is the name of an optional feature of the OCM Agent Operator
+type OperatorFeature string
+
+const (
+	// FeatureOrphanCleanup removes the resources that are labelled as managed for an OcmAgent
+	// but are no longer desired after a reconcile
+	FeatureOrphanCleanup OperatorFeature = "OrphanCleanup"
+	// FeatureStaleRecordCleanup removes the ManagedFleetNotificationRecord items that were
+	// not updated within the stale record timeout
+	FeatureStaleRecordCleanup OperatorFeature = "StaleRecordCleanup"
+)
+
+// FeatureGate enables or disables an optional feature of the OCM Agent Operator
+type FeatureGate struct {
+	// Name of the feature
+	// +kubebuilder:validation:Enum={"OrphanCleanup","StaleRecordCleanup"}
+	Name OperatorFeature `json:"name"`
+
+	// Enabled indicates if the feature is enabled
+	Enabled bool `json:"enabled"`
+}
+
+// AlertThresholds sets the thresholds of the alerts of the PrometheusRule managed for an OcmAgent
+type AlertThresholds struct {
+	// PullSecretInvalidFor is the time that the cluster pull secret must be invalid before
+	// the OCMAgentPullSecretInvalid alert fires. Defaults to 15m.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="pullSecretInvalidFor must not be negative"
+	PullSecretInvalidFor *metav1.Duration `json:"pullSecretInvalidFor,omitempty"`
+
+	// AgentUnavailableFor is the time that the OCM Agent must have no available replicas
+	// before the OCMAgentUnavailable alert fires. Defaults to 10m.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('0s')",message="agentUnavailableFor must not be negative"
+	AgentUnavailableFor *metav1.Duration `json:"agentUnavailableFor,omitempty"`
+
+	// ReconcileErrors is the number of failed operations on the resources of an OcmAgent within
+	// the reconcile error window that fires the OCMAgentReconcileFailing alert. Defaults to 5.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ReconcileErrors int32 `json:"reconcileErrors,omitempty"`
+
+	// ReconcileErrorWindow is the window over which the failed operations are counted, of at
+	// least 1m. Defaults to 30m.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="reconcileErrorWindow must be at least 1m"
+	ReconcileErrorWindow *metav1.Duration `json:"reconcileErrorWindow,omitempty"`
+
+	// FleetRecordsStaleFor is the time since the last successful reconcile of the
+	// ManagedFleetNotificationRecords after which the OCMAgentFleetRecordsStale alert fires, of
+	// at least 1m. Defaults to 3h.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="fleetRecordsStaleFor must be at least 1m"
+	FleetRecordsStaleFor *metav1.Duration `json:"fleetRecordsStaleFor,omitempty"`
+}
+
+// OcmAgentOperatorConfigSpec defines the configuration of the OCM Agent Operator.
+// Any field that is not set takes the operator's default value.
+type OcmAgentOperatorConfigSpec struct {
+	// OcmAgentController tunes the controller of the OcmAgent resources.
+	// Its requeue interval defaults to 5m and its concurrency to 1.
+	// +kubebuilder:validation:Optional
+	OcmAgentController ControllerConfig `json:"ocmAgentController,omitempty"`
+
+	// FleetNotificationController tunes the controller of the ManagedFleetNotificationRecord
+	// resources. Its requeue interval defaults to 1h and its concurrency to 1.
+	// +kubebuilder:validation:Optional
+	FleetNotificationController ControllerConfig `json:"fleetNotificationController,omitempty"`
+
+	// NotificationRecordStaleTimeout is the time after the resend wait of a notification that a
+	// ManagedFleetNotificationRecord item is considered stale and is removed, such as "360h".
+	// Defaults to 360h.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	NotificationRecordStaleTimeout *metav1.Duration `json:"notificationRecordStaleTimeout,omitempty"`
+
+	// FlappingWindow is the time after the alert of a notification resolves within which it
+	// is considered to flap if it fires again, such as "1h". Defaults to 1h.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	FlappingWindow *metav1.Duration `json:"flappingWindow,omitempty"`
+
+	// FlappingCountWindow is the window over which the flaps of the alert of a notification are
+	// counted in the ManagedNotification status and metrics, such as "24h". Defaults to 24h.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Format=duration
+	FlappingCountWindow *metav1.Duration `json:"flappingCountWindow,omitempty"`
+
+	// AllowedIngressNamespaces are the names of the namespaces allowed to reach an OCM Agent
+	// running in fleet mode. Defaults to observatorium-mst-production and openshift-monitoring.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=32
+	// +kubebuilder:validation:items:MaxLength=63
+	// +kubebuilder:validation:items:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +listType=set
+	AllowedIngressNamespaces []string `json:"allowedIngressNamespaces,omitempty"`
+
+	// FeatureGates enables or disables optional features of the operator.
+	// Every feature is enabled by default.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	FeatureGates []FeatureGate `json:"featureGates,omitempty"`
+
+	// Alerts sets the thresholds of the alerts of the PrometheusRule managed for each OcmAgent
+	// +kubebuilder:validation:Optional
+	Alerts AlertThresholds `json:"alerts,omitempty"`
+}
+
+const (
+	// ConditionConfigApplied indicates that the configuration is in effect
+	ConditionConfigApplied = "Applied"
+)
+
+// OcmAgentOperatorConfigStatus defines the observed state of OcmAgentOperatorConfig
+type OcmAgentOperatorConfigStatus struct {
+	// ObservedGeneration is the most recent OcmAgentOperatorConfig generation observed by the operator
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the configuration's state
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Effective is the configuration in effect, including the default values
+	// +kubebuilder:validation:Optional
+	Effective *OcmAgentOperatorConfigSpec `json:"effective,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=ocmagentoperatorconfigs,scope=Cluster
+//+kubebuilder:validation:XValidation:rule="self.metadata.name == 'cluster'",message="the OcmAgentOperatorConfig must be named cluster"
+//+kubebuilder:printcolumn:name="Applied",type="string",JSONPath=".status.conditions[?(@.type==\"Applied\")].status"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// OcmAgentOperatorConfig is the Schema for the ocmagentoperatorconfigs API.
+// The operator is configured by the OcmAgentOperatorConfig named cluster.
+type OcmAgentOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OcmAgentOperatorConfigSpec   `json:"spec,omitempty"`
+	Status OcmAgentOperatorConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OcmAgentOperatorConfigList contains a list of OcmAgentOperatorConfig
+type OcmAgentOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OcmAgentOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OcmAgentOperatorConfig{}, &OcmAgentOperatorConfigList{})
+}
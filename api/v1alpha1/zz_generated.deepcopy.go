@@ -6,6 +6,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -29,6 +31,71 @@ func (in *AgentConfig) DeepCopy() *AgentConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertThresholds) DeepCopyInto(out *AlertThresholds) {
+	*out = *in
+	if in.PullSecretInvalidFor != nil {
+		in, out := &in.PullSecretInvalidFor, &out.PullSecretInvalidFor
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AgentUnavailableFor != nil {
+		in, out := &in.AgentUnavailableFor, &out.AgentUnavailableFor
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ReconcileErrorWindow != nil {
+		in, out := &in.ReconcileErrorWindow, &out.ReconcileErrorWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FleetRecordsStaleFor != nil {
+		in, out := &in.FleetRecordsStaleFor, &out.FleetRecordsStaleFor
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertThresholds.
+func (in *AlertThresholds) DeepCopy() *AlertThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingConfig) DeepCopyInto(out *AutoscalingConfig) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingConfig.
+func (in *AutoscalingConfig) DeepCopy() *AutoscalingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Conditions) DeepCopyInto(out *Conditions) {
 	{
@@ -50,6 +117,41 @@ func (in Conditions) DeepCopy() Conditions {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerConfig) DeepCopyInto(out *ControllerConfig) {
+	*out = *in
+	if in.RequeueInterval != nil {
+		in, out := &in.RequeueInterval, &out.RequeueInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerConfig.
+func (in *ControllerConfig) DeepCopy() *ControllerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeatureGate) DeepCopyInto(out *FeatureGate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureGate.
+func (in *FeatureGate) DeepCopy() *FeatureGate {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FleetNotification) DeepCopyInto(out *FleetNotification) {
 	*out = *in
@@ -71,6 +173,7 @@ func (in *ManagedFleetNotification) DeepCopyInto(out *ManagedFleetNotification)
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedFleetNotification.
@@ -219,6 +322,28 @@ func (in *ManagedFleetNotificationSpec) DeepCopy() *ManagedFleetNotificationSpec
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedFleetNotificationStatus) DeepCopyInto(out *ManagedFleetNotificationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedFleetNotificationStatus.
+func (in *ManagedFleetNotificationStatus) DeepCopy() *ManagedFleetNotificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedFleetNotificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedNotification) DeepCopyInto(out *ManagedNotification) {
 	*out = *in
@@ -308,6 +433,13 @@ func (in *ManagedNotificationStatus) DeepCopyInto(out *ManagedNotificationStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ResolutionSummaries != nil {
+		in, out := &in.ResolutionSummaries, &out.ResolutionSummaries
+		*out = make([]NotificationResolutionSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedNotificationStatus.
@@ -320,6 +452,37 @@ func (in *ManagedNotificationStatus) DeepCopy() *ManagedNotificationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedObjectReference) DeepCopyInto(out *ManagedObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedObjectReference.
+func (in *ManagedObjectReference) DeepCopy() *ManagedObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedResourceStatus) DeepCopyInto(out *ManagedResourceStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedResourceStatus.
+func (in *ManagedResourceStatus) DeepCopy() *ManagedResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Notification) DeepCopyInto(out *Notification) {
 	*out = *in
@@ -438,13 +601,53 @@ func (in NotificationRecords) DeepCopy() NotificationRecords {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationResolutionSummary) DeepCopyInto(out *NotificationResolutionSummary) {
+	*out = *in
+	if in.FiringSince != nil {
+		in, out := &in.FiringSince, &out.FiringSince
+		*out = (*in).DeepCopy()
+	}
+	if in.LastResolvedTime != nil {
+		in, out := &in.LastResolvedTime, &out.LastResolvedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTimeToResolve != nil {
+		in, out := &in.LastTimeToResolve, &out.LastTimeToResolve
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MeanTimeToResolve != nil {
+		in, out := &in.MeanTimeToResolve, &out.MeanTimeToResolve
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RecentFlaps != nil {
+		in, out := &in.RecentFlaps, &out.RecentFlaps
+		*out = make([]v1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationResolutionSummary.
+func (in *NotificationResolutionSummary) DeepCopy() *NotificationResolutionSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationResolutionSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OcmAgent) DeepCopyInto(out *OcmAgent) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OcmAgent.
@@ -497,10 +700,175 @@ func (in *OcmAgentList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OcmAgentOperatorConfig) DeepCopyInto(out *OcmAgentOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OcmAgentOperatorConfig.
+func (in *OcmAgentOperatorConfig) DeepCopy() *OcmAgentOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OcmAgentOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OcmAgentOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OcmAgentOperatorConfigList) DeepCopyInto(out *OcmAgentOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OcmAgentOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OcmAgentOperatorConfigList.
+func (in *OcmAgentOperatorConfigList) DeepCopy() *OcmAgentOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(OcmAgentOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OcmAgentOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OcmAgentOperatorConfigSpec) DeepCopyInto(out *OcmAgentOperatorConfigSpec) {
+	*out = *in
+	in.OcmAgentController.DeepCopyInto(&out.OcmAgentController)
+	in.FleetNotificationController.DeepCopyInto(&out.FleetNotificationController)
+	if in.NotificationRecordStaleTimeout != nil {
+		in, out := &in.NotificationRecordStaleTimeout, &out.NotificationRecordStaleTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FlappingWindow != nil {
+		in, out := &in.FlappingWindow, &out.FlappingWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FlappingCountWindow != nil {
+		in, out := &in.FlappingCountWindow, &out.FlappingCountWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AllowedIngressNamespaces != nil {
+		in, out := &in.AllowedIngressNamespaces, &out.AllowedIngressNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make([]FeatureGate, len(*in))
+		copy(*out, *in)
+	}
+	in.Alerts.DeepCopyInto(&out.Alerts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OcmAgentOperatorConfigSpec.
+func (in *OcmAgentOperatorConfigSpec) DeepCopy() *OcmAgentOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OcmAgentOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OcmAgentOperatorConfigStatus) DeepCopyInto(out *OcmAgentOperatorConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Effective != nil {
+		in, out := &in.Effective, &out.Effective
+		*out = new(OcmAgentOperatorConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OcmAgentOperatorConfigStatus.
+func (in *OcmAgentOperatorConfigStatus) DeepCopy() *OcmAgentOperatorConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OcmAgentOperatorConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OcmAgentSpec) DeepCopyInto(out *OcmAgentSpec) {
 	*out = *in
 	in.AgentConfig.DeepCopyInto(&out.AgentConfig)
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OcmAgentSpec.
@@ -516,6 +884,29 @@ func (in *OcmAgentSpec) DeepCopy() *OcmAgentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OcmAgentStatus) DeepCopyInto(out *OcmAgentStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ManagedResources != nil {
+		in, out := &in.ManagedResources, &out.ManagedResources
+		*out = make([]ManagedResourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastModeTransitionTime != nil {
+		in, out := &in.LastModeTransitionTime, &out.LastModeTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]ManagedObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OcmAgentStatus.
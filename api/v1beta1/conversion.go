@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+)
+
+// The v1beta1 types convert through the v1alpha1 hub. Every v1beta1 field has a v1alpha1
+// counterpart, so that objects round-trip between the two versions without loss.
+
+// hoursToDuration converts a v1alpha1 resend window in hours to a duration
+func hoursToDuration(hours int32) metav1.Duration {
+	return metav1.Duration{Duration: time.Duration(hours) * time.Hour}
+}
+
+// durationToHours converts a duration to a v1alpha1 resend window in hours. The v1beta1
+// resend windows are validated to be whole hours.
+func durationToHours(d metav1.Duration) int32 {
+	return int32(d.Duration / time.Hour)
+}
+
+// unsetTransitionTime is the last transition time of a condition converted from a v1alpha1
+// condition without one, as the last transition time of a standard condition is required
+var unsetTransitionTime = metav1.Unix(0, 0).Rfc3339Copy()
+
+// convertConditionToHub converts a standard condition to a v1alpha1 notification condition.
+// The placeholders of the reason and last transition time that convertConditionFromHub sets
+// are dropped again.
+func convertConditionToHub(src metav1.Condition) v1alpha1.NotificationCondition {
+	dst := v1alpha1.NotificationCondition{
+		Type:               v1alpha1.NotificationConditionType(src.Type),
+		Status:             corev1.ConditionStatus(src.Status),
+		Reason:             src.Reason,
+		Message:            src.Message,
+		ObservedGeneration: src.ObservedGeneration,
+	}
+	if dst.Reason == ConditionReasonUnset {
+		dst.Reason = ""
+	}
+	if !src.LastTransitionTime.IsZero() && !src.LastTransitionTime.Equal(&unsetTransitionTime) {
+		t := src.LastTransitionTime
+		dst.LastTransitionTime = &t
+	}
+	return dst
+}
+
+// convertConditionFromHub converts a v1alpha1 notification condition to a standard condition.
+// The reason and last transition time of a v1alpha1 condition are optional, while they are
+// required in a standard condition, so placeholders are set for the missing ones.
+func convertConditionFromHub(src v1alpha1.NotificationCondition) metav1.Condition {
+	dst := metav1.Condition{
+		Type:               string(src.Type),
+		Status:             metav1.ConditionStatus(src.Status),
+		Reason:             src.Reason,
+		Message:            src.Message,
+		ObservedGeneration: src.ObservedGeneration,
+		LastTransitionTime: unsetTransitionTime,
+	}
+	if dst.Reason == "" {
+		dst.Reason = ConditionReasonUnset
+	}
+	if src.LastTransitionTime != nil {
+		dst.LastTransitionTime = *src.LastTransitionTime
+	}
+	return dst
+}
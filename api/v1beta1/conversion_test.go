@@ -0,0 +1,261 @@
+package v1beta1_test
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/api/v1beta1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API Conversion", func() {
+
+	var (
+		testTime    metav1.Time
+		testObjMeta metav1.ObjectMeta
+	)
+
+	BeforeEach(func() {
+		testTime = metav1.NewTime(time.Now().Truncate(time.Second))
+		testObjMeta = metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "test-ns",
+			Labels:      map[string]string{"app": "test"},
+			Annotations: map[string]string{"test": "true"},
+			Generation:  2,
+		}
+	})
+
+	Context("When converting an OcmAgent", func() {
+		var hub *v1alpha1.OcmAgent
+		BeforeEach(func() {
+			minReplicas := int32(2)
+			cpu := int32(70)
+			hub = &v1alpha1.OcmAgent{
+				ObjectMeta: testObjMeta,
+				Spec: v1alpha1.OcmAgentSpec{
+					AgentConfig: v1alpha1.AgentConfig{
+						OcmBaseUrl: "https://api.example.com",
+						Services:   []string{"service_logs"},
+					},
+					OcmAgentImage: "quay.io/ocm-agent:latest",
+					TokenSecret:   "ocm-access-token",
+					Replicas:      1,
+					Autoscaling: &v1alpha1.AutoscalingConfig{
+						MinReplicas:                    &minReplicas,
+						MaxReplicas:                    3,
+						TargetCPUUtilizationPercentage: &cpu,
+					},
+					FleetMode:         true,
+					NodeSelector:      map[string]string{"node": "infra"},
+					Tolerations:       []corev1.Toleration{{Key: "infra", Effect: corev1.TaintEffectNoSchedule}},
+					PriorityClassName: "high",
+					TargetNamespace:   "ocm-agent-staging",
+				},
+				Status: v1alpha1.OcmAgentStatus{
+					ServiceStatus:      "Ready",
+					AvailableReplicas:  1,
+					ReadyReplicas:      1,
+					ObservedGeneration: 2,
+					Conditions: []metav1.Condition{
+						{Type: v1alpha1.ConditionAvailable, Status: metav1.ConditionTrue, Reason: "MinimumReplicasAvailable", LastTransitionTime: testTime},
+					},
+					ManagedResources: []v1alpha1.ManagedResourceStatus{
+						{Kind: "Deployment", Name: "test", State: v1alpha1.ManagedResourceReconciled, LastSyncTime: testTime},
+						{Kind: "Secret", Name: "ocm-access-token", State: v1alpha1.ManagedResourceFailed, LastError: "forbidden", LastSyncTime: testTime},
+					},
+					Mode:                   v1alpha1.OcmAgentModeFleet,
+					LastModeTransitionTime: &testTime,
+					Inventory: []v1alpha1.ManagedObjectReference{
+						{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "test-ns", Name: "test"},
+					},
+				},
+			}
+		})
+		It("round-trips from the hub without loss", func() {
+			spoke := &v1beta1.OcmAgent{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.AgentConfig.OCMBaseURL).To(Equal(hub.Spec.AgentConfig.OcmBaseUrl))
+			restored := &v1alpha1.OcmAgent{}
+			Expect(spoke.ConvertTo(restored)).To(Succeed())
+			Expect(restored).To(Equal(hub))
+		})
+		It("round-trips to the hub without loss", func() {
+			spoke := &v1beta1.OcmAgent{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			intermediate := &v1alpha1.OcmAgent{}
+			Expect(spoke.ConvertTo(intermediate)).To(Succeed())
+			restored := &v1beta1.OcmAgent{}
+			Expect(restored.ConvertFrom(intermediate)).To(Succeed())
+			Expect(restored).To(Equal(spoke))
+		})
+	})
+
+	Context("When converting a ManagedNotification", func() {
+		var hub *v1alpha1.ManagedNotification
+		BeforeEach(func() {
+			hub = &v1alpha1.ManagedNotification{
+				ObjectMeta: testObjMeta,
+				Spec: v1alpha1.ManagedNotificationSpec{
+					Notifications: []v1alpha1.Notification{
+						{
+							Name:         "test-notification",
+							Summary:      "Test Summary",
+							ActiveDesc:   "Test Firing",
+							ResolvedDesc: "Test Resolved",
+							Severity:     v1alpha1.SeverityInfo,
+							ResendWait:   24,
+						},
+						{
+							Name:       "test-notification-no-resend-wait",
+							Summary:    "Test Summary",
+							ActiveDesc: "Test Firing",
+							Severity:   v1alpha1.SeverityWarning,
+						},
+					},
+				},
+				Status: v1alpha1.ManagedNotificationStatus{
+					NotificationRecords: v1alpha1.NotificationRecords{
+						{
+							Name:                "test-notification",
+							ServiceLogSentCount: 3,
+							Conditions: v1alpha1.Conditions{
+								{Type: v1alpha1.ConditionAlertFiring, Status: corev1.ConditionTrue, LastTransitionTime: &testTime, Reason: "Alert is firing"},
+								{Type: v1alpha1.ConditionServiceLogSent, Status: corev1.ConditionFalse, Reason: "Service log not sent"},
+							},
+						},
+					},
+					ResolutionSummaries: []v1alpha1.NotificationResolutionSummary{
+						{
+							Name:              "test-notification",
+							FiringSince:       &testTime,
+							LastResolvedTime:  &testTime,
+							ResolvedCount:     2,
+							LastTimeToResolve: &metav1.Duration{Duration: time.Hour},
+							MeanTimeToResolve: &metav1.Duration{Duration: 90 * time.Minute},
+							RecentFlaps:       []metav1.Time{testTime},
+						},
+					},
+				},
+			}
+		})
+		It("converts the resend wait to a duration", func() {
+			spoke := &v1beta1.ManagedNotification{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Notifications[0].ResendWait.Duration).To(Equal(24 * time.Hour))
+			Expect(spoke.Status.NotificationRecords[0].Conditions[0].Message).To(BeEmpty())
+			Expect(spoke.Status.NotificationRecords[0].Conditions[0].Reason).To(Equal("Alert is firing"))
+		})
+		It("round-trips from the hub without loss", func() {
+			spoke := &v1beta1.ManagedNotification{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			restored := &v1alpha1.ManagedNotification{}
+			Expect(spoke.ConvertTo(restored)).To(Succeed())
+			Expect(restored).To(Equal(hub))
+		})
+		It("sets the required fields of a condition without a reason or transition time", func() {
+			hub.Status.NotificationRecords[0].Conditions = v1alpha1.Conditions{
+				{Type: v1alpha1.ConditionAlertResolved, Status: corev1.ConditionFalse},
+			}
+			spoke := &v1beta1.ManagedNotification{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			condition := spoke.Status.NotificationRecords[0].Conditions[0]
+			Expect(condition.Reason).To(Equal(v1beta1.ConditionReasonUnset))
+			Expect(condition.LastTransitionTime.IsZero()).To(BeFalse())
+			restored := &v1alpha1.ManagedNotification{}
+			Expect(spoke.ConvertTo(restored)).To(Succeed())
+			Expect(restored).To(Equal(hub))
+			Expect(restored.Status.NotificationRecords[0].Conditions[0].Reason).To(BeEmpty())
+			Expect(restored.Status.NotificationRecords[0].Conditions[0].LastTransitionTime).To(BeNil())
+		})
+		It("round-trips to the hub without loss", func() {
+			spoke := &v1beta1.ManagedNotification{
+				ObjectMeta: testObjMeta,
+				Spec: v1beta1.ManagedNotificationSpec{
+					Notifications: []v1beta1.Notification{
+						{Name: "test-notification", Summary: "Test Summary", ActiveBody: "Test Firing", Severity: v1beta1.SeverityInfo, ResendWait: metav1.Duration{Duration: 2 * time.Hour}},
+					},
+				},
+				Status: v1beta1.ManagedNotificationStatus{
+					NotificationRecords: []v1beta1.NotificationRecord{
+						{
+							Name: "test-notification",
+							Conditions: []metav1.Condition{
+								{Type: v1beta1.ConditionAlertFiring, Status: metav1.ConditionTrue, Reason: "AlertFiring", Message: "Alert is firing", ObservedGeneration: 2, LastTransitionTime: testTime},
+							},
+						},
+					},
+					ResolutionSummaries: []v1beta1.NotificationResolutionSummary{
+						{Name: "test-notification", ResolvedCount: 1, LastResolvedTime: &testTime, LastTimeToResolve: &metav1.Duration{Duration: time.Hour}},
+					},
+				},
+			}
+			intermediate := &v1alpha1.ManagedNotification{}
+			Expect(spoke.ConvertTo(intermediate)).To(Succeed())
+			restored := &v1beta1.ManagedNotification{}
+			Expect(restored.ConvertFrom(intermediate)).To(Succeed())
+			Expect(restored).To(Equal(spoke))
+		})
+	})
+
+	Context("When converting a ManagedFleetNotification", func() {
+		It("round-trips from the hub without loss", func() {
+			hub := &v1alpha1.ManagedFleetNotification{
+				ObjectMeta: testObjMeta,
+				Spec: v1alpha1.ManagedFleetNotificationSpec{
+					FleetNotification: v1alpha1.FleetNotification{
+						Name:                "test-notification",
+						Summary:             "Test Summary",
+						NotificationMessage: "Test message",
+						Severity:            v1alpha1.SeverityError,
+						ResendWait:          48,
+					},
+				},
+				Status: v1alpha1.ManagedFleetNotificationStatus{
+					ObservedGeneration: 2,
+					Conditions:         []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready", LastTransitionTime: testTime}},
+				},
+			}
+			spoke := &v1beta1.ManagedFleetNotification{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.FleetNotification.ResendWait.Duration).To(Equal(48 * time.Hour))
+			restored := &v1alpha1.ManagedFleetNotification{}
+			Expect(spoke.ConvertTo(restored)).To(Succeed())
+			Expect(restored).To(Equal(hub))
+		})
+	})
+
+	Context("When converting a ManagedFleetNotificationRecord", func() {
+		It("round-trips from the hub without loss", func() {
+			hub := &v1alpha1.ManagedFleetNotificationRecord{
+				ObjectMeta: testObjMeta,
+				Status: v1alpha1.ManagedFleetNotificationRecordStatus{
+					ManagementCluster: "test-mc",
+					NotificationRecordByName: []v1alpha1.NotificationRecordByName{
+						{
+							NotificationName: "test-notification",
+							ResendWait:       24,
+							NotificationRecordItems: []v1alpha1.NotificationRecordItem{
+								{HostedClusterID: "test-hc", ServiceLogSentCount: 1, LastTransitionTime: &testTime},
+								{HostedClusterID: "test-hc-2"},
+							},
+						},
+						{
+							NotificationName: "test-notification-empty",
+						},
+					},
+				},
+			}
+			spoke := &v1beta1.ManagedFleetNotificationRecord{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			restored := &v1alpha1.ManagedFleetNotificationRecord{}
+			Expect(spoke.ConvertTo(restored)).To(Succeed())
+			Expect(restored).To(Equal(hub))
+		})
+	})
+})
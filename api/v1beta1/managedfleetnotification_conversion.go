@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+)
+
+// ConvertTo converts this ManagedFleetNotification to the hub version (v1alpha1)
+func (src *ManagedFleetNotification) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ManagedFleetNotification)
+	dst.ObjectMeta = src.ObjectMeta
+
+	n := src.Spec.FleetNotification
+	dst.Spec = v1alpha1.ManagedFleetNotificationSpec{
+		FleetNotification: v1alpha1.FleetNotification{
+			Name:                n.Name,
+			Summary:             n.Summary,
+			NotificationMessage: n.NotificationMessage,
+			Severity:            v1alpha1.NotificationSeverity(n.Severity),
+			ResendWait:          durationToHours(n.ResendWait),
+		},
+	}
+	dst.Status = v1alpha1.ManagedFleetNotificationStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this ManagedFleetNotification
+func (dst *ManagedFleetNotification) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ManagedFleetNotification)
+	dst.ObjectMeta = src.ObjectMeta
+
+	n := src.Spec.FleetNotification
+	dst.Spec = ManagedFleetNotificationSpec{
+		FleetNotification: FleetNotification{
+			Name:                n.Name,
+			Summary:             n.Summary,
+			NotificationMessage: n.NotificationMessage,
+			Severity:            NotificationSeverity(n.Severity),
+			ResendWait:          hoursToDuration(n.ResendWait),
+		},
+	}
+	dst.Status = ManagedFleetNotificationStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FleetNotification defines a Service Log notification sent for an alert of a hosted cluster
+type FleetNotification struct {
+	// Name of the notification, used to associate it with an alert
+	Name string `json:"name"`
+
+	// Summary is the summary line of the Service Log notification
+	Summary string `json:"summary"`
+
+	// NotificationMessage is the body of the Service Log notification sent when the alert is firing
+	NotificationMessage string `json:"notificationMessage"`
+
+	// Severity is the severity of the Service Log notification
+	// +kubebuilder:validation:Enum={"Debug","Info","Warning","Error","Fatal"}
+	Severity NotificationSeverity `json:"severity"`
+
+	// ResendWait is the minimum time that must elapse between Service Log notifications sent for a firing alert,
+	// in whole hours
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+h(0m(0s)?)?|0s?)$`
+	ResendWait metav1.Duration `json:"resendWait"`
+}
+
+// ManagedFleetNotificationSpec defines the desired state of ManagedFleetNotification
+type ManagedFleetNotificationSpec struct {
+	// FleetNotification is the notification sent for alerts of the hosted clusters
+	FleetNotification FleetNotification `json:"fleetNotification"`
+}
+
+// ManagedFleetNotificationStatus defines the observed state of ManagedFleetNotification
+type ManagedFleetNotificationStatus struct {
+	// ObservedGeneration is the most recent ManagedFleetNotification generation observed
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the ManagedFleetNotification's state
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mfn
+
+// ManagedFleetNotification is the Schema for the managedfleetnotifications API
+type ManagedFleetNotification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagedFleetNotificationSpec   `json:"spec,omitempty"`
+	Status ManagedFleetNotificationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ManagedFleetNotificationList contains a list of ManagedFleetNotification
+type ManagedFleetNotificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagedFleetNotification `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ManagedFleetNotification{}, &ManagedFleetNotificationList{})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+)
+
+// ConvertTo converts this ManagedFleetNotificationRecord to the hub version (v1alpha1)
+func (src *ManagedFleetNotificationRecord) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ManagedFleetNotificationRecord)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Status = v1alpha1.ManagedFleetNotificationRecordStatus{
+		ManagementCluster: src.Status.ManagementCluster,
+	}
+	if src.Status.NotificationRecordByName != nil {
+		dst.Status.NotificationRecordByName = make([]v1alpha1.NotificationRecordByName, 0, len(src.Status.NotificationRecordByName))
+		for _, rn := range src.Status.NotificationRecordByName {
+			record := v1alpha1.NotificationRecordByName{
+				NotificationName: rn.NotificationName,
+				ResendWait:       durationToHours(rn.ResendWait),
+			}
+			if rn.NotificationRecordItems != nil {
+				record.NotificationRecordItems = make([]v1alpha1.NotificationRecordItem, 0, len(rn.NotificationRecordItems))
+				for _, ri := range rn.NotificationRecordItems {
+					record.NotificationRecordItems = append(record.NotificationRecordItems, v1alpha1.NotificationRecordItem{
+						HostedClusterID:     ri.HostedClusterID,
+						ServiceLogSentCount: ri.ServiceLogSentCount,
+						LastTransitionTime:  ri.LastTransitionTime,
+					})
+				}
+			}
+			dst.Status.NotificationRecordByName = append(dst.Status.NotificationRecordByName, record)
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this ManagedFleetNotificationRecord
+func (dst *ManagedFleetNotificationRecord) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ManagedFleetNotificationRecord)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Status = ManagedFleetNotificationRecordStatus{
+		ManagementCluster: src.Status.ManagementCluster,
+	}
+	if src.Status.NotificationRecordByName != nil {
+		dst.Status.NotificationRecordByName = make([]NotificationRecordByName, 0, len(src.Status.NotificationRecordByName))
+		for _, rn := range src.Status.NotificationRecordByName {
+			record := NotificationRecordByName{
+				NotificationName: rn.NotificationName,
+				ResendWait:       hoursToDuration(rn.ResendWait),
+			}
+			if rn.NotificationRecordItems != nil {
+				record.NotificationRecordItems = make([]NotificationRecordItem, 0, len(rn.NotificationRecordItems))
+				for _, ri := range rn.NotificationRecordItems {
+					record.NotificationRecordItems = append(record.NotificationRecordItems, NotificationRecordItem{
+						HostedClusterID:     ri.HostedClusterID,
+						ServiceLogSentCount: ri.ServiceLogSentCount,
+						LastTransitionTime:  ri.LastTransitionTime,
+					})
+				}
+			}
+			dst.Status.NotificationRecordByName = append(dst.Status.NotificationRecordByName, record)
+		}
+	}
+	return nil
+}
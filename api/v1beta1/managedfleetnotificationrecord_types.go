@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NotificationRecordItem records the notifications sent to a hosted cluster
+type NotificationRecordItem struct {
+	// HostedClusterID is the ID of the hosted cluster
+	HostedClusterID string `json:"hostedClusterID"`
+
+	// ServiceLogSentCount is the number of Service Logs sent to the hosted cluster
+	ServiceLogSentCount int `json:"serviceLogSentCount"`
+
+	// LastTransitionTime is the last time a Service Log was sent to the hosted cluster
+	// +kubebuilder:validation:Optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// NotificationRecordByName groups the notification record items of a notification
+type NotificationRecordByName struct {
+	// NotificationName is the name of the notification
+	NotificationName string `json:"notificationName"`
+
+	// ResendWait is the minimum time that must elapse between Service Log notifications, in whole hours
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+h(0m(0s)?)?|0s?)$`
+	ResendWait metav1.Duration `json:"resendWait"`
+
+	// NotificationRecordItems record the notifications sent to each hosted cluster
+	NotificationRecordItems []NotificationRecordItem `json:"notificationRecordItems"`
+}
+
+// ManagedFleetNotificationRecordStatus defines the observed state of ManagedFleetNotificationRecord
+type ManagedFleetNotificationRecordStatus struct {
+	// ManagementCluster is the name of the management cluster of the hosted clusters
+	ManagementCluster string `json:"managementCluster"`
+
+	// NotificationRecordByName records the history of each notification
+	NotificationRecordByName []NotificationRecordByName `json:"notificationRecordByName"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mfnr
+
+// ManagedFleetNotificationRecord is the Schema for the managedfleetnotificationrecords API
+type ManagedFleetNotificationRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ManagedFleetNotificationRecordStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ManagedFleetNotificationRecordList contains a list of ManagedFleetNotificationRecord
+type ManagedFleetNotificationRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagedFleetNotificationRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ManagedFleetNotificationRecord{}, &ManagedFleetNotificationRecordList{})
+}
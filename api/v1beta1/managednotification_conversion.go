@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+)
+
+// ConvertTo converts this ManagedNotification to the hub version (v1alpha1)
+func (src *ManagedNotification) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ManagedNotification)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = v1alpha1.ManagedNotificationSpec{}
+	if src.Spec.Notifications != nil {
+		dst.Spec.Notifications = make([]v1alpha1.Notification, 0, len(src.Spec.Notifications))
+		for _, n := range src.Spec.Notifications {
+			dst.Spec.Notifications = append(dst.Spec.Notifications, v1alpha1.Notification{
+				Name:         n.Name,
+				Summary:      n.Summary,
+				ActiveDesc:   n.ActiveBody,
+				ResolvedDesc: n.ResolvedBody,
+				Severity:     v1alpha1.NotificationSeverity(n.Severity),
+				ResendWait:   durationToHours(n.ResendWait),
+			})
+		}
+	}
+
+	dst.Status = v1alpha1.ManagedNotificationStatus{}
+	if src.Status.NotificationRecords != nil {
+		dst.Status.NotificationRecords = make(v1alpha1.NotificationRecords, 0, len(src.Status.NotificationRecords))
+		for _, r := range src.Status.NotificationRecords {
+			record := v1alpha1.NotificationRecord{
+				Name:                r.Name,
+				ServiceLogSentCount: r.ServiceLogSentCount,
+			}
+			if r.Conditions != nil {
+				record.Conditions = make(v1alpha1.Conditions, 0, len(r.Conditions))
+				for _, c := range r.Conditions {
+					record.Conditions = append(record.Conditions, convertConditionToHub(c))
+				}
+			}
+			dst.Status.NotificationRecords = append(dst.Status.NotificationRecords, record)
+		}
+	}
+	if src.Status.ResolutionSummaries != nil {
+		dst.Status.ResolutionSummaries = make([]v1alpha1.NotificationResolutionSummary, 0, len(src.Status.ResolutionSummaries))
+		for _, rs := range src.Status.ResolutionSummaries {
+			// The summaries have the same fields in both versions
+			dst.Status.ResolutionSummaries = append(dst.Status.ResolutionSummaries, v1alpha1.NotificationResolutionSummary(*rs.DeepCopy()))
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this ManagedNotification
+func (dst *ManagedNotification) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ManagedNotification)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = ManagedNotificationSpec{}
+	if src.Spec.Notifications != nil {
+		dst.Spec.Notifications = make([]Notification, 0, len(src.Spec.Notifications))
+		for _, n := range src.Spec.Notifications {
+			dst.Spec.Notifications = append(dst.Spec.Notifications, Notification{
+				Name:         n.Name,
+				Summary:      n.Summary,
+				ActiveBody:   n.ActiveDesc,
+				ResolvedBody: n.ResolvedDesc,
+				Severity:     NotificationSeverity(n.Severity),
+				ResendWait:   hoursToDuration(n.ResendWait),
+			})
+		}
+	}
+
+	dst.Status = ManagedNotificationStatus{}
+	if src.Status.NotificationRecords != nil {
+		dst.Status.NotificationRecords = make([]NotificationRecord, 0, len(src.Status.NotificationRecords))
+		for _, r := range src.Status.NotificationRecords {
+			record := NotificationRecord{
+				Name:                r.Name,
+				ServiceLogSentCount: r.ServiceLogSentCount,
+			}
+			if r.Conditions != nil {
+				record.Conditions = make([]metav1.Condition, 0, len(r.Conditions))
+				for _, c := range r.Conditions {
+					record.Conditions = append(record.Conditions, convertConditionFromHub(c))
+				}
+			}
+			dst.Status.NotificationRecords = append(dst.Status.NotificationRecords, record)
+		}
+	}
+	if src.Status.ResolutionSummaries != nil {
+		dst.Status.ResolutionSummaries = make([]NotificationResolutionSummary, 0, len(src.Status.ResolutionSummaries))
+		for _, rs := range src.Status.ResolutionSummaries {
+			dst.Status.ResolutionSummaries = append(dst.Status.ResolutionSummaries, NotificationResolutionSummary(*rs.DeepCopy()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NotificationSeverity is the severity of a Service Log notification
+type NotificationSeverity string
+
+const (
+	SeverityDebug   NotificationSeverity = "Debug"
+	SeverityWarning NotificationSeverity = "Warning"
+	SeverityInfo    NotificationSeverity = "Info"
+	SeverityError   NotificationSeverity = "Error"
+	SeverityFatal   NotificationSeverity = "Fatal"
+)
+
+// Notification defines a Service Log notification sent for an alert
+type Notification struct {
+	// Name of the notification, used to associate it with an alert
+	Name string `json:"name"`
+
+	// Summary is the summary line of the Service Log notification
+	Summary string `json:"summary"`
+
+	// ActiveBody is the body of the Service Log notification sent when the alert is firing
+	ActiveBody string `json:"activeBody"`
+
+	// ResolvedBody is the body of the Service Log notification sent when the alert is resolved.
+	// No notification is sent for a resolved alert if it is not set.
+	// +kubebuilder:validation:Optional
+	ResolvedBody string `json:"resolvedBody,omitempty"`
+
+	// Severity is the severity of the Service Log notification
+	// +kubebuilder:validation:Enum={"Debug","Info","Warning","Error","Fatal"}
+	Severity NotificationSeverity `json:"severity"`
+
+	// ResendWait is the minimum time that must elapse between Service Log notifications sent for a firing alert,
+	// in whole hours
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+h(0m(0s)?)?|0s?)$`
+	ResendWait metav1.Duration `json:"resendWait"`
+}
+
+// ManagedNotificationSpec defines the desired state of ManagedNotification
+type ManagedNotificationSpec struct {
+	// Notifications are the notifications that can be sent for alerts
+	Notifications []Notification `json:"notifications"`
+}
+
+const (
+	// ConditionAlertFiring indicates whether the alert of a notification is firing
+	ConditionAlertFiring = "AlertFiring"
+	// ConditionAlertResolved indicates whether the alert of a notification is resolved
+	ConditionAlertResolved = "AlertResolved"
+	// ConditionServiceLogSent indicates whether a Service Log was sent for a notification
+	ConditionServiceLogSent = "ServiceLogSent"
+
+	// ConditionReasonUnset is the reason of a condition converted from a v1alpha1 condition
+	// without a reason, as the reason of a standard condition is required
+	ConditionReasonUnset = "Unset"
+)
+
+// NotificationRecord records the history of a notification
+type NotificationRecord struct {
+	// Name of the notification
+	Name string `json:"name"`
+
+	// ServiceLogSentCount is the number of Service Logs sent for the notification
+	// +kubebuilder:validation:Optional
+	ServiceLogSentCount int32 `json:"serviceLogSentCount,omitempty"`
+
+	// Conditions of the notification, any of AlertFiring, AlertResolved or ServiceLogSent
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ManagedNotificationStatus defines the observed state of ManagedNotification
+type ManagedNotificationStatus struct {
+	// NotificationRecords record the history of each notification
+	// +kubebuilder:validation:Optional
+	NotificationRecords []NotificationRecord `json:"notificationRecords,omitempty"`
+
+	// ResolutionSummaries summarize how long the alert of each notification took to resolve,
+	// and how often it fired again shortly after resolving
+	// +kubebuilder:validation:Optional
+	ResolutionSummaries []NotificationResolutionSummary `json:"resolutionSummaries,omitempty"`
+}
+
+// NotificationResolutionSummary summarizes the firing to resolved transitions of the alert of a notification
+type NotificationResolutionSummary struct {
+	// Name of the notification
+	Name string `json:"name"`
+
+	// FiringSince is the time the alert started firing, while it is firing
+	// +kubebuilder:validation:Optional
+	FiringSince *metav1.Time `json:"firingSince,omitempty"`
+
+	// LastResolvedTime is the time the alert last resolved, up to which its resolutions were counted
+	// +kubebuilder:validation:Optional
+	LastResolvedTime *metav1.Time `json:"lastResolvedTime,omitempty"`
+
+	// ResolvedCount is the number of times the alert resolved after firing
+	// +kubebuilder:validation:Optional
+	ResolvedCount int32 `json:"resolvedCount,omitempty"`
+
+	// LastTimeToResolve is the time the alert fired for before it last resolved
+	// +kubebuilder:validation:Optional
+	LastTimeToResolve *metav1.Duration `json:"lastTimeToResolve,omitempty"`
+
+	// MeanTimeToResolve is the mean time the alert fired for before resolving
+	// +kubebuilder:validation:Optional
+	MeanTimeToResolve *metav1.Duration `json:"meanTimeToResolve,omitempty"`
+
+	// RecentFlaps are the times the alert fired again shortly after resolving, over the
+	// flapping count window of the operator configuration
+	// +kubebuilder:validation:Optional
+	RecentFlaps []metav1.Time `json:"recentFlaps,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=managednotifications,scope=Namespaced
+
+// ManagedNotification is the Schema for the managednotifications API
+type ManagedNotification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagedNotificationSpec   `json:"spec,omitempty"`
+	Status ManagedNotificationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ManagedNotificationList contains a list of ManagedNotification
+type ManagedNotificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagedNotification `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ManagedNotification{}, &ManagedNotificationList{})
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
+)
+
+// ConvertTo converts this OcmAgent to the hub version (v1alpha1)
+func (src *OcmAgent) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.OcmAgent)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = v1alpha1.OcmAgentSpec{
+		AgentConfig: v1alpha1.AgentConfig{
+			OcmBaseUrl: src.Spec.AgentConfig.OCMBaseURL,
+			Services:   src.Spec.AgentConfig.Services,
+		},
+		OcmAgentImage:             src.Spec.OcmAgentImage,
+		TokenSecret:               src.Spec.TokenSecret,
+		Replicas:                  src.Spec.Replicas,
+		FleetMode:                 src.Spec.FleetMode,
+		Resources:                 src.Spec.Resources,
+		NodeSelector:              src.Spec.NodeSelector,
+		Tolerations:               src.Spec.Tolerations,
+		Affinity:                  src.Spec.Affinity,
+		TopologySpreadConstraints: src.Spec.TopologySpreadConstraints,
+		PriorityClassName:         src.Spec.PriorityClassName,
+		TargetNamespace:           src.Spec.TargetNamespace,
+	}
+	if as := src.Spec.Autoscaling; as != nil {
+		dst.Spec.Autoscaling = &v1alpha1.AutoscalingConfig{
+			MinReplicas:                       as.MinReplicas,
+			MaxReplicas:                       as.MaxReplicas,
+			TargetCPUUtilizationPercentage:    as.TargetCPUUtilizationPercentage,
+			TargetMemoryUtilizationPercentage: as.TargetMemoryUtilizationPercentage,
+		}
+	}
+
+	dst.Status = v1alpha1.OcmAgentStatus{
+		ServiceStatus:          src.Status.ServiceStatus,
+		AvailableReplicas:      src.Status.AvailableReplicas,
+		ReadyReplicas:          src.Status.ReadyReplicas,
+		ObservedGeneration:     src.Status.ObservedGeneration,
+		Conditions:             src.Status.Conditions,
+		Mode:                   v1alpha1.OcmAgentMode(src.Status.Mode),
+		LastModeTransitionTime: src.Status.LastModeTransitionTime,
+	}
+	if src.Status.ManagedResources != nil {
+		dst.Status.ManagedResources = make([]v1alpha1.ManagedResourceStatus, 0, len(src.Status.ManagedResources))
+		for _, r := range src.Status.ManagedResources {
+			dst.Status.ManagedResources = append(dst.Status.ManagedResources, v1alpha1.ManagedResourceStatus{
+				Kind:         r.Kind,
+				Name:         r.Name,
+				State:        v1alpha1.ManagedResourceState(r.State),
+				LastError:    r.LastError,
+				LastSyncTime: r.LastSyncTime,
+			})
+		}
+	}
+	if src.Status.Inventory != nil {
+		dst.Status.Inventory = make([]v1alpha1.ManagedObjectReference, 0, len(src.Status.Inventory))
+		for _, r := range src.Status.Inventory {
+			dst.Status.Inventory = append(dst.Status.Inventory, v1alpha1.ManagedObjectReference(r))
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this OcmAgent
+func (dst *OcmAgent) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.OcmAgent)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = OcmAgentSpec{
+		AgentConfig: AgentConfig{
+			OCMBaseURL: src.Spec.AgentConfig.OcmBaseUrl,
+			Services:   src.Spec.AgentConfig.Services,
+		},
+		OcmAgentImage:             src.Spec.OcmAgentImage,
+		TokenSecret:               src.Spec.TokenSecret,
+		Replicas:                  src.Spec.Replicas,
+		FleetMode:                 src.Spec.FleetMode,
+		Resources:                 src.Spec.Resources,
+		NodeSelector:              src.Spec.NodeSelector,
+		Tolerations:               src.Spec.Tolerations,
+		Affinity:                  src.Spec.Affinity,
+		TopologySpreadConstraints: src.Spec.TopologySpreadConstraints,
+		PriorityClassName:         src.Spec.PriorityClassName,
+		TargetNamespace:           src.Spec.TargetNamespace,
+	}
+	if as := src.Spec.Autoscaling; as != nil {
+		dst.Spec.Autoscaling = &AutoscalingConfig{
+			MinReplicas:                       as.MinReplicas,
+			MaxReplicas:                       as.MaxReplicas,
+			TargetCPUUtilizationPercentage:    as.TargetCPUUtilizationPercentage,
+			TargetMemoryUtilizationPercentage: as.TargetMemoryUtilizationPercentage,
+		}
+	}
+
+	dst.Status = OcmAgentStatus{
+		ServiceStatus:          src.Status.ServiceStatus,
+		AvailableReplicas:      src.Status.AvailableReplicas,
+		ReadyReplicas:          src.Status.ReadyReplicas,
+		ObservedGeneration:     src.Status.ObservedGeneration,
+		Conditions:             src.Status.Conditions,
+		Mode:                   OcmAgentMode(src.Status.Mode),
+		LastModeTransitionTime: src.Status.LastModeTransitionTime,
+	}
+	if src.Status.ManagedResources != nil {
+		dst.Status.ManagedResources = make([]ManagedResourceStatus, 0, len(src.Status.ManagedResources))
+		for _, r := range src.Status.ManagedResources {
+			dst.Status.ManagedResources = append(dst.Status.ManagedResources, ManagedResourceStatus{
+				Kind:         r.Kind,
+				Name:         r.Name,
+				State:        ManagedResourceState(r.State),
+				LastError:    r.LastError,
+				LastSyncTime: r.LastSyncTime,
+			})
+		}
+	}
+	if src.Status.Inventory != nil {
+		dst.Status.Inventory = make([]ManagedObjectReference, 0, len(src.Status.Inventory))
+		for _, r := range src.Status.Inventory {
+			dst.Status.Inventory = append(dst.Status.Inventory, ManagedObjectReference(r))
+		}
+	}
+	return nil
+}
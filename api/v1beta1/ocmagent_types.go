@@ -0,0 +1,242 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AgentConfig defines how the OCM Agent accesses OCM
+type AgentConfig struct {
+	// OCMBaseURL is the OCM API endpoint that the OCM Agent accesses
+	OCMBaseURL string `json:"ocmBaseUrl"`
+
+	// Services are the OCM services served by the OCM Agent, any of service_logs or clusters_mgmt
+	Services []string `json:"services"`
+}
+
+// AutoscalingConfig defines how the OCM Agent is autoscaled
+type AutoscalingConfig struct {
+	// MinReplicas is the lower limit for the number of OCM Agent replicas, defaults to 1
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of OCM Agent replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization of the OCM Agent pods,
+	// as a percentage of the requested CPU. Defaults to 80 if neither target is set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the target average memory utilization of the OCM Agent pods,
+	// as a percentage of the requested memory
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// OcmAgentSpec defines the desired state of OcmAgent
+type OcmAgentSpec struct {
+	// AgentConfig defines how the OCM Agent accesses OCM
+	AgentConfig AgentConfig `json:"agentConfig"`
+
+	// OcmAgentImage is the image run by the OCM Agent
+	OcmAgentImage string `json:"ocmAgentImage"`
+
+	// TokenSecret is the name of the Secret holding the OCM access token
+	TokenSecret string `json:"tokenSecret"`
+
+	// Replicas is the number of OCM Agent replicas. It is not enforced while Autoscaling is set.
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Autoscaling scales the OCM Agent with a HorizontalPodAutoscaler instead of a fixed replica count
+	// +kubebuilder:validation:Optional
+	Autoscaling *AutoscalingConfig `json:"autoscaling,omitempty"`
+
+	// FleetMode runs the OCM Agent for a fleet of hosted clusters, defaults to false
+	// +kubebuilder:validation:Optional
+	FleetMode bool `json:"fleetMode,omitempty"`
+
+	// Resources defines the compute resources of the OCM Agent container.
+	// Defaults to the operator's built-in requests and limits if not set.
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector restricts the OCM Agent pods to nodes with matching labels
+	// +kubebuilder:validation:Optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations defines the tolerations of the OCM Agent pods.
+	// Defaults to tolerating infra nodes if not set.
+	// +kubebuilder:validation:Optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the scheduling constraints of the OCM Agent pods.
+	// Defaults to preferring infra nodes if not set.
+	// +kubebuilder:validation:Optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describes how the OCM Agent pods are spread across topology domains
+	// +kubebuilder:validation:Optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the name of the priority class of the OCM Agent pods
+	// +kubebuilder:validation:Optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// TargetNamespace is the namespace the OCM Agent is deployed to.
+	// Defaults to the namespace of the OCM Agent Operator if not set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+}
+
+// ManagedResourceState describes the outcome of reconciling a resource managed for the OCM Agent
+type ManagedResourceState string
+
+const (
+	// ManagedResourceReconciled indicates that the resource matches its expected configuration
+	ManagedResourceReconciled ManagedResourceState = "Reconciled"
+	// ManagedResourceFailed indicates that the resource could not be reconciled
+	ManagedResourceFailed ManagedResourceState = "Failed"
+)
+
+// ManagedObjectReference identifies an object managed for the OCM Agent
+type ManagedObjectReference struct {
+	// APIVersion of the managed object
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the managed object
+	Kind string `json:"kind"`
+
+	// Namespace of the managed object
+	Namespace string `json:"namespace"`
+
+	// Name of the managed object
+	Name string `json:"name"`
+}
+
+// OcmAgentMode describes the mode that the OCM Agent is deployed in
+type OcmAgentMode string
+
+const (
+	// OcmAgentModeCluster indicates that the OCM Agent serves the cluster it runs on
+	OcmAgentModeCluster OcmAgentMode = "Cluster"
+	// OcmAgentModeFleet indicates that the OCM Agent serves a fleet of hosted clusters
+	OcmAgentModeFleet OcmAgentMode = "Fleet"
+)
+
+// ManagedResourceStatus records the reconcile state of a resource managed for the OCM Agent
+type ManagedResourceStatus struct {
+	// Kind of the managed resource
+	Kind string `json:"kind"`
+
+	// Name of the managed resource
+	Name string `json:"name"`
+
+	// State of the managed resource after the last reconcile
+	// +kubebuilder:validation:Enum={"Reconciled","Failed"}
+	State ManagedResourceState `json:"state"`
+
+	// LastError is the error encountered when the resource last failed to reconcile
+	// +kubebuilder:validation:Optional
+	LastError string `json:"lastError,omitempty"`
+
+	// LastSyncTime is the last time the state or error of the resource changed
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
+}
+
+// OcmAgentStatus defines the observed state of OcmAgent
+type OcmAgentStatus struct {
+	// ServiceStatus indicates the status of the OCM Agent service
+	ServiceStatus string `json:"serviceStatus"`
+
+	// AvailableReplicas is the number of available OCM Agent pods
+	AvailableReplicas int32 `json:"availableReplicas"`
+
+	// ReadyReplicas is the number of ready OCM Agent pods
+	// +kubebuilder:validation:Optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// ObservedGeneration is the most recent OcmAgent generation observed by the controller
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the OCM Agent's state
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ManagedResources records the reconcile state of each resource managed for the OCM Agent
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=kind
+	// +listMapKey=name
+	ManagedResources []ManagedResourceStatus `json:"managedResources,omitempty"`
+
+	// Mode is the mode that the OCM Agent was last deployed in
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"Cluster","Fleet"}
+	Mode OcmAgentMode `json:"mode,omitempty"`
+
+	// LastModeTransitionTime is the last time the OCM Agent switched between modes
+	// +kubebuilder:validation:Optional
+	LastModeTransitionTime *metav1.Time `json:"lastModeTransitionTime,omitempty"`
+
+	// Inventory lists the objects that were managed for the OCM Agent after the last successful
+	// reconcile, any other object labelled as managed for the OcmAgent is removed
+	// +kubebuilder:validation:Optional
+	// +listType=atomic
+	Inventory []ManagedObjectReference `json:"inventory,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=ocmagents,scope=Namespaced
+//+kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+//+kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.availableReplicas"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// OcmAgent is the Schema for the ocmagents API
+type OcmAgent struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OcmAgentSpec   `json:"spec,omitempty"`
+	Status OcmAgentStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OcmAgentList contains a list of OcmAgent
+type OcmAgentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OcmAgent `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OcmAgent{}, &OcmAgentList{})
+}
@@ -21,28 +21,44 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
-)
-
-const (
-	NotificationRecordStaleTimeoutInHour int32 = 360
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
 )
 
 // ManagedFleetNotificationReconciler reconciles a ManagedFleetNotification object
 type ManagedFleetNotificationReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Config holds the operator configuration, the default configuration if it is nil
+	Config *operatorconfig.Store
+	// Recorder records the Events of a reconcile on the ManagedFleetNotificationRecord
+	Recorder record.EventRecorder
 }
 
+const (
+	// reasonStaleRecordPruned is the reason of the Event recorded when a stale
+	// notification record item is removed
+	reasonStaleRecordPruned = "StaleRecordPruned"
+	// reasonStaleRecordPruneFailed is the reason of the Event recorded when a stale
+	// notification record item could not be removed
+	reasonStaleRecordPruneFailed = "StaleRecordPruneFailed"
+)
+
+// controllerName is the name of the controller in its metrics
+const controllerName = "fleetnotification"
+
 var log = logf.Log.WithName("controller_fleetnotification")
 
 var _ reconcile.Reconciler = &ManagedFleetNotificationReconciler{}
@@ -50,6 +66,7 @@ var _ reconcile.Reconciler = &ManagedFleetNotificationReconciler{}
 //+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=managedfleetnotifications,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=managedfleetnotifications/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=managedfleetnotifications/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -61,6 +78,18 @@ var _ reconcile.Reconciler = &ManagedFleetNotificationReconciler{}
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.2/pkg/reconcile
 func (r *ManagedFleetNotificationReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, request)
+	localmetrics.ObserveMetricReconcileDuration(controllerName, time.Since(start))
+	if err == nil {
+		localmetrics.UpdateMetricLastSuccessfulReconcile(controllerName)
+	}
+	return result, err
+}
+
+// reconcile reconciles the ManagedFleetNotificationRecord, while Reconcile records the duration and
+// outcome of each reconcile in the metrics
+func (r *ManagedFleetNotificationReconciler) reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling FleetNotification")
@@ -69,45 +98,84 @@ func (r *ManagedFleetNotificationReconciler) Reconcile(ctx context.Context, requ
 
 	err := r.Client.Get(ctx, request.NamespacedName, &nr)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.V(2).Info("ManagedFleetNotificationRecord not found, removing its metrics")
+			localmetrics.DeleteMetricFleetNotifications(request.NamespacedName.String())
+			return reconcile.Result{}, nil
+		}
 		return reconcile.Result{}, err
 	}
+	updateMetrics(request.NamespacedName.String(), nr)
+
+	cfg := r.Config.Get()
+	requeue := ctrl.Result{RequeueAfter: cfg.FleetNotificationController.RequeueInterval}
+	if !cfg.Enabled(ocmagentv1alpha1.FeatureStaleRecordCleanup) {
+		return requeue, nil
+	}
 
 	for n, rn := range nr.Status.NotificationRecordByName {
-		resendWait := rn.ResendWait
+		resendWait := time.Duration(rn.ResendWait) * time.Hour
 		for i, ri := range rn.NotificationRecordItems {
-			// Consider the record is stale if the lastSendTime is older than resendWait + 15 days
-			eol := ri.LastTransitionTime.Time.Add(time.Duration(resendWait+NotificationRecordStaleTimeoutInHour) * time.Hour)
+			// Consider the record is stale if the lastSendTime is older than resendWait + the stale timeout
+			eol := ri.LastTransitionTime.Time.Add(resendWait + cfg.NotificationRecordStaleTimeout)
 			if time.Now().After(eol) {
 				log.Info(fmt.Sprintf("NotificationRecord for notification %s and hostedcluster %s has not been updated "+
-					"for %d hours and considered as stale, cleaning up...", rn.NotificationName, ri.HostedClusterID,
-					resendWait+NotificationRecordStaleTimeoutInHour))
+					"for %s and considered as stale, cleaning up...", rn.NotificationName, ri.HostedClusterID,
+					resendWait+cfg.NotificationRecordStaleTimeout))
 
 				patch := []byte(fmt.Sprintf(`[{"op": "remove", "path": "/status/notificationRecordByName/%d/notificationRecordItems/%d"}]`, n, i))
 
 				err = r.Client.Status().Patch(ctx, &nr, client.RawPatch(types.JSONPatchType, patch))
 				if err != nil {
+					r.Recorder.Eventf(&nr, corev1.EventTypeWarning, reasonStaleRecordPruneFailed,
+						"Failed to remove the stale record of notification %s for hosted cluster %s: %v", rn.NotificationName, ri.HostedClusterID, err)
 					return ctrl.Result{}, err
 				}
+				localmetrics.IncrementMetricFleetRecordsPruned(managementCluster(nr), rn.NotificationName)
+				r.Recorder.Eventf(&nr, corev1.EventTypeNormal, reasonStaleRecordPruned,
+					"Removed the record of notification %s for hosted cluster %s, which was not updated for %s",
+					rn.NotificationName, ri.HostedClusterID, resendWait+cfg.NotificationRecordStaleTimeout)
 				return ctrl.Result{}, nil
 			}
 		}
 	}
-	return ctrl.Result{}, nil
+	return requeue, nil
+}
+
+// updateMetrics exports the state of the notifications of the ManagedFleetNotificationRecord.
+// The records are aggregated per notification, as a label per hosted cluster would make
+// the number of series grow with the fleet.
+func updateMetrics(record string, nr ocmagentv1alpha1.ManagedFleetNotificationRecord) {
+	hostedClusters := map[string]struct{}{}
+	states := make([]localmetrics.FleetNotificationState, 0, len(nr.Status.NotificationRecordByName))
+	for _, rn := range nr.Status.NotificationRecordByName {
+		state := localmetrics.FleetNotificationState{
+			Name:          rn.NotificationName,
+			ActiveRecords: len(rn.NotificationRecordItems),
+		}
+		for _, ri := range rn.NotificationRecordItems {
+			state.ServiceLogsSent += ri.ServiceLogSentCount
+			hostedClusters[ri.HostedClusterID] = struct{}{}
+		}
+		states = append(states, state)
+	}
+	localmetrics.UpdateMetricFleetNotifications(record, managementCluster(nr), len(hostedClusters), states)
+}
+
+// managementCluster returns the management cluster of the ManagedFleetNotificationRecord,
+// which is also its name
+func managementCluster(nr ocmagentv1alpha1.ManagedFleetNotificationRecord) string {
+	if nr.Status.ManagementCluster != "" {
+		return nr.Status.ManagementCluster
+	}
+	return nr.Name
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ManagedFleetNotificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Config.Get().FleetNotificationController.MaxConcurrentReconciles}).
+		// Deletions are reconciled too, so that the metrics of a removed record are cleared
 		For(&ocmagentv1alpha1.ManagedFleetNotificationRecord{}).
-		WithEventFilter(eventPredicates()).
 		Complete(r)
 }
-
-func eventPredicates() predicate.Predicate {
-	return predicate.Funcs{
-		DeleteFunc: func(e event.DeleteEvent) bool {
-			return false
-		},
-	}
-}
@@ -1,11 +1,18 @@
 package fleetnotification_test
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	"time"
 
 	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
 
@@ -13,6 +20,8 @@ import (
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	"github.com/openshift/ocm-agent-operator/controllers/fleetnotification"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
 	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
 )
 
@@ -23,18 +32,25 @@ var _ = Describe("FleetNotification Controller", func() {
 		mockCtrl                    *gomock.Controller
 		fleetNotificationReconciler *fleetnotification.ManagedFleetNotificationReconciler
 		testFleetNotificationRecord *ocmagentv1alpha1.ManagedFleetNotificationRecord
+		testRecorder                *record.FakeRecorder
 	)
 
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		mockClient = clientmocks.NewMockClient(mockCtrl)
 		mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
+		testRecorder = record.NewFakeRecorder(10)
 		fleetNotificationReconciler = &fleetnotification.ManagedFleetNotificationReconciler{
-			Client: mockClient,
-			Scheme: testconst.Scheme,
+			Client:   mockClient,
+			Scheme:   testconst.Scheme,
+			Recorder: testRecorder,
 		}
 	})
 
+	AfterEach(func() {
+		localmetrics.DeleteMetricFleetNotifications(testconst.MfnrNamespacedName.String())
+	})
+
 	Context("Reconcile ManagedFleetNotificationRecord CR", func() {
 		BeforeEach(func() {
 			testFleetNotificationRecord = &ocmagentv1alpha1.ManagedFleetNotificationRecord{
@@ -87,6 +103,55 @@ var _ = Describe("FleetNotification Controller", func() {
 				Expect(err).To(BeNil())
 				Expect(err).NotTo(HaveOccurred())
 			})
+			It("Exports the metrics of the record without the hosted cluster IDs", func() {
+				testFleetNotificationRecord.Status.ManagementCluster = "test-mc"
+				testFleetNotificationRecord.Status.NotificationRecordByName[0].NotificationName = "test-notification"
+				testFleetNotificationRecord.Status.NotificationRecordByName[0].NotificationRecordItems = append(
+					testFleetNotificationRecord.Status.NotificationRecordByName[0].NotificationRecordItems,
+					ocmagentv1alpha1.NotificationRecordItem{
+						HostedClusterID:     "8765-4321-87654321",
+						ServiceLogSentCount: 2,
+						LastTransitionTime:  &metav1.Time{Time: time.Now()},
+					})
+				mockClient.EXPECT().Get(gomock.Any(), testconst.MfnrNamespacedName, gomock.Any()).Times(1).SetArg(2, *testFleetNotificationRecord)
+				_, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+
+				expected := `
+# HELP ocm_agent_operator_fleet_hosted_clusters Number of hosted clusters of a management cluster with a notification record
+# TYPE ocm_agent_operator_fleet_hosted_clusters gauge
+ocm_agent_operator_fleet_hosted_clusters{management_cluster="test-mc"} 2
+# HELP ocm_agent_operator_fleet_notification_active_records Number of hosted clusters with a record of a fleet notification
+# TYPE ocm_agent_operator_fleet_notification_active_records gauge
+ocm_agent_operator_fleet_notification_active_records{management_cluster="test-mc",notification_name="test-notification"} 2
+# HELP ocm_agent_operator_fleet_notification_service_logs_sent Number of service logs sent for a fleet notification to the hosted clusters with a record of it
+# TYPE ocm_agent_operator_fleet_notification_service_logs_sent gauge
+ocm_agent_operator_fleet_notification_service_logs_sent{management_cluster="test-mc",notification_name="test-notification"} 3
+`
+				Expect(testutil.CollectAndCompare(localmetrics.MetricFleetNotifications, strings.NewReader(expected))).To(Succeed())
+			})
+			It("Requeues the record after the configured interval", func() {
+				cfg := operatorconfig.Default()
+				cfg.FleetNotificationController.RequeueInterval = 10 * time.Minute
+				fleetNotificationReconciler.Config = operatorconfig.NewStore(cfg)
+				mockClient.EXPECT().Get(gomock.Any(), testconst.MfnrNamespacedName, gomock.Any()).Times(1).SetArg(2, *testFleetNotificationRecord)
+				result, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(10 * time.Minute))
+			})
+			It("Removes the items that are stale within a shorter configured timeout", func() {
+				testFleetNotificationRecord.Status.NotificationRecordByName[0].NotificationRecordItems[0].LastTransitionTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+				cfg := operatorconfig.Default()
+				cfg.NotificationRecordStaleTimeout = time.Hour
+				fleetNotificationReconciler.Config = operatorconfig.NewStore(cfg)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.MfnrNamespacedName, gomock.Any()).Times(1).SetArg(2, *testFleetNotificationRecord),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1),
+				)
+				_, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
 		})
 
 		When("There is notification record which was sent before and stale", func() {
@@ -120,6 +185,49 @@ var _ = Describe("FleetNotification Controller", func() {
 				_, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
 				Expect(err).To(BeNil())
 				Expect(err).NotTo(HaveOccurred())
+				Expect(testRecorder.Events).To(Receive(And(ContainSubstring("Normal StaleRecordPruned"), ContainSubstring("1234-5678-12345678"))))
+			})
+			It("Counts the removed stale item", func() {
+				pruned := localmetrics.MetricFleetRecordsPruned.WithLabelValues(testconst.MfnrNamespacedName.Name, "")
+				before := testutil.ToFloat64(pruned)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.MfnrNamespacedName, gomock.Any()).Times(1).SetArg(2, *testFleetNotificationRecord),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1),
+				)
+				_, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(testutil.ToFloat64(pruned) - before).To(Equal(float64(1)))
+			})
+			It("Records a failure to remove the stale item", func() {
+				patchErr := fmt.Errorf("fake error")
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.MfnrNamespacedName, gomock.Any()).Times(1).SetArg(2, *testFleetNotificationRecord),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(patchErr),
+				)
+				_, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
+				Expect(err).To(Equal(patchErr))
+				Expect(testRecorder.Events).To(Receive(ContainSubstring("Warning StaleRecordPruneFailed")))
+			})
+			It("Keeps the stale items while the StaleRecordCleanup feature is disabled", func() {
+				cfg := operatorconfig.Default()
+				cfg.FeatureGates[ocmagentv1alpha1.FeatureStaleRecordCleanup] = false
+				fleetNotificationReconciler.Config = operatorconfig.NewStore(cfg)
+				mockClient.EXPECT().Get(gomock.Any(), testconst.MfnrNamespacedName, gomock.Any()).Times(1).SetArg(2, *testFleetNotificationRecord)
+				mockClient.EXPECT().Status().Times(0)
+				_, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("The record is removed", func() {
+			It("Removes the metrics of the record", func() {
+				localmetrics.UpdateMetricFleetNotifications(testconst.MfnrNamespacedName.String(), "test-mc", 1, nil)
+				mockClient.EXPECT().Get(gomock.Any(), testconst.MfnrNamespacedName, gomock.Any()).Times(1).Return(k8serrs.NewNotFound(schema.GroupResource{}, testconst.MfnrNamespacedName.Name))
+				_, err := fleetNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.MfnrNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(testutil.CollectAndCount(localmetrics.MetricFleetNotifications)).To(Equal(0))
 			})
 		})
 	})
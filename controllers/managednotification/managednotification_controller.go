@@ -0,0 +1,165 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managednotification
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+)
+
+// ManagedNotificationReconciler exports the state of the notifications of the
+// ManagedNotifications as metrics, and summarizes the resolutions of their alerts
+// in the ManagedNotification status
+type ManagedNotificationReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	// Config holds the operator configuration, the default configuration if it is nil
+	Config *operatorconfig.Store
+}
+
+// controllerName is the name of the controller in its metrics
+const controllerName = "managednotification"
+
+var _ reconcile.Reconciler = &ManagedNotificationReconciler{}
+
+//+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=managednotifications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=managednotifications/status,verbs=get;update;patch
+
+// Reconcile updates the resolution summaries and the notification metrics from the status of the ManagedNotification
+func (r *ManagedNotificationReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, request)
+	localmetrics.ObserveMetricReconcileDuration(controllerName, time.Since(start))
+	if err == nil {
+		localmetrics.UpdateMetricLastSuccessfulReconcile(controllerName)
+	}
+	return result, err
+}
+
+// reconcile reconciles the ManagedNotification, while Reconcile records the duration and
+// outcome of each reconcile in the metrics
+func (r *ManagedNotificationReconciler) reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := logf.FromContext(ctx).WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	instance := &ocmagentv1alpha1.ManagedNotification{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.V(2).Info("ManagedNotification not found, removing its metrics")
+			localmetrics.DeleteMetricNotifications(request.NamespacedName.String())
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	cfg := r.Config.Get()
+	now := time.Now()
+	var summaries []ocmagentv1alpha1.NotificationResolutionSummary
+	var resolutions []resolution
+	for _, n := range instance.Spec.Notifications {
+		summary := ocmagentv1alpha1.NotificationResolutionSummary{Name: n.Name}
+		if existing := instance.Status.GetResolutionSummary(n.Name); existing != nil {
+			summary = *existing.DeepCopy()
+		}
+		record := instance.Status.NotificationRecords.GetNotificationRecord(n.Name)
+		if timeToResolve, resolved := updateResolutionSummary(&summary, record, cfg, now); resolved {
+			resolutions = append(resolutions, resolution{notification: n, timeToResolve: timeToResolve})
+		}
+		if !isEmpty(summary) {
+			summaries = append(summaries, summary)
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(instance.Status.ResolutionSummaries, summaries) {
+		// The status is patched with an optimistic lock, so that a transition is not counted
+		// twice from a ManagedNotification read before the summaries were last updated
+		patch := client.MergeFromWithOptions(instance.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		instance.Status.ResolutionSummaries = summaries
+		if err := r.Client.Status().Patch(ctx, instance, patch); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	for _, res := range resolutions {
+		reqLogger.V(2).Info("Alert of notification resolved", "notification", res.notification.Name, "timeToResolve", res.timeToResolve)
+		localmetrics.ObserveMetricNotificationTimeToResolve(request.NamespacedName.String(), res.notification.Name, string(res.notification.Severity), res.timeToResolve)
+	}
+
+	localmetrics.UpdateMetricNotifications(request.NamespacedName.String(), notificationStates(instance))
+
+	// The ManagedNotification is reconciled again once a flap leaves the flapping count window
+	return reconcile.Result{RequeueAfter: nextFlapExpiry(summaries, cfg, now)}, nil
+}
+
+// resolution is the resolution of the alert of a notification
+type resolution struct {
+	notification  ocmagentv1alpha1.Notification
+	timeToResolve time.Duration
+}
+
+// notificationStates returns the state of each notification of the ManagedNotification,
+// from its spec, its notification record and its resolution summary. A ManagedNotification
+// stored before the notification names were validated may repeat a name, in which case only
+// its first notification is reported, as only that one is matched with the notification record.
+func notificationStates(mn *ocmagentv1alpha1.ManagedNotification) []localmetrics.NotificationState {
+	states := make([]localmetrics.NotificationState, 0, len(mn.Spec.Notifications))
+	seen := map[string]bool{}
+	for _, n := range mn.Spec.Notifications {
+		if seen[n.Name] {
+			continue
+		}
+		seen[n.Name] = true
+		state := localmetrics.NotificationState{
+			Name:       n.Name,
+			Severity:   string(n.Severity),
+			ResendWait: time.Duration(n.ResendWait) * time.Hour,
+		}
+		if record := mn.Status.NotificationRecords.GetNotificationRecord(n.Name); record != nil {
+			state.ServiceLogSentCount = record.ServiceLogSentCount
+			if firing := record.Conditions.GetCondition(ocmagentv1alpha1.ConditionAlertFiring); firing != nil {
+				state.Firing = firing.Status == corev1.ConditionTrue
+			}
+			if sent := record.Conditions.GetCondition(ocmagentv1alpha1.ConditionServiceLogSent); sent != nil && sent.LastTransitionTime != nil {
+				state.LastSent = sent.LastTransitionTime.Time
+			}
+		}
+		if summary := mn.Status.GetResolutionSummary(n.Name); summary != nil {
+			state.Flaps = len(summary.RecentFlaps)
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ManagedNotificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Status changes are not filtered out, as the metrics are derived from the status
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ocmagentv1alpha1.ManagedNotification{}).
+		Complete(r)
+}
@@ -0,0 +1,13 @@
+package managednotification_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestManagedNotification(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ManagedNotification Controller Suite")
+}
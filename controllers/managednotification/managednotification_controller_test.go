@@ -0,0 +1,402 @@
+package managednotification_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/golang/mock/gomock"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/controllers/managednotification"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("ManagedNotification Controller", func() {
+	var (
+		mockClient                    *clientmocks.MockClient
+		mockStatusWriter              *clientmocks.MockStatusWriter
+		mockCtrl                      *gomock.Controller
+		managedNotificationReconciler *managednotification.ManagedNotificationReconciler
+		testManagedNotification       ocmagentv1alpha1.ManagedNotification
+		testNamespacedName            types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
+		managedNotificationReconciler = &managednotification.ManagedNotificationReconciler{
+			Client: mockClient,
+			Scheme: testconst.Scheme,
+		}
+		testNamespacedName = types.NamespacedName{Namespace: "openshift-ocm-agent-operator", Name: "sre-managed-notifications"}
+		sentTime := metav1.NewTime(time.Now().Add(-time.Hour))
+		testManagedNotification = ocmagentv1alpha1.ManagedNotification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testNamespacedName.Name,
+				Namespace: testNamespacedName.Namespace,
+			},
+			Spec: ocmagentv1alpha1.ManagedNotificationSpec{
+				Notifications: []ocmagentv1alpha1.Notification{
+					{Name: "test-notification", Severity: ocmagentv1alpha1.SeverityWarning, ResendWait: 24},
+					{Name: "quiet-notification", Severity: ocmagentv1alpha1.SeverityInfo, ResendWait: 1},
+				},
+			},
+			Status: ocmagentv1alpha1.ManagedNotificationStatus{
+				NotificationRecords: ocmagentv1alpha1.NotificationRecords{
+					{
+						Name:                "test-notification",
+						ServiceLogSentCount: 3,
+						Conditions: ocmagentv1alpha1.Conditions{
+							{Type: ocmagentv1alpha1.ConditionAlertFiring, Status: corev1.ConditionTrue},
+							{Type: ocmagentv1alpha1.ConditionServiceLogSent, Status: corev1.ConditionTrue, LastTransitionTime: &sentTime},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		localmetrics.DeleteMetricNotifications(testNamespacedName.String())
+	})
+
+	When("the ManagedNotification exists", func() {
+		It("exports the state of each of its notifications", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testManagedNotification),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1),
+			)
+			_, err := managedNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			expected := `
+# HELP ocm_agent_operator_notification_alert_firing Whether the alert of a notification is firing
+# TYPE ocm_agent_operator_notification_alert_firing gauge
+ocm_agent_operator_notification_alert_firing{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_alert_firing{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 1
+# HELP ocm_agent_operator_notification_flaps Number of times the alert of a notification fired again shortly after resolving, over the flapping count window
+# TYPE ocm_agent_operator_notification_flaps gauge
+ocm_agent_operator_notification_flaps{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_flaps{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 0
+# HELP ocm_agent_operator_notification_resend_suppressed Whether a service log for a notification would be suppressed by its resend wait
+# TYPE ocm_agent_operator_notification_resend_suppressed gauge
+ocm_agent_operator_notification_resend_suppressed{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_resend_suppressed{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 1
+# HELP ocm_agent_operator_notification_service_logs_sent_total Number of service logs sent for a notification
+# TYPE ocm_agent_operator_notification_service_logs_sent_total counter
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 3
+`
+			Expect(testutil.CollectAndCompare(localmetrics.MetricNotifications, strings.NewReader(expected),
+				"ocm_agent_operator_notification_alert_firing",
+				"ocm_agent_operator_notification_flaps",
+				"ocm_agent_operator_notification_resend_suppressed",
+				"ocm_agent_operator_notification_service_logs_sent_total",
+			)).To(Succeed())
+			// Only the notification that sent a service log reports the time since it was sent
+			Expect(testutil.CollectAndCount(localmetrics.MetricNotifications, "ocm_agent_operator_notification_last_sent_age_seconds")).To(Equal(1))
+		})
+	})
+
+	When("the ManagedNotification repeats a notification name", func() {
+		It("exports the first notification of the name", func() {
+			testManagedNotification.Spec.Notifications = append(testManagedNotification.Spec.Notifications,
+				ocmagentv1alpha1.Notification{Name: "test-notification", Severity: ocmagentv1alpha1.SeverityError, ResendWait: 1})
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testManagedNotification),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1),
+			)
+			_, err := managedNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.CollectAndCompare(localmetrics.MetricNotifications, strings.NewReader(`
+# HELP ocm_agent_operator_notification_alert_firing Whether the alert of a notification is firing
+# TYPE ocm_agent_operator_notification_alert_firing gauge
+ocm_agent_operator_notification_alert_firing{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_alert_firing{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 1
+`), "ocm_agent_operator_notification_alert_firing")).To(Succeed())
+		})
+	})
+
+	When("another ManagedNotification has a notification of the same name", func() {
+		otherNamespacedName := types.NamespacedName{Namespace: "openshift-ocm-agent-operator", Name: "cluster-notifications"}
+		AfterEach(func() {
+			localmetrics.DeleteMetricNotifications(otherNamespacedName.String())
+		})
+		It("exports the notifications of both ManagedNotifications", func() {
+			localmetrics.UpdateMetricNotifications(otherNamespacedName.String(), []localmetrics.NotificationState{{Name: "test-notification", Severity: "Warning"}})
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testManagedNotification),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1),
+			)
+			_, err := managedNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			// The series of the notifications are distinct, so the metrics can be gathered
+			registry := prometheus.NewPedanticRegistry()
+			Expect(registry.Register(localmetrics.MetricNotifications)).To(Succeed())
+			_, err = registry.Gather()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.CollectAndCompare(localmetrics.MetricNotifications, strings.NewReader(`
+# HELP ocm_agent_operator_notification_service_logs_sent_total Number of service logs sent for a notification
+# TYPE ocm_agent_operator_notification_service_logs_sent_total counter
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/cluster-notifications",notification_name="test-notification",severity="Warning"} 0
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 3
+`), "ocm_agent_operator_notification_service_logs_sent_total")).To(Succeed())
+		})
+	})
+
+	When("the alert of a notification transitions", func() {
+		var (
+			firingTime     metav1.Time
+			resolvedTime   metav1.Time
+			patched        *ocmagentv1alpha1.ManagedNotification
+			expectPatch    func()
+			setConditions  func(firing corev1.ConditionStatus, t metav1.Time)
+			reconcileAgent func() reconcile.Result
+		)
+
+		BeforeEach(func() {
+			firingTime = metav1.NewTime(time.Now().Add(-3 * time.Hour).Truncate(time.Second))
+			resolvedTime = metav1.NewTime(firingTime.Add(2 * time.Hour))
+			patched = nil
+			expectPatch = func() {
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testManagedNotification),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+							patched = obj.(*ocmagentv1alpha1.ManagedNotification)
+							return nil
+						}),
+				)
+			}
+			setConditions = func(firing corev1.ConditionStatus, t metav1.Time) {
+				resolved := corev1.ConditionFalse
+				if firing == corev1.ConditionFalse {
+					resolved = corev1.ConditionTrue
+				}
+				testManagedNotification.Status.NotificationRecords[0].Conditions = ocmagentv1alpha1.Conditions{
+					{Type: ocmagentv1alpha1.ConditionAlertFiring, Status: firing, LastTransitionTime: &t},
+					{Type: ocmagentv1alpha1.ConditionAlertResolved, Status: resolved, LastTransitionTime: &t},
+				}
+			}
+			reconcileAgent = func() reconcile.Result {
+				result, err := managedNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				return result
+			}
+		})
+
+		It("records when the alert started firing", func() {
+			setConditions(corev1.ConditionTrue, firingTime)
+			expectPatch()
+			reconcileAgent()
+			Expect(patched.Status.ResolutionSummaries).To(HaveLen(1))
+			Expect(patched.Status.ResolutionSummaries[0].Name).To(Equal("test-notification"))
+			Expect(patched.Status.ResolutionSummaries[0].FiringSince.Time).To(BeTemporally("==", firingTime.Time))
+		})
+
+		It("keeps the start of the firing while the alert keeps firing", func() {
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{Name: "test-notification", FiringSince: &firingTime},
+			}
+			setConditions(corev1.ConditionTrue, metav1.NewTime(firingTime.Add(time.Hour)))
+			mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testManagedNotification)
+			mockClient.EXPECT().Status().Times(0)
+			reconcileAgent()
+		})
+
+		It("summarizes the time to resolve once the alert resolves", func() {
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{
+					Name:              "test-notification",
+					FiringSince:       &firingTime,
+					ResolvedCount:     1,
+					MeanTimeToResolve: &metav1.Duration{Duration: 4 * time.Hour},
+				},
+			}
+			setConditions(corev1.ConditionFalse, resolvedTime)
+			before := sampleCount(localmetrics.MetricNotificationTimeToResolve)
+			expectPatch()
+			reconcileAgent()
+			summary := patched.Status.ResolutionSummaries[0]
+			Expect(summary.FiringSince).To(BeNil())
+			Expect(summary.LastResolvedTime.Time).To(BeTemporally("==", resolvedTime.Time))
+			Expect(summary.ResolvedCount).To(Equal(int32(2)))
+			Expect(summary.LastTimeToResolve.Duration).To(Equal(2 * time.Hour))
+			Expect(summary.MeanTimeToResolve.Duration).To(Equal(3 * time.Hour))
+			Expect(sampleCount(localmetrics.MetricNotificationTimeToResolve) - before).To(Equal(uint64(1)))
+		})
+
+		It("counts an alert that resolved before the operator started", func() {
+			testManagedNotification.Status.NotificationRecords[0].Conditions = ocmagentv1alpha1.Conditions{
+				{Type: ocmagentv1alpha1.ConditionAlertFiring, Status: corev1.ConditionFalse, LastTransitionTime: &firingTime},
+				{Type: ocmagentv1alpha1.ConditionAlertResolved, Status: corev1.ConditionTrue, LastTransitionTime: &resolvedTime},
+			}
+			before := sampleCount(localmetrics.MetricNotificationTimeToResolve)
+			expectPatch()
+			reconcileAgent()
+			summary := patched.Status.ResolutionSummaries[0]
+			Expect(summary.LastResolvedTime.Time).To(BeTemporally("==", resolvedTime.Time))
+			Expect(summary.ResolvedCount).To(Equal(int32(1)))
+			Expect(summary.LastTimeToResolve.Duration).To(Equal(2 * time.Hour))
+			Expect(sampleCount(localmetrics.MetricNotificationTimeToResolve) - before).To(Equal(uint64(1)))
+		})
+
+		It("counts an alert that fired and resolved between two reconciles", func() {
+			previousResolvedTime := metav1.NewTime(firingTime.Add(-30 * time.Minute))
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{
+					Name:              "test-notification",
+					LastResolvedTime:  &previousResolvedTime,
+					ResolvedCount:     1,
+					MeanTimeToResolve: &metav1.Duration{Duration: 4 * time.Hour},
+				},
+			}
+			testManagedNotification.Status.NotificationRecords[0].Conditions = ocmagentv1alpha1.Conditions{
+				{Type: ocmagentv1alpha1.ConditionAlertFiring, Status: corev1.ConditionFalse, LastTransitionTime: &firingTime},
+				{Type: ocmagentv1alpha1.ConditionAlertResolved, Status: corev1.ConditionTrue, LastTransitionTime: &resolvedTime},
+			}
+			before := sampleCount(localmetrics.MetricNotificationTimeToResolve)
+			expectPatch()
+			reconcileAgent()
+			summary := patched.Status.ResolutionSummaries[0]
+			Expect(summary.LastResolvedTime.Time).To(BeTemporally("==", resolvedTime.Time))
+			Expect(summary.ResolvedCount).To(Equal(int32(2)))
+			Expect(summary.LastTimeToResolve.Duration).To(Equal(2 * time.Hour))
+			Expect(summary.MeanTimeToResolve.Duration).To(Equal(3 * time.Hour))
+			Expect(summary.RecentFlaps).To(HaveLen(1))
+			Expect(summary.RecentFlaps[0].Time).To(BeTemporally("==", firingTime.Time))
+			Expect(sampleCount(localmetrics.MetricNotificationTimeToResolve) - before).To(Equal(uint64(1)))
+		})
+
+		It("counts a resolution only once however often it is reconciled", func() {
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{
+					Name:              "test-notification",
+					LastResolvedTime:  &resolvedTime,
+					ResolvedCount:     1,
+					LastTimeToResolve: &metav1.Duration{Duration: 2 * time.Hour},
+					MeanTimeToResolve: &metav1.Duration{Duration: 2 * time.Hour},
+				},
+			}
+			testManagedNotification.Status.NotificationRecords[0].Conditions = ocmagentv1alpha1.Conditions{
+				{Type: ocmagentv1alpha1.ConditionAlertFiring, Status: corev1.ConditionFalse, LastTransitionTime: &firingTime},
+				{Type: ocmagentv1alpha1.ConditionAlertResolved, Status: corev1.ConditionTrue, LastTransitionTime: &resolvedTime},
+			}
+			before := sampleCount(localmetrics.MetricNotificationTimeToResolve)
+			mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testManagedNotification)
+			mockClient.EXPECT().Status().Times(0)
+			reconcileAgent()
+			Expect(sampleCount(localmetrics.MetricNotificationTimeToResolve)).To(Equal(before))
+		})
+
+		It("counts the alert firing again shortly after resolving as a flap", func() {
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{Name: "test-notification", ResolvedCount: 1, LastResolvedTime: &resolvedTime},
+			}
+			refiringTime := metav1.NewTime(resolvedTime.Add(30 * time.Minute))
+			setConditions(corev1.ConditionTrue, refiringTime)
+			expectPatch()
+			result := reconcileAgent()
+			summary := patched.Status.ResolutionSummaries[0]
+			Expect(summary.FiringSince.Time).To(BeTemporally("==", refiringTime.Time))
+			Expect(summary.RecentFlaps).To(HaveLen(1))
+			// The ManagedNotification is reconciled again once the flap leaves the flapping count window
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Until(refiringTime.Add(operatorconfig.FlappingCountWindowDefault)), time.Minute))
+			Expect(testutil.CollectAndCompare(localmetrics.MetricNotifications, strings.NewReader(`
+# HELP ocm_agent_operator_notification_flaps Number of times the alert of a notification fired again shortly after resolving, over the flapping count window
+# TYPE ocm_agent_operator_notification_flaps gauge
+ocm_agent_operator_notification_flaps{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_flaps{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 1
+`), "ocm_agent_operator_notification_flaps")).To(Succeed())
+		})
+
+		It("doesn't count the alert firing again after the flapping window", func() {
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{Name: "test-notification", ResolvedCount: 1, LastResolvedTime: &firingTime},
+			}
+			setConditions(corev1.ConditionTrue, resolvedTime)
+			expectPatch()
+			result := reconcileAgent()
+			Expect(patched.Status.ResolutionSummaries[0].RecentFlaps).To(BeEmpty())
+			Expect(result.RequeueAfter).To(BeZero())
+		})
+
+		It("drops the flaps that left the flapping count window", func() {
+			oldFlap := metav1.NewTime(time.Now().Add(-operatorconfig.FlappingCountWindowDefault - time.Minute))
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{Name: "test-notification", ResolvedCount: 1, LastResolvedTime: &resolvedTime, RecentFlaps: []metav1.Time{oldFlap}},
+			}
+			setConditions(corev1.ConditionFalse, resolvedTime)
+			expectPatch()
+			reconcileAgent()
+			Expect(patched.Status.ResolutionSummaries[0].RecentFlaps).To(BeNil())
+		})
+
+		It("doesn't observe the time to resolve if the summary can't be updated", func() {
+			testManagedNotification.Status.ResolutionSummaries = []ocmagentv1alpha1.NotificationResolutionSummary{
+				{Name: "test-notification", FiringSince: &firingTime},
+			}
+			setConditions(corev1.ConditionFalse, resolvedTime)
+			patchErr := fmt.Errorf("fake error")
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testManagedNotification),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(patchErr),
+			)
+			before := sampleCount(localmetrics.MetricNotificationTimeToResolve)
+			_, err := managedNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testNamespacedName})
+			Expect(err).To(Equal(patchErr))
+			Expect(sampleCount(localmetrics.MetricNotificationTimeToResolve)).To(Equal(before))
+		})
+	})
+
+	When("the ManagedNotification is removed", func() {
+		It("removes the metrics of its notifications", func() {
+			localmetrics.UpdateMetricNotifications(testNamespacedName.String(), []localmetrics.NotificationState{{Name: "test-notification", Severity: "Warning"}})
+			mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(k8serrs.NewNotFound(schema.GroupResource{}, testNamespacedName.Name))
+			_, err := managedNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.CollectAndCount(localmetrics.MetricNotifications)).To(Equal(0))
+		})
+	})
+
+	When("the ManagedNotification can't be read", func() {
+		It("returns the error", func() {
+			mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(fmt.Errorf("fake error"))
+			_, err := managedNotificationReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testNamespacedName})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+// sampleCount returns the number of times the time to resolve of the test notification was observed
+func sampleCount(h *prometheus.HistogramVec) uint64 {
+	m := &dto.Metric{}
+	Expect(h.WithLabelValues("openshift-ocm-agent-operator/sre-managed-notifications", "test-notification", "Warning").(prometheus.Metric).Write(m)).To(Succeed())
+	return m.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,123 @@
+package managednotification
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+)
+
+// updateResolutionSummary updates the resolution summary of a notification from the conditions
+// of its record, and returns the time the alert fired for if it resolved since the summary was
+// last updated.
+//
+// A resolution is processed once the AlertResolved condition transitioned after the
+// lastResolvedTime of the summary, so that each resolution is counted exactly once however
+// often the notification is reconciled, including the resolutions that happened before the
+// operator started or between two reconciles. The alert fired from the firingSince of the
+// summary, or from the transition of the AlertFiring condition if it wasn't seen firing. The
+// OCM Agent refreshes the time of the AlertFiring condition while the alert fires, so the start
+// of the firing is kept in the summary when it is first seen.
+func updateResolutionSummary(summary *ocmagentv1alpha1.NotificationResolutionSummary, record *ocmagentv1alpha1.NotificationRecord,
+	cfg operatorconfig.Config, now time.Time) (time.Duration, bool) {
+	var timeToResolve time.Duration
+	resolved := false
+
+	if record != nil {
+		firingCondition := record.Conditions.GetCondition(ocmagentv1alpha1.ConditionAlertFiring)
+		resolvedCondition := record.Conditions.GetCondition(ocmagentv1alpha1.ConditionAlertResolved)
+		switch {
+		case firingCondition != nil && firingCondition.Status == corev1.ConditionTrue:
+			if summary.FiringSince == nil {
+				firingSince := conditionTime(firingCondition, now)
+				summary.FiringSince = &firingSince
+				recordFlap(summary, firingSince, cfg)
+			}
+		case resolvedCondition != nil && resolvedCondition.Status == corev1.ConditionTrue:
+			resolvedTime := conditionTime(resolvedCondition, now)
+			if summary.LastResolvedTime != nil && !resolvedTime.After(summary.LastResolvedTime.Time) {
+				break
+			}
+			firingSince := summary.FiringSince
+			if firingSince == nil && firingCondition != nil {
+				// The alert fired and resolved without being seen firing
+				t := conditionTime(firingCondition, now)
+				firingSince = &t
+				recordFlap(summary, t, cfg)
+			}
+			summary.FiringSince = nil
+			summary.LastResolvedTime = &resolvedTime
+			if firingSince == nil {
+				// The alert isn't known to have fired, so the resolution isn't counted
+				break
+			}
+
+			timeToResolve = resolvedTime.Sub(firingSince.Time)
+			if timeToResolve < 0 {
+				timeToResolve = 0
+			}
+			resolved = true
+
+			var mean time.Duration
+			if summary.MeanTimeToResolve != nil {
+				mean = summary.MeanTimeToResolve.Duration
+			}
+			summary.ResolvedCount++
+			mean += (timeToResolve - mean) / time.Duration(summary.ResolvedCount)
+
+			summary.LastTimeToResolve = &metav1.Duration{Duration: timeToResolve}
+			summary.MeanTimeToResolve = &metav1.Duration{Duration: mean}
+		}
+	}
+
+	// Only the flaps within the flapping count window are kept
+	var recentFlaps []metav1.Time
+	for _, t := range summary.RecentFlaps {
+		if now.Sub(t.Time) < cfg.FlappingCountWindow {
+			recentFlaps = append(recentFlaps, t)
+		}
+	}
+	summary.RecentFlaps = recentFlaps
+
+	return timeToResolve, resolved
+}
+
+// recordFlap records the alert firing at the given time as a flap if it fired within the
+// flapping window of last resolving
+func recordFlap(summary *ocmagentv1alpha1.NotificationResolutionSummary, firingSince metav1.Time, cfg operatorconfig.Config) {
+	if summary.LastResolvedTime != nil && !firingSince.Before(summary.LastResolvedTime) &&
+		firingSince.Sub(summary.LastResolvedTime.Time) <= cfg.FlappingWindow {
+		summary.RecentFlaps = append(summary.RecentFlaps, firingSince)
+	}
+}
+
+// conditionTime returns the time of the last transition of the condition, or now if it has none
+func conditionTime(condition *ocmagentv1alpha1.NotificationCondition, now time.Time) metav1.Time {
+	if condition.LastTransitionTime != nil {
+		return *condition.LastTransitionTime
+	}
+	return metav1.NewTime(now)
+}
+
+// isEmpty returns whether nothing was recorded in the resolution summary
+func isEmpty(summary ocmagentv1alpha1.NotificationResolutionSummary) bool {
+	return summary.FiringSince == nil && summary.ResolvedCount == 0 && len(summary.RecentFlaps) == 0
+}
+
+// nextFlapExpiry returns the time until the first of the recent flaps of the summaries leaves the
+// flapping count window, or 0 if there are none
+func nextFlapExpiry(summaries []ocmagentv1alpha1.NotificationResolutionSummary, cfg operatorconfig.Config, now time.Time) time.Duration {
+	var next time.Duration
+	for _, summary := range summaries {
+		for _, t := range summary.RecentFlaps {
+			expiry := t.Add(cfg.FlappingCountWindow).Sub(now)
+			if next == 0 || expiry < next {
+				next = expiry
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,121 @@
+package ocmagent
+
+import (
+	"context"
+
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+// labelledObjects are the kinds of the resources that are only created by the operator in its
+// namespace, so that the manager's cache only needs to hold those labelled for an OcmAgent.
+// Secrets aren't listed, as the token secrets of an OcmAgent may be provided by the user.
+var labelledObjects = []client.Object{
+	&appsv1.Deployment{},
+	&corev1.ConfigMap{},
+	&corev1.Service{},
+	&netv1.NetworkPolicy{},
+	&monitorv1.ServiceMonitor{},
+	&monitorv1.PrometheusRule{},
+	&policyv1.PodDisruptionBudget{},
+	&autoscalingv2.HorizontalPodAutoscaler{},
+	&corev1.ServiceAccount{},
+	&rbacv1.Role{},
+	&rbacv1.RoleBinding{},
+}
+
+// NewCacheOptions returns the options of the manager's cache. The cache is scoped to the
+// operator namespace, and only holds the resources of the managed kinds that are labelled
+// with an OcmAgent instance.
+func NewCacheOptions(operatorNamespace string) cache.Options {
+	instance, err := labels.NewRequirement(oah.OCMAgentInstanceLabel, selection.Exists, nil)
+	if err != nil {
+		// The requirement is built from constants, so it is always valid
+		panic(err)
+	}
+	byObject := map[client.Object]cache.ByObject{}
+	for _, obj := range labelledObjects {
+		byObject[obj] = cache.ByObject{Label: labels.NewSelector().Add(*instance)}
+	}
+	return cache.Options{
+		Namespaces: []string{operatorNamespace},
+		ByObject:   byObject,
+	}
+}
+
+// NewHandlerClient returns the client of the OCM Agent handler. Its reads are served from
+// the manager's cache and the supplied cluster resource cache, while its writes go to the
+// API server.
+func NewHandlerClient(mgr ctrl.Manager, clusterCache cache.Cache, operatorNamespace string) (client.Client, error) {
+	return client.New(mgr.GetConfig(), client.Options{
+		HTTPClient: mgr.GetHTTPClient(),
+		Scheme:     mgr.GetScheme(),
+		Mapper:     mgr.GetRESTMapper(),
+		Cache: &client.CacheOptions{
+			Reader: newHandlerReader(operatorNamespace, mgr.GetCache(), clusterCache, mgr.GetAPIReader()),
+		},
+	})
+}
+
+// handlerReader serves each read from the reader that holds the objects of its namespace.
+// Objects in the operator namespace and cluster-scoped objects are read from the manager's
+// cache, and the cluster resources that are watched outside of the operator namespace from
+// the cluster resource cache. Objects in any other namespace, such as the target namespace
+// of an OcmAgent, are read from the API server.
+type handlerReader struct {
+	operatorNamespace string
+	managerCache      client.Reader
+	clusterCache      client.Reader
+	clusterNamespaces map[string]bool
+	apiReader         client.Reader
+}
+
+func newHandlerReader(operatorNamespace string, managerCache, clusterCache, apiReader client.Reader) *handlerReader {
+	clusterNamespaces := map[string]bool{}
+	for _, w := range clusterWatches {
+		if w.name.Namespace != "" {
+			clusterNamespaces[w.name.Namespace] = true
+		}
+	}
+	return &handlerReader{
+		operatorNamespace: operatorNamespace,
+		managerCache:      managerCache,
+		clusterCache:      clusterCache,
+		clusterNamespaces: clusterNamespaces,
+		apiReader:         apiReader,
+	}
+}
+
+// readerFor returns the reader of the objects in the given namespace
+func (r *handlerReader) readerFor(namespace string) client.Reader {
+	switch {
+	case namespace == "" || namespace == r.operatorNamespace:
+		return r.managerCache
+	case r.clusterNamespaces[namespace]:
+		return r.clusterCache
+	default:
+		return r.apiReader
+	}
+}
+
+func (r *handlerReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return r.readerFor(key.Namespace).Get(ctx, key, obj, opts...)
+}
+
+func (r *handlerReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	return r.readerFor(listOpts.Namespace).List(ctx, list, opts...)
+}
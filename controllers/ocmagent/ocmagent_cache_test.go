@@ -0,0 +1,81 @@
+package ocmagent
+
+import (
+	"github.com/golang/mock/gomock"
+
+	configv1 "github.com/openshift/api/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	oahconst "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("OCMAgent Controller Caches", func() {
+	const operatorNamespace = "openshift-ocm-agent-operator"
+
+	Context("Building the manager's cache options", func() {
+		It("scopes the cache to the operator namespace", func() {
+			opts := NewCacheOptions(operatorNamespace)
+			Expect(opts.Namespaces).To(ConsistOf(operatorNamespace))
+		})
+		It("only caches the managed resources that are labelled for an OcmAgent", func() {
+			opts := NewCacheOptions(operatorNamespace)
+			Expect(opts.ByObject).To(HaveLen(len(labelledObjects)))
+			for obj, byObject := range opts.ByObject {
+				Expect(byObject.Label.Matches(labels.Set{oahconst.OCMAgentInstanceLabel: "ocmagent"})).To(BeTrue(), "%T", obj)
+				Expect(byObject.Label.Matches(labels.Set{})).To(BeFalse(), "%T", obj)
+			}
+		})
+	})
+
+	Context("Reading the resources of the OCM Agent handler", func() {
+		var (
+			mockCtrl         *gomock.Controller
+			mockManagerCache *clientmocks.MockClient
+			mockClusterCache *clientmocks.MockClient
+			mockAPIReader    *clientmocks.MockClient
+			reader           *handlerReader
+		)
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockManagerCache = clientmocks.NewMockClient(mockCtrl)
+			mockClusterCache = clientmocks.NewMockClient(mockCtrl)
+			mockAPIReader = clientmocks.NewMockClient(mockCtrl)
+			reader = newHandlerReader(operatorNamespace, mockManagerCache, mockClusterCache, mockAPIReader)
+		})
+		It("reads the resources in the operator namespace from the manager's cache", func() {
+			key := types.NamespacedName{Namespace: operatorNamespace, Name: "ocmagent"}
+			mockManagerCache.EXPECT().Get(gomock.Any(), key, gomock.Any()).Times(1)
+			Expect(reader.Get(testconst.Context, key, &appsv1.Deployment{})).To(Succeed())
+		})
+		It("reads cluster-scoped resources from the manager's cache", func() {
+			mockManagerCache.EXPECT().Get(gomock.Any(), oahconst.ClusterVersionNamespacedName, gomock.Any()).Times(1)
+			Expect(reader.Get(testconst.Context, oahconst.ClusterVersionNamespacedName, &configv1.ClusterVersion{})).To(Succeed())
+		})
+		It("reads the pull secret and the CAMO ConfigMap from the cluster resource cache", func() {
+			mockClusterCache.EXPECT().Get(gomock.Any(), oahconst.PullSecretNamespacedName, gomock.Any()).Times(1)
+			mockClusterCache.EXPECT().Get(gomock.Any(), oahconst.CAMOConfigMapNamespacedName, gomock.Any()).Times(1)
+			Expect(reader.Get(testconst.Context, oahconst.PullSecretNamespacedName, &corev1.Secret{})).To(Succeed())
+			Expect(reader.Get(testconst.Context, oahconst.CAMOConfigMapNamespacedName, &corev1.ConfigMap{})).To(Succeed())
+		})
+		It("reads the resources in other namespaces from the API server", func() {
+			key := types.NamespacedName{Namespace: "ocm-agent-staging", Name: "ocmagent"}
+			mockAPIReader.EXPECT().Get(gomock.Any(), key, gomock.Any()).Times(1)
+			Expect(reader.Get(testconst.Context, key, &appsv1.Deployment{})).To(Succeed())
+		})
+		It("lists the resources of a namespace from its reader", func() {
+			mockManagerCache.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+			mockAPIReader.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+			Expect(reader.List(testconst.Context, &appsv1.DeploymentList{}, client.InNamespace(operatorNamespace))).To(Succeed())
+			Expect(reader.List(testconst.Context, &appsv1.DeploymentList{}, client.InNamespace("ocm-agent-staging"))).To(Succeed())
+		})
+	})
+})
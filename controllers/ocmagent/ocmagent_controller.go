@@ -18,22 +18,32 @@ package ocmagent
 
 import (
 	"context"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	ctrlconst "github.com/openshift/ocm-agent-operator/pkg/consts/controller"
 	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
 	"github.com/openshift/ocm-agent-operator/pkg/ocmagenthandler"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
 	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -42,8 +52,25 @@ type OcmAgentReconciler struct {
 	Client                 client.Client
 	Scheme                 *runtime.Scheme
 	OCMAgentHandlerBuilder ocmagenthandler.OcmAgentHandlerBuilder
+	// ReconcileTimeout bounds the duration of a reconcile, including the API calls
+	// of the OCM Agent handler. No timeout is applied if it is not set.
+	ReconcileTimeout time.Duration
+	// ClusterCache holds the cluster resources that are watched outside of the operator
+	// namespace. It is created by SetupWithManager if it is not set.
+	ClusterCache cache.Cache
+	// Config holds the operator configuration, the default configuration if it is nil
+	Config *operatorconfig.Store
+	// Recorder records the Events of a reconcile on the OcmAgent
+	Recorder record.EventRecorder
 }
 
+// reasonResourcesRemoved is the reason of the Event recorded when the resources of
+// a deleted OcmAgent were removed
+const reasonResourcesRemoved = "ResourcesRemoved"
+
+// controllerName is the name of the controller in its metrics
+const controllerName = "ocmagent"
+
 var log = logf.Log.WithName("controller_ocmagent")
 
 var _ reconcile.Reconciler = &OcmAgentReconciler{}
@@ -51,6 +78,7 @@ var _ reconcile.Reconciler = &OcmAgentReconciler{}
 //+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=ocmagents,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=ocmagents/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=ocmagents/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -62,8 +90,29 @@ var _ reconcile.Reconciler = &OcmAgentReconciler{}
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.2/pkg/reconcile
 func (r *OcmAgentReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, request)
+	localmetrics.ObserveMetricReconcileDuration(controllerName, time.Since(start))
+	if err == nil {
+		localmetrics.UpdateMetricLastSuccessfulReconcile(controllerName)
+	}
+	return result, err
+}
+
+// reconcile reconciles the OcmAgent, while Reconcile records the duration and
+// outcome of each reconcile in the metrics
+func (r *OcmAgentReconciler) reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+
+	if r.ReconcileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.ReconcileTimeout)
+		defer cancel()
+	}
 
-	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	// The request logger is passed on through the context, so that the log lines
+	// of the OCM Agent handler carry its values
+	reqLogger := logf.FromContext(ctx).WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	ctx = logf.IntoContext(ctx, reqLogger)
 	reqLogger.Info("Reconciling OCMAgent")
 
 	// Fetch the OCMAgent instance
@@ -78,38 +127,48 @@ func (r *OcmAgentReconciler) Reconcile(ctx context.Context, request reconcile.Re
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
-		log.Error(err, "Failed to retrieve OCMAgent. Will retry on next reconcile.")
+		reqLogger.Error(err, "Failed to retrieve OCMAgent. Will retry on next reconcile.")
 		return reconcile.Result{}, err
 	}
 	localmetrics.ResetMetricOcmAgentResourceAbsent()
-	oaohandler, err := r.OCMAgentHandlerBuilder.New()
+	oaohandler, err := r.OCMAgentHandlerBuilder.New(ctx)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
 	// Is the OCMAgent being deleted?
 	if !instance.DeletionTimestamp.IsZero() {
-		log.V(2).Info("Entering EnsureOCMAgentResourcesAbsent")
-		err := oaohandler.EnsureOCMAgentResourcesAbsent(instance)
+		reqLogger.V(2).Info("Entering EnsureOCMAgentResourcesAbsent")
+		err := oaohandler.EnsureOCMAgentResourcesAbsent(ctx, instance)
 		if err != nil {
-			log.Error(err, "Failed to remove OCMAgent. Will retry on next reconcile.")
+			reqLogger.Error(err, "Failed to remove OCMAgent. Will retry on next reconcile.")
+			r.Recorder.Eventf(&instance, corev1.EventTypeWarning, reasonReconcileFailed, "Failed to remove the OCM Agent resources: %v", err)
 			return reconcile.Result{}, err
 		}
 		// The finalizer can now be removed
 		if controllerutil.ContainsFinalizer(&instance, ctrlconst.ReconcileOCMAgentFinalizer) {
 			controllerutil.RemoveFinalizer(&instance, ctrlconst.ReconcileOCMAgentFinalizer)
 			if err := r.Client.Update(ctx, &instance); err != nil {
-				log.Error(err, "Failed to remove finalizer from OCMAgent resource. Will retry on next reconcile.")
+				reqLogger.Error(err, "Failed to remove finalizer from OCMAgent resource. Will retry on next reconcile.")
 				return reconcile.Result{}, err
 			}
 		}
-		log.Info("Successfully removed OCMAgent resources.")
+		reqLogger.Info("Successfully removed OCMAgent resources.")
+		r.Recorder.Event(&instance, corev1.EventTypeNormal, reasonResourcesRemoved, "Removed the OCM Agent resources")
 	} else {
 		// There needs to be an OCM Agent
-		log.V(2).Info("Entering EnsureOCMAgentResourcesExist")
-		err := oaohandler.EnsureOCMAgentResourcesExist(instance)
+		reqLogger.V(2).Info("Entering EnsureOCMAgentResourcesExist")
+		observedStatus := instance.Status.DeepCopy()
+		err := oaohandler.EnsureOCMAgentResourcesExist(ctx, &instance)
+		if statusErr := r.updateStatus(ctx, &instance, observedStatus, err); statusErr != nil {
+			reqLogger.Error(statusErr, "Failed to update OCMAgent status. Will retry on next reconcile.")
+			if err == nil {
+				return reconcile.Result{}, statusErr
+			}
+		}
 		if err != nil {
-			log.Error(err, "Failed to create OCMAgent. Will retry on next reconcile.")
+			reqLogger.Error(err, "Failed to create OCMAgent. Will retry on next reconcile.")
+			r.Recorder.Eventf(&instance, corev1.EventTypeWarning, reasonReconcileFailed, "Failed to reconcile the OCM Agent resources: %v", err)
 			return reconcile.Result{}, err
 		}
 
@@ -117,11 +176,12 @@ func (r *OcmAgentReconciler) Reconcile(ctx context.Context, request reconcile.Re
 		if !controllerutil.ContainsFinalizer(&instance, ctrlconst.ReconcileOCMAgentFinalizer) {
 			controllerutil.AddFinalizer(&instance, ctrlconst.ReconcileOCMAgentFinalizer)
 			if err := r.Client.Update(ctx, &instance); err != nil {
-				log.Error(err, "Failed to apply finalizer to OCMAgent resource. Will retry on next reconcile.")
+				reqLogger.Error(err, "Failed to apply finalizer to OCMAgent resource. Will retry on next reconcile.")
 				return reconcile.Result{}, err
 			}
 		}
-		log.Info("Successfully setup OCMAgent resources.")
+		reqLogger.Info("Successfully setup OCMAgent resources.")
+		return reconcile.Result{RequeueAfter: r.Config.Get().OcmAgentController.RequeueInterval}, nil
 	}
 
 	return reconcile.Result{}, nil
@@ -130,13 +190,34 @@ func (r *OcmAgentReconciler) Reconcile(ctx context.Context, request reconcile.Re
 // SetupWithManager sets up the controller with the Manager.
 func (r *OcmAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&ocmagentv1alpha1.OcmAgent{}).
+	b := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Config.Get().OcmAgentController.MaxConcurrentReconciles}).
+		// Status writes don't change the generation, so they won't trigger another reconcile, while
+		// changes of the annotations and labels are reconciled
+		For(&ocmagentv1alpha1.OcmAgent{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+			predicate.LabelChangedPredicate{},
+		))).
 		Owns(&netv1.NetworkPolicy{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&monitorv1.ServiceMonitor{}).
-		Complete(r)
+		Owns(&monitorv1.PrometheusRule{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{})
+
+	if r.ClusterCache == nil {
+		clusterCache, err := NewClusterResourceCache(mgr)
+		if err != nil {
+			return err
+		}
+		r.ClusterCache = clusterCache
+	}
+	return r.watchClusterResources(b, r.ClusterCache).Complete(r)
 }
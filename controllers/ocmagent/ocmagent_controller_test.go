@@ -2,21 +2,33 @@ package ocmagent_test
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/golang/mock/gomock"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	"github.com/openshift/ocm-agent-operator/controllers/ocmagent"
 	ctrlconst "github.com/openshift/ocm-agent-operator/pkg/consts/controller"
+	oahconst "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
 	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
 	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
 	ocmagenthandlermocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/ocmagenthandler"
 )
@@ -24,27 +36,36 @@ import (
 var _ = Describe("OCMAgent Controller", func() {
 	var (
 		mockClient                 *clientmocks.MockClient
+		mockStatusWriter           *clientmocks.MockStatusWriter
 		mockCtrl                   *gomock.Controller
 		mockOcmAgentHandler        *ocmagenthandlermocks.MockOCMAgentHandler
 		ocmAgentReconciler         *ocmagent.OcmAgentReconciler
 		testOcmAgent               *ocmagentv1alpha1.OcmAgent
 		mockOcmAgentHandlerBuilder *ocmagenthandlermocks.MockOcmAgentHandlerBuilder
+		testRecorder               *record.FakeRecorder
 	)
 
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		mockClient = clientmocks.NewMockClient(mockCtrl)
+		mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
 		mockOcmAgentHandler = ocmagenthandlermocks.NewMockOCMAgentHandler(mockCtrl)
 		mockOcmAgentHandlerBuilder = ocmagenthandlermocks.NewMockOcmAgentHandlerBuilder(mockCtrl)
+		testRecorder = record.NewFakeRecorder(10)
 		ocmAgentReconciler = &ocmagent.OcmAgentReconciler{
 			Client:                 mockClient,
 			Scheme:                 testconst.Scheme,
 			OCMAgentHandlerBuilder: mockOcmAgentHandlerBuilder,
+			Recorder:               testRecorder,
 		}
 	})
 
 	Context("Reconciling an OCM Agent CR", func() {
+		var testDeploymentNamespacedName types.NamespacedName
+		var notFound *k8serrs.StatusError
 		BeforeEach(func() {
+			testDeploymentNamespacedName = oahconst.BuildNamespacedName(testconst.OCMAgentNamespacedName.Name)
+			notFound = k8serrs.NewNotFound(schema.GroupResource{}, testDeploymentNamespacedName.Name)
 			testOcmAgent = &ocmagentv1alpha1.OcmAgent{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      testconst.OCMAgentNamespacedName.Name,
@@ -59,17 +80,148 @@ var _ = Describe("OCMAgent Controller", func() {
 			It("Creates an OCM Agent", func() {
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
-					mockOcmAgentHandlerBuilder.EXPECT().New().Return(mockOcmAgentHandler, nil),
-					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesExist(*testOcmAgent).Times(1),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesExist(gomock.Any(), testOcmAgent).Times(1),
+					mockClient.EXPECT().Get(gomock.Any(), testDeploymentNamespacedName, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1),
 					mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
 						func(ctx context.Context, o *ocmagentv1alpha1.OcmAgent, opts ...client.UpdateOptions) error {
 							Expect(o.Finalizers).To(ContainElement(ctrlconst.ReconcileOCMAgentFinalizer))
 							return nil
 						}),
 				)
-				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
+				result, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
 				Expect(err).To(BeNil())
 				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(operatorconfig.OcmAgentRequeueIntervalDefault))
+				Expect(testutil.CollectAndCount(localmetrics.MetricReconcileDuration)).To(Equal(1))
+				Expect(testutil.ToFloat64(localmetrics.MetricLastSuccessfulReconcileAge)).To(BeNumerically("<", 60))
+			})
+			It("Passes a reconcile context bounded by the reconcile timeout to the handler", func() {
+				ocmAgentReconciler.ReconcileTimeout = time.Minute
+				testOcmAgent.Finalizers = []string{ctrlconst.ReconcileOCMAgentFinalizer}
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesExist(gomock.Any(), testOcmAgent).Times(1).DoAndReturn(
+						func(ctx context.Context, o *ocmagentv1alpha1.OcmAgent) error {
+							deadline, ok := ctx.Deadline()
+							Expect(ok).To(BeTrue())
+							Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+							return nil
+						}),
+					mockClient.EXPECT().Get(gomock.Any(), testDeploymentNamespacedName, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1),
+				)
+				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("Reports the status of the deployment in the target namespace", func() {
+				testOcmAgent.Finalizers = []string{ctrlconst.ReconcileOCMAgentFinalizer}
+				testOcmAgent.Spec.TargetNamespace = "ocm-agent-staging"
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesExist(gomock.Any(), testOcmAgent).Times(1),
+					mockClient.EXPECT().Get(gomock.Any(), types.NamespacedName{Namespace: "ocm-agent-staging", Name: testOcmAgent.Name}, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1),
+				)
+				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("An OCM Agent has been deployed", func() {
+			var testDeployment *appsv1.Deployment
+			BeforeEach(func() {
+				testOcmAgent.Generation = 2
+				testOcmAgent.Finalizers = []string{
+					ctrlconst.ReconcileOCMAgentFinalizer,
+				}
+				replicas := int32(1)
+				testDeployment = &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       testDeploymentNamespacedName.Name,
+						Namespace:  testDeploymentNamespacedName.Namespace,
+						Generation: 1,
+					},
+					Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+					Status: appsv1.DeploymentStatus{
+						ObservedGeneration: 1,
+						Replicas:           1,
+						UpdatedReplicas:    1,
+						ReadyReplicas:      1,
+						AvailableReplicas:  1,
+						Conditions: []appsv1.DeploymentCondition{{
+							Type:   appsv1.DeploymentAvailable,
+							Status: corev1.ConditionTrue,
+						}},
+					},
+				}
+			})
+			It("reports the OCM Agent as available", func() {
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesExist(gomock.Any(), testOcmAgent).Times(1),
+					mockClient.EXPECT().Get(gomock.Any(), testDeploymentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testDeployment),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, o *ocmagentv1alpha1.OcmAgent, opts ...client.SubResourceUpdateOption) error {
+							Expect(o.Status.ObservedGeneration).To(Equal(int64(2)))
+							Expect(o.Status.AvailableReplicas).To(Equal(int32(1)))
+							Expect(o.Status.ReadyReplicas).To(Equal(int32(1)))
+							Expect(o.Status.ServiceStatus).To(Equal(ocmagentv1alpha1.ConditionAvailable))
+							Expect(meta.IsStatusConditionTrue(o.Status.Conditions, ocmagentv1alpha1.ConditionAvailable)).To(BeTrue())
+							Expect(meta.IsStatusConditionFalse(o.Status.Conditions, ocmagentv1alpha1.ConditionProgressing)).To(BeTrue())
+							Expect(meta.IsStatusConditionFalse(o.Status.Conditions, ocmagentv1alpha1.ConditionDegraded)).To(BeTrue())
+							return nil
+						}),
+				)
+				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("reports the OCM Agent as progressing during a rollout", func() {
+				testDeployment.Generation = 2
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesExist(gomock.Any(), testOcmAgent).Times(1),
+					mockClient.EXPECT().Get(gomock.Any(), testDeploymentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testDeployment),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, o *ocmagentv1alpha1.OcmAgent, opts ...client.SubResourceUpdateOption) error {
+							Expect(meta.IsStatusConditionTrue(o.Status.Conditions, ocmagentv1alpha1.ConditionProgressing)).To(BeTrue())
+							return nil
+						}),
+				)
+				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("reports the OCM Agent as degraded when the reconcile fails", func() {
+				reconcileErr := fmt.Errorf("fake error")
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesExist(gomock.Any(), testOcmAgent).Times(1).Return(reconcileErr),
+					mockClient.EXPECT().Get(gomock.Any(), testDeploymentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testDeployment),
+					mockClient.EXPECT().Status().Return(mockStatusWriter),
+					mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, o *ocmagentv1alpha1.OcmAgent, opts ...client.SubResourceUpdateOption) error {
+							Expect(o.Status.ServiceStatus).To(Equal(ocmagentv1alpha1.ConditionDegraded))
+							degraded := meta.FindStatusCondition(o.Status.Conditions, ocmagentv1alpha1.ConditionDegraded)
+							Expect(degraded).NotTo(BeNil())
+							Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+							Expect(degraded.Message).To(Equal(reconcileErr.Error()))
+							return nil
+						}),
+				)
+				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
+				Expect(err).To(Equal(reconcileErr))
+				Expect(testRecorder.Events).To(Receive(Equal("Warning ReconcileFailed Failed to reconcile the OCM Agent resources: fake error")))
 			})
 		})
 
@@ -83,8 +235,8 @@ var _ = Describe("OCMAgent Controller", func() {
 			It("Deletes an OCM Agent", func() {
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
-					mockOcmAgentHandlerBuilder.EXPECT().New().Return(mockOcmAgentHandler, nil),
-					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesAbsent(gomock.Any()).Times(1),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesAbsent(gomock.Any(), gomock.Any()).Times(1),
 					mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
 						func(ctx context.Context, o *ocmagentv1alpha1.OcmAgent, opts ...client.UpdateOptions) error {
 							Expect(o.Finalizers).NotTo(ContainElement(ctrlconst.ReconcileOCMAgentFinalizer))
@@ -94,6 +246,19 @@ var _ = Describe("OCMAgent Controller", func() {
 				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
 				Expect(err).To(BeNil())
 				Expect(err).NotTo(HaveOccurred())
+				Expect(testRecorder.Events).To(Receive(ContainSubstring("Normal ResourcesRemoved")))
+			})
+			It("Keeps the finalizer and records the failure when the resources can't be removed", func() {
+				removeErr := fmt.Errorf("fake error")
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testconst.OCMAgentNamespacedName, gomock.Any()).Times(1).SetArg(2, *testOcmAgent),
+					mockOcmAgentHandlerBuilder.EXPECT().New(gomock.Any()).Return(mockOcmAgentHandler, nil),
+					mockOcmAgentHandler.EXPECT().EnsureOCMAgentResourcesAbsent(gomock.Any(), gomock.Any()).Times(1).Return(removeErr),
+				)
+				mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+				_, err := ocmAgentReconciler.Reconcile(testconst.Context, reconcile.Request{NamespacedName: testconst.OCMAgentNamespacedName})
+				Expect(err).To(Equal(removeErr))
+				Expect(testRecorder.Events).To(Receive(Equal("Warning ReconcileFailed Failed to remove the OCM Agent resources: fake error")))
 			})
 		})
 	})
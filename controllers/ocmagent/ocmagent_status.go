@@ -0,0 +1,150 @@
+package ocmagent
+
+import (
+	"context"
+	"reflect"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+const (
+	reasonAsExpected                 = "AsExpected"
+	reasonReconcileFailed            = "ReconcileFailed"
+	reasonDeploymentNotFound         = "DeploymentNotFound"
+	reasonReplicaFailure             = "ReplicaFailure"
+	reasonProgressDeadlineExceeded   = "ProgressDeadlineExceeded"
+	reasonMinimumReplicasAvailable   = "MinimumReplicasAvailable"
+	reasonMinimumReplicasUnavailable = "MinimumReplicasUnavailable"
+	reasonRollingOut                 = "RollingOut"
+	reasonRolloutComplete            = "RolloutComplete"
+)
+
+// updateStatus derives the OcmAgent status from the agent deployment and the outcome
+// of the reconcile, and writes it through the status subresource if it differs from
+// the status observed at the start of the reconcile.
+func (r *OcmAgentReconciler) updateStatus(ctx context.Context, instance *ocmagentv1alpha1.OcmAgent, observed *ocmagentv1alpha1.OcmAgentStatus, reconcileErr error) error {
+	var deployment *appsv1.Deployment
+	found := &appsv1.Deployment{}
+	err := r.Client.Get(ctx, oah.BuildTargetNamespacedName(instance.Spec.TargetNamespace, instance.Name), found)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	} else {
+		deployment = found
+	}
+
+	status := instance.Status.DeepCopy()
+	setStatusFromDeployment(status, instance.Generation, deployment, reconcileErr)
+	if reflect.DeepEqual(status, observed) {
+		return nil
+	}
+
+	instance.Status = *status
+	return r.Client.Status().Update(ctx, instance)
+}
+
+// setStatusFromDeployment populates the replica counts and the Available, Progressing
+// and Degraded conditions of the status from the supplied deployment, which is nil if
+// the deployment does not exist.
+func setStatusFromDeployment(status *ocmagentv1alpha1.OcmAgentStatus, generation int64, deployment *appsv1.Deployment, reconcileErr error) {
+	status.ObservedGeneration = generation
+
+	available := metav1.Condition{Type: ocmagentv1alpha1.ConditionAvailable, ObservedGeneration: generation}
+	progressing := metav1.Condition{Type: ocmagentv1alpha1.ConditionProgressing, ObservedGeneration: generation}
+	degraded := metav1.Condition{Type: ocmagentv1alpha1.ConditionDegraded, ObservedGeneration: generation}
+
+	if deployment == nil {
+		status.AvailableReplicas = 0
+		status.ReadyReplicas = 0
+
+		available.Status = metav1.ConditionFalse
+		available.Reason = reasonDeploymentNotFound
+		available.Message = "The OCM Agent deployment does not exist"
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = reasonDeploymentNotFound
+		progressing.Message = "Waiting for the OCM Agent deployment to be created"
+		degraded.Status = metav1.ConditionFalse
+		degraded.Reason = reasonAsExpected
+	} else {
+		status.AvailableReplicas = deployment.Status.AvailableReplicas
+		status.ReadyReplicas = deployment.Status.ReadyReplicas
+
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+
+		if c := deploymentCondition(deployment, appsv1.DeploymentAvailable); c != nil && c.Status == corev1.ConditionTrue && deployment.Status.AvailableReplicas > 0 {
+			available.Status = metav1.ConditionTrue
+			available.Reason = reasonMinimumReplicasAvailable
+			available.Message = c.Message
+		} else {
+			available.Status = metav1.ConditionFalse
+			available.Reason = reasonMinimumReplicasUnavailable
+			available.Message = "The OCM Agent deployment does not have minimum availability"
+		}
+
+		if deployment.Generation > deployment.Status.ObservedGeneration ||
+			deployment.Status.UpdatedReplicas < desired ||
+			deployment.Status.Replicas > deployment.Status.UpdatedReplicas ||
+			deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
+			progressing.Status = metav1.ConditionTrue
+			progressing.Reason = reasonRollingOut
+			progressing.Message = "The OCM Agent deployment is rolling out"
+		} else {
+			progressing.Status = metav1.ConditionFalse
+			progressing.Reason = reasonRolloutComplete
+			progressing.Message = "The OCM Agent deployment is up to date"
+		}
+
+		degraded.Status = metav1.ConditionFalse
+		degraded.Reason = reasonAsExpected
+		if c := deploymentCondition(deployment, appsv1.DeploymentReplicaFailure); c != nil && c.Status == corev1.ConditionTrue {
+			degraded.Status = metav1.ConditionTrue
+			degraded.Reason = reasonReplicaFailure
+			degraded.Message = c.Message
+		} else if c := deploymentCondition(deployment, appsv1.DeploymentProgressing); c != nil && c.Reason == reasonProgressDeadlineExceeded {
+			degraded.Status = metav1.ConditionTrue
+			degraded.Reason = reasonProgressDeadlineExceeded
+			degraded.Message = c.Message
+		}
+	}
+
+	// A failed reconcile takes precedence over the deployment state
+	if reconcileErr != nil {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = reasonReconcileFailed
+		degraded.Message = reconcileErr.Error()
+	}
+
+	meta.SetStatusCondition(&status.Conditions, available)
+	meta.SetStatusCondition(&status.Conditions, progressing)
+	meta.SetStatusCondition(&status.Conditions, degraded)
+
+	switch {
+	case degraded.Status == metav1.ConditionTrue:
+		status.ServiceStatus = ocmagentv1alpha1.ConditionDegraded
+	case available.Status == metav1.ConditionTrue:
+		status.ServiceStatus = ocmagentv1alpha1.ConditionAvailable
+	default:
+		status.ServiceStatus = ocmagentv1alpha1.ConditionProgressing
+	}
+}
+
+// deploymentCondition returns the deployment condition with the given type, or nil if it is not set
+func deploymentCondition(deployment *appsv1.Deployment, t appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if deployment.Status.Conditions[i].Type == t {
+			return &deployment.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package ocmagent
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+// clusterWatch is a cluster resource that the OCM Agent configuration is derived from,
+// but which isn't owned by an OcmAgent
+type clusterWatch struct {
+	object client.Object
+	name   types.NamespacedName
+}
+
+// clusterWatches are the cluster resources that trigger a reconcile of every OcmAgent when they change
+var clusterWatches = []clusterWatch{
+	// The pull secret contains the OCM access token of the OCM Agent
+	{object: &corev1.Secret{}, name: oah.PullSecretNamespacedName},
+	// The cluster proxy configures the proxy environment of the OCM Agent
+	{object: &configv1.Proxy{}, name: oah.ProxyNamespacedName},
+	// The cluster version contains the cluster ID of the OCM Agent configuration
+	{object: &configv1.ClusterVersion{}, name: oah.ClusterVersionNamespacedName},
+	// The CAMO ConfigMap is expected to point at the OCM Agent service
+	{object: &corev1.ConfigMap{}, name: oah.CAMOConfigMapNamespacedName},
+	// The operator configuration sets the ingress namespaces and the features of the OCM Agent.
+	// Its status is updated once the configuration is in effect, which triggers another reconcile.
+	{object: &ocmagentv1alpha1.OcmAgentOperatorConfig{}, name: types.NamespacedName{Name: ocmagentv1alpha1.OcmAgentOperatorConfigName}},
+}
+
+// isNamed returns a predicate that only accepts events for the object with the given name
+func isNamed(name types.NamespacedName) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == name.Namespace && obj.GetName() == name.Name
+	})
+}
+
+// enqueueAllOcmAgents maps an event to a reconcile request for every OcmAgent
+func (r *OcmAgentReconciler) enqueueAllOcmAgents(ctx context.Context, obj client.Object) []reconcile.Request {
+	ocmAgents := &ocmagentv1alpha1.OcmAgentList{}
+	if err := r.Client.List(ctx, ocmAgents); err != nil {
+		log.Error(err, "Failed to list OCMAgents to reconcile", "kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(ocmAgents.Items))
+	for _, ocmAgent := range ocmAgents.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ocmAgent)})
+	}
+	return requests
+}
+
+// NewClusterResourceCache returns a cache of the namespaced cluster resources that are watched
+// outside of the operator namespace. It only holds the watched objects themselves, so that the
+// operator doesn't watch any other resources in those namespaces.
+func NewClusterResourceCache(mgr ctrl.Manager) (cache.Cache, error) {
+	var namespaces []string
+	byObject := map[client.Object]cache.ByObject{}
+	for _, w := range clusterWatches {
+		if w.name.Namespace == "" {
+			continue
+		}
+		namespaces = append(namespaces, w.name.Namespace)
+		byObject[w.object] = cache.ByObject{Field: fields.OneTermEqualSelector("metadata.name", w.name.Name)}
+	}
+	clusterCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:     mgr.GetScheme(),
+		Mapper:     mgr.GetRESTMapper(),
+		Namespaces: namespaces,
+		ByObject:   byObject,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The manager starts the cache along with the controllers
+	if err := mgr.Add(clusterCache); err != nil {
+		return nil, err
+	}
+	return clusterCache, nil
+}
+
+// watchClusterResources adds the cluster resource watches to the controller builder.
+// Namespaced resources are watched through the supplied cluster resource cache, while
+// cluster-scoped resources are watched through the manager's cache.
+func (r *OcmAgentReconciler) watchClusterResources(b *builder.Builder, clusterCache cache.Cache) *builder.Builder {
+	for _, w := range clusterWatches {
+		eventHandler := handler.EnqueueRequestsFromMapFunc(r.enqueueAllOcmAgents)
+		predicates := builder.WithPredicates(isNamed(w.name))
+		if w.name.Namespace == "" {
+			b = b.Watches(w.object, eventHandler, predicates)
+		} else {
+			b = b.WatchesRawSource(source.Kind(clusterCache, w.object), eventHandler, predicates)
+		}
+	}
+	return b
+}
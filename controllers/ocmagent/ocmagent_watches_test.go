@@ -0,0 +1,84 @@
+package ocmagent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oahconst "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("OCMAgent Controller Watches", func() {
+	var (
+		mockClient         *clientmocks.MockClient
+		mockCtrl           *gomock.Controller
+		ocmAgentReconciler *OcmAgentReconciler
+		testPullSecret     *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		ocmAgentReconciler = &OcmAgentReconciler{
+			Client: mockClient,
+			Scheme: testconst.Scheme,
+		}
+		testPullSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      oahconst.PullSecretNamespacedName.Name,
+				Namespace: oahconst.PullSecretNamespacedName.Namespace,
+			},
+		}
+	})
+
+	Context("Mapping a cluster resource change to OcmAgents", func() {
+		It("enqueues every OcmAgent", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, list *ocmagentv1alpha1.OcmAgentList, opts ...client.ListOption) error {
+					list.Items = []ocmagentv1alpha1.OcmAgent{
+						{ObjectMeta: metav1.ObjectMeta{Name: "ocm-agent", Namespace: "ns-a"}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "ocm-agent", Namespace: "ns-b"}},
+					}
+					return nil
+				})
+			requests := ocmAgentReconciler.enqueueAllOcmAgents(context.TODO(), testPullSecret)
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[0].NamespacedName).To(Equal(types.NamespacedName{Name: "ocm-agent", Namespace: "ns-a"}))
+			Expect(requests[1].NamespacedName).To(Equal(types.NamespacedName{Name: "ocm-agent", Namespace: "ns-b"}))
+		})
+		It("enqueues nothing if the OcmAgents can't be listed", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(fmt.Errorf("fake error"))
+			requests := ocmAgentReconciler.enqueueAllOcmAgents(context.TODO(), testPullSecret)
+			Expect(requests).To(BeEmpty())
+		})
+	})
+
+	Context("Filtering cluster resource events", func() {
+		It("only accepts the watched resource", func() {
+			p := isNamed(oahconst.PullSecretNamespacedName)
+			Expect(p.Update(event.UpdateEvent{ObjectOld: testPullSecret, ObjectNew: testPullSecret})).To(BeTrue())
+			other := testPullSecret.DeepCopy()
+			other.Name = "other-secret"
+			Expect(p.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: other})).To(BeFalse())
+		})
+		It("accepts cluster-scoped resources", func() {
+			p := isNamed(oahconst.ProxyNamespacedName)
+			proxy := &configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: oahconst.ProxyNamespacedName.Name}}
+			Expect(p.Create(event.CreateEvent{Object: proxy})).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,143 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+)
+
+const (
+	reasonAsExpected      = "AsExpected"
+	reasonRestartRequired = "RestartRequired"
+)
+
+// OcmAgentOperatorConfigReconciler reconciles the OcmAgentOperatorConfig of the operator
+type OcmAgentOperatorConfigReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	// Config holds the configuration in effect, which is updated on each reconcile
+	Config *operatorconfig.Store
+	// Started is the configuration that the operator started with. Its reconcile
+	// concurrency stays in effect until the operator restarts.
+	Started operatorconfig.Config
+}
+
+// controllerName is the name of the controller in its metrics
+const controllerName = "operatorconfig"
+
+var log = logf.Log.WithName("controller_operatorconfig")
+
+var _ reconcile.Reconciler = &OcmAgentOperatorConfigReconciler{}
+
+//+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=ocmagentoperatorconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=ocmagent.managed.openshift.io,resources=ocmagentoperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile updates the configuration in effect from the OcmAgentOperatorConfig, and reports
+// the effective configuration in its status
+func (r *OcmAgentOperatorConfigReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, request)
+	localmetrics.ObserveMetricReconcileDuration(controllerName, time.Since(start))
+	if err == nil {
+		localmetrics.UpdateMetricLastSuccessfulReconcile(controllerName)
+	}
+	return result, err
+}
+
+// reconcile reconciles the OcmAgentOperatorConfig, while Reconcile records the duration and
+// outcome of each reconcile in the metrics
+func (r *OcmAgentOperatorConfigReconciler) reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := logf.FromContext(ctx).WithValues("Request.Name", request.Name)
+
+	instance := &ocmagentv1alpha1.OcmAgentOperatorConfig{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.Info("OcmAgentOperatorConfig not found, using the default configuration")
+			r.Config.Set(r.effective(operatorconfig.Default()))
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	requested := operatorconfig.FromSpec(instance.Spec)
+	cfg := r.effective(requested)
+	r.Config.Set(cfg)
+	reqLogger.Info("Applied the operator configuration")
+
+	status := instance.Status.DeepCopy()
+	status.ObservedGeneration = instance.Generation
+	effective := cfg.Spec()
+	status.Effective = &effective
+	applied := metav1.Condition{
+		Type:               ocmagentv1alpha1.ConditionConfigApplied,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonAsExpected,
+		ObservedGeneration: instance.Generation,
+	}
+	if !reflect.DeepEqual(requested, cfg) {
+		applied.Status = metav1.ConditionFalse
+		applied.Reason = reasonRestartRequired
+		applied.Message = "The maxConcurrentReconciles of the controllers take effect when the operator restarts"
+	}
+	meta.SetStatusCondition(&status.Conditions, applied)
+
+	if reflect.DeepEqual(*status, instance.Status) {
+		return reconcile.Result{}, nil
+	}
+	instance.Status = *status
+	return reconcile.Result{}, r.Client.Status().Update(ctx, instance)
+}
+
+// effective returns the supplied configuration with the reconcile concurrency that the
+// operator started with, as that can't change while the controllers run
+func (r *OcmAgentOperatorConfigReconciler) effective(cfg operatorconfig.Config) operatorconfig.Config {
+	cfg.OcmAgentController.MaxConcurrentReconciles = r.Started.OcmAgentController.MaxConcurrentReconciles
+	cfg.FleetNotificationController.MaxConcurrentReconciles = r.Started.FleetNotificationController.MaxConcurrentReconciles
+	return cfg
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OcmAgentOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	name := types.NamespacedName{Name: ocmagentv1alpha1.OcmAgentOperatorConfigName}
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status writes don't change the generation, so they won't trigger another reconcile
+		For(&ocmagentv1alpha1.OcmAgentOperatorConfig{}, builder.WithPredicates(
+			predicate.GenerationChangedPredicate{},
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == name.Name
+			}),
+		)).
+		Complete(r)
+}
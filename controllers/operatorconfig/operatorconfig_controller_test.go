@@ -0,0 +1,152 @@
+package operatorconfig_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/controllers/operatorconfig"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	opconfig "github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("OperatorConfig Controller", func() {
+	var (
+		mockClient       *clientmocks.MockClient
+		mockStatusWriter *clientmocks.MockStatusWriter
+		mockCtrl         *gomock.Controller
+		reconciler       *operatorconfig.OcmAgentOperatorConfigReconciler
+		store            *opconfig.Store
+		testConfig       *ocmagentv1alpha1.OcmAgentOperatorConfig
+		request          reconcile.Request
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
+		store = opconfig.NewStore(opconfig.Default())
+		reconciler = &operatorconfig.OcmAgentOperatorConfigReconciler{
+			Client:  mockClient,
+			Scheme:  testconst.Scheme,
+			Config:  store,
+			Started: opconfig.Default(),
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: ocmagentv1alpha1.OcmAgentOperatorConfigName}}
+		testConfig = &ocmagentv1alpha1.OcmAgentOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       ocmagentv1alpha1.OcmAgentOperatorConfigName,
+				Generation: 2,
+			},
+			Spec: ocmagentv1alpha1.OcmAgentOperatorConfigSpec{
+				OcmAgentController: ocmagentv1alpha1.ControllerConfig{
+					RequeueInterval: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+				NotificationRecordStaleTimeout: &metav1.Duration{Duration: 24 * time.Hour},
+				AllowedIngressNamespaces:       []string{"observatorium-mst-stage"},
+				FeatureGates: []ocmagentv1alpha1.FeatureGate{
+					{Name: ocmagentv1alpha1.FeatureOrphanCleanup, Enabled: false},
+				},
+			},
+		}
+	})
+
+	When("There is no OcmAgentOperatorConfig", func() {
+		It("Uses the default configuration", func() {
+			cfg := opconfig.Default()
+			cfg.AllowedIngressNamespaces = []string{"observatorium-mst-stage"}
+			store.Set(cfg)
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, request.Name)
+			mockClient.EXPECT().Get(gomock.Any(), request.NamespacedName, gomock.Any()).Return(notFound)
+			_, err := reconciler.Reconcile(testconst.Context, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(store.Get()).To(Equal(opconfig.Default()))
+		})
+	})
+
+	When("The OcmAgentOperatorConfig is set", func() {
+		It("Applies the configuration and reports the effective values", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), request.NamespacedName, gomock.Any()).SetArg(2, *testConfig),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, c *ocmagentv1alpha1.OcmAgentOperatorConfig, opts ...client.SubResourceUpdateOption) error {
+						Expect(c.Status.ObservedGeneration).To(Equal(int64(2)))
+						Expect(c.Status.Effective).NotTo(BeNil())
+						Expect(c.Status.Effective.OcmAgentController.RequeueInterval.Duration).To(Equal(10 * time.Minute))
+						Expect(c.Status.Effective.FleetNotificationController.RequeueInterval.Duration).To(Equal(opconfig.FleetNotificationRequeueIntervalDefault))
+						Expect(c.Status.Effective.OcmAgentController.MaxConcurrentReconciles).To(Equal(int32(1)))
+						Expect(c.Status.Effective.FeatureGates).To(ContainElements(
+							ocmagentv1alpha1.FeatureGate{Name: ocmagentv1alpha1.FeatureOrphanCleanup, Enabled: false},
+							ocmagentv1alpha1.FeatureGate{Name: ocmagentv1alpha1.FeatureStaleRecordCleanup, Enabled: true},
+						))
+						condition := meta.FindStatusCondition(c.Status.Conditions, ocmagentv1alpha1.ConditionConfigApplied)
+						Expect(condition).NotTo(BeNil())
+						Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+						return nil
+					}),
+			)
+			_, err := reconciler.Reconcile(testconst.Context, request)
+			Expect(err).NotTo(HaveOccurred())
+			cfg := store.Get()
+			Expect(cfg.OcmAgentController.RequeueInterval).To(Equal(10 * time.Minute))
+			Expect(cfg.NotificationRecordStaleTimeout).To(Equal(24 * time.Hour))
+			Expect(cfg.AllowedIngressNamespaces).To(ConsistOf("observatorium-mst-stage"))
+			Expect(cfg.Enabled(ocmagentv1alpha1.FeatureOrphanCleanup)).To(BeFalse())
+			Expect(cfg.Enabled(ocmagentv1alpha1.FeatureStaleRecordCleanup)).To(BeTrue())
+		})
+
+		It("Keeps the concurrency the operator started with until it restarts", func() {
+			testConfig.Spec.OcmAgentController.MaxConcurrentReconciles = 4
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), request.NamespacedName, gomock.Any()).SetArg(2, *testConfig),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, c *ocmagentv1alpha1.OcmAgentOperatorConfig, opts ...client.SubResourceUpdateOption) error {
+						Expect(c.Status.Effective.OcmAgentController.MaxConcurrentReconciles).To(Equal(int32(1)))
+						condition := meta.FindStatusCondition(c.Status.Conditions, ocmagentv1alpha1.ConditionConfigApplied)
+						Expect(condition).NotTo(BeNil())
+						Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+						Expect(condition.Reason).To(Equal("RestartRequired"))
+						return nil
+					}),
+			)
+			_, err := reconciler.Reconcile(testconst.Context, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(store.Get().OcmAgentController.MaxConcurrentReconciles).To(Equal(1))
+		})
+
+		It("Doesn't update a status that reports the configuration", func() {
+			cfg := opconfig.FromSpec(testConfig.Spec)
+			effective := cfg.Spec()
+			testConfig.Status = ocmagentv1alpha1.OcmAgentOperatorConfigStatus{
+				ObservedGeneration: 2,
+				Effective:          &effective,
+				Conditions: []metav1.Condition{{
+					Type:               ocmagentv1alpha1.ConditionConfigApplied,
+					Status:             metav1.ConditionTrue,
+					Reason:             "AsExpected",
+					ObservedGeneration: 2,
+					LastTransitionTime: metav1.Now(),
+				}},
+			}
+			mockClient.EXPECT().Get(gomock.Any(), request.NamespacedName, gomock.Any()).SetArg(2, *testConfig)
+			mockClient.EXPECT().Status().Times(0)
+			_, err := reconciler.Reconcile(testconst.Context, request)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,20 @@
+#!/usr/bin/env bash
+# Configures the generated CRDs to convert between API versions with the operator's conversion webhook.
+# controller-gen has no marker for the conversion strategy, so this runs after the CRDs are generated.
+set -euo pipefail
+
+CRD_DIR=${1:-deploy/crds}
+
+for crd in "${CRD_DIR}"/*.yaml; do
+	if grep -q "^  conversion:" "${crd}"; then
+		continue
+	fi
+	# Kinds that are served in a single version need no conversion
+	if [ "$(grep -c "^    served: true" "${crd}")" -lt 2 ]; then
+		continue
+	fi
+	sed -i \
+		-e '/^    controller-gen.kubebuilder.io\/version:/a\    service.beta.openshift.io/inject-cabundle: "true"' \
+		-e '/^spec:$/a\  conversion:\n    strategy: Webhook\n    webhook:\n      clientConfig:\n        service:\n          name: ocm-agent-operator-webhook\n          namespace: openshift-ocm-agent-operator\n          path: /convert\n      conversionReviewVersions:\n      - v1' \
+		"${crd}"
+done
@@ -28,8 +28,12 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/openshift/ocm-agent-operator/controllers/fleetnotification"
+	"github.com/openshift/ocm-agent-operator/controllers/managednotification"
+	operatorconfigcontroller "github.com/openshift/ocm-agent-operator/controllers/operatorconfig"
+	ctrlconst "github.com/openshift/ocm-agent-operator/pkg/consts/controller"
 	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
 	"github.com/openshift/ocm-agent-operator/pkg/ocmagenthandler"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
 	"github.com/openshift/ocm-agent-operator/pkg/util/namespace"
 	"github.com/openshift/ocm-agent-operator/pkg/version"
 
@@ -37,6 +41,8 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -55,6 +61,7 @@ import (
 	osdmetrics "github.com/openshift/operator-custom-metrics/pkg/metrics"
 
 	ocmagentmanagedopenshiftiov1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	ocmagentmanagedopenshiftiov1beta1 "github.com/openshift/ocm-agent-operator/api/v1beta1"
 	"github.com/openshift/ocm-agent-operator/controllers/ocmagent"
 	//+kubebuilder:scaffold:imports
 )
@@ -74,6 +81,7 @@ var log = logf.Log.WithName("cmd")
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(ocmagentmanagedopenshiftiov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(ocmagentmanagedopenshiftiov1beta1.AddToScheme(scheme))
 	utilruntime.Must(oconfigv1.Install(scheme))
 	utilruntime.Must(monitorv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
@@ -90,11 +98,14 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var reconcileTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&reconcileTimeout, "reconcile-timeout", ctrlconst.ReconcileTimeoutDefault,
+		"The maximum duration of a reconcile of an OcmAgent, 0 disables the timeout.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -136,13 +147,21 @@ func main() {
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Namespace:              operatorNS,
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "8716512f.managed.openshift.io",
+		// The cache is scoped to the operator namespace and to the resources managed for an OcmAgent
+		Cache: ocmagent.NewCacheOptions(operatorNS),
+		// The cache is scoped to the operator namespace, so the OCM Agent Deployments
+		// that may be deployed to other target namespaces are read from the API server
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor: []client.Object{&appsv1.Deployment{}},
+			},
+		},
 	})
 
 	if err != nil {
@@ -150,28 +169,86 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create a separate client for the OAH Builder
-	kubeConfig := ctrl.GetConfigOrDie()
-	handlerClient, err := client.New(kubeConfig, client.Options{Scheme: mgr.GetScheme()})
+	// The operator configuration is read at startup, as the reconcile concurrency of the
+	// controllers can't change while they run, and is then kept up to date by its controller
+	startupConfig, err := operatorconfig.Load(context.TODO(), mgr.GetAPIReader())
 	if err != nil {
+		setupLog.Error(err, "unable to read the operator configuration")
 		os.Exit(1)
 	}
+	configStore := operatorconfig.NewStore(startupConfig)
 
+	// The OAH Builder's client reads from the manager's cache and the cluster resource cache
+	clusterCache, err := ocmagent.NewClusterResourceCache(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create cluster resource cache")
+		os.Exit(1)
+	}
+	handlerClient, err := ocmagent.NewHandlerClient(mgr, clusterCache, operatorNS)
+	if err != nil {
+		setupLog.Error(err, "unable to create OCM Agent handler client")
+		os.Exit(1)
+	}
+
+	recorder := mgr.GetEventRecorderFor("ocm-agent-operator")
 	if err = (&ocmagent.OcmAgentReconciler{
 		Client:                 mgr.GetClient(),
 		Scheme:                 mgr.GetScheme(),
-		OCMAgentHandlerBuilder: ocmagenthandler.NewBuilder(handlerClient),
+		OCMAgentHandlerBuilder: ocmagenthandler.NewBuilder(handlerClient, mgr.GetAPIReader(), recorder, configStore),
+		ReconcileTimeout:       reconcileTimeout,
+		ClusterCache:           clusterCache,
+		Config:                 configStore,
+		Recorder:               recorder,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OcmAgent")
 		os.Exit(1)
 	}
 	if err = (&fleetnotification.ManagedFleetNotificationReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Config:   configStore,
+		Recorder: recorder,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ManagedFleetNotification")
+		os.Exit(1)
+	}
+	if err = (&managednotification.ManagedNotificationReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Config: configStore,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "ManagedFleetNotification")
+		setupLog.Error(err, "unable to create controller", "controller", "ManagedNotification")
 		os.Exit(1)
 	}
+	if err = (&operatorconfigcontroller.OcmAgentOperatorConfigReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Config:  configStore,
+		Started: startupConfig,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OcmAgentOperatorConfig")
+		os.Exit(1)
+	}
+	// Webhooks are served with the certificate injected by the service CA, they can be
+	// disabled with ENABLE_WEBHOOKS=false when running the operator locally.
+	// Registering the v1alpha1 hub types also serves the v1beta1 conversion webhook.
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		targetNamespaceRules := func(ocmAgent ocmagentmanagedopenshiftiov1alpha1.OcmAgent) ([]rbacv1.PolicyRule, error) {
+			return ocmagenthandler.TargetNamespaceRules(mgr.GetRESTMapper(), ocmAgent)
+		}
+		if err = (&ocmagentmanagedopenshiftiov1alpha1.OcmAgent{}).SetupWebhookWithManager(mgr, operatorNS, targetNamespaceRules); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OcmAgent")
+			os.Exit(1)
+		}
+		if err = (&ocmagentmanagedopenshiftiov1alpha1.ManagedNotification{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ManagedNotification")
+			os.Exit(1)
+		}
+		if err = (&ocmagentmanagedopenshiftiov1alpha1.ManagedFleetNotification{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ManagedFleetNotification")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
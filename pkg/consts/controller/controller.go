@@ -8,6 +8,9 @@ const (
 	// SyncPeriodDefault reconciles a sync period for each controller
 	SyncPeriodDefault = 5 * time.Minute
 
+	// ReconcileTimeoutDefault bounds the duration of a single reconcile of an OcmAgent
+	ReconcileTimeoutDefault = 2 * time.Minute
+
 	// ReconcileOCMAgentFinalizer defines the finalizer to apply to the OCM Agent resource
 	ReconcileOCMAgentFinalizer = "ocmagent.managed.openshift.io"
 )
\ No newline at end of file
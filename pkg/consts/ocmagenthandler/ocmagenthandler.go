@@ -5,6 +5,8 @@ import (
 	"net/url"
 
 	ns "github.com/openshift/ocm-agent-operator/pkg/util/namespace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -25,8 +27,13 @@ const (
 	OCMAgentLivezPath = "/livez"
 	// OCMAgentReadyzPath is the readyness probe path
 	OCMAgentReadyzPath = "/readyz"
-	// OCMAgentServiceAccount is the name of the service account that will run the OCM Agent
-	OCMAgentServiceAccount = "ocm-agent"
+	// OCMAgentProbeTimeoutSeconds, OCMAgentProbePeriodSeconds, OCMAgentProbeSuccessThreshold and
+	// OCMAgentProbeFailureThreshold are the OCM Agent probe settings, set explicitly to the Kubernetes
+	// defaults so that the API server defaulting them is not mistaken for drift
+	OCMAgentProbeTimeoutSeconds   = 1
+	OCMAgentProbePeriodSeconds    = 10
+	OCMAgentProbeSuccessThreshold = 1
+	OCMAgentProbeFailureThreshold = 3
 	// OCMAgentCommand is the name of the OCM Agent binary to run in the deployment
 	OCMAgentCommand = "ocm-agent"
 
@@ -63,16 +70,37 @@ const (
 	PullSecretAuthTokenKey = "cloud.openshift.com"
 	// InjectCaBundleIndicator defines the name of the key for the label of trusted CA bundle configmap
 	InjectCaBundleIndicator = "config.openshift.io/inject-trusted-cabundle"
-	// TrustedCaBundleConfigMapName TrustedCaBundleConfigMap defines the name of trusted CA bundle configmap
+	// TrustedCaBundleConfigMapName defines the name of the trusted CA bundle volume of the OCM Agent pods,
+	// and the suffix of the name of the trusted CA bundle configmap of an OcmAgent
 	TrustedCaBundleConfigMapName = "trusted-ca-bundle"
+	// OCMAgentPDBMaxUnavailable is the number of OCM Agent pods that may be disrupted at once when running more than one replica
+	OCMAgentPDBMaxUnavailable = 1
+	// OCMAgentHPADefaultMinReplicas is the minimum OCM Agent replica count used when autoscaling does not set one
+	OCMAgentHPADefaultMinReplicas = 1
+	// OCMAgentHPADefaultCPUUtilization is the target CPU utilization used when autoscaling does not set any target
+	OCMAgentHPADefaultCPUUtilization = 80
+	// InfraNodeLabel is the label of infra nodes that the OCM Agent prefers and tolerates by default
+	InfraNodeLabel = "node-role.kubernetes.io/infra"
 	// ResourceLimitsCPU and ResourceLimitsMemory defines the cpu and memory limits for OA deployment
 	ResourceLimitsCPU    = "50m"
 	ResourceLimitsMemory = "64Mi"
 	// ResourceRequestsCPU and ResourceRequestsMemory defines the cpu and memory requests for OA deployment
 	ResourceRequestsCPU    = "1m"
 	ResourceRequestsMemory = "30Mi"
+	// OCMAgentConfigChecksumAnnotation is the OCM Agent pod template annotation holding the checksum of the
+	// mounted token secret and configmaps, so that a change of their content rolls out the OCM Agent pods
+	OCMAgentConfigChecksumAnnotation = "ocmagent.managed.openshift.io/config-checksum"
+	// OCMAgentInstanceLabel is the label set on every resource managed for an OcmAgent, holding the
+	// name of the OcmAgent, so that resources it no longer needs can be found and removed
+	OCMAgentInstanceLabel = "ocmagent.managed.openshift.io/instance"
 	// ConfigMapSuffix is the suffix added to configmap name to always make it unique compared to secret name
 	ConfigMapSuffix = "-cm"
+	// PrometheusRuleSuffix is the suffix added to the name of the PrometheusRule holding the alerts of an OcmAgent
+	PrometheusRuleSuffix = "-alerts"
+	// OCMAgentOperatorFieldManager is the field manager used by the operator when applying managed resources.
+	// It matches the field manager the API server derived for the operator's previous create/update calls,
+	// so that the fields written by earlier operator versions can be handed over to server-side apply.
+	OCMAgentOperatorFieldManager = "ocm-agent-operator"
 )
 
 var (
@@ -92,6 +120,12 @@ var (
 		Namespace: "",
 		Name:      "cluster",
 	}
+
+	// ClusterVersionNamespacedName defines the namespaced name of the cluster version
+	ClusterVersionNamespacedName = types.NamespacedName{
+		Namespace: "",
+		Name:      "version",
+	}
 )
 
 // BuildNamespacedName returns the name and namespace intended for OCM Agent deployment resources
@@ -104,6 +138,29 @@ func BuildNamespacedName(name string) types.NamespacedName {
 	return namespacedName
 }
 
+// BuildTargetNamespacedName returns the name and namespace intended for OCM Agent deployment resources
+// deployed to the target namespace of an OcmAgent, or to the operator namespace if it has none
+func BuildTargetNamespacedName(targetNamespace, name string) types.NamespacedName {
+	if targetNamespace == "" {
+		return BuildNamespacedName(name)
+	}
+	return types.NamespacedName{Name: name, Namespace: targetNamespace}
+}
+
+// BuildDefaultResources returns the operator's built-in requests and limits of the OCM Agent container
+func BuildDefaultResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(ResourceLimitsCPU),
+			corev1.ResourceMemory: resource.MustParse(ResourceLimitsMemory),
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(ResourceRequestsCPU),
+			corev1.ResourceMemory: resource.MustParse(ResourceRequestsMemory),
+		},
+	}
+}
+
 func BuildServiceURL(ocmAgentSvcName, ocmAgentNamespace string) (string, error) {
 	u := fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d%s", OCMAgentServiceScheme,
 		ocmAgentSvcName,
@@ -0,0 +1,112 @@
+package localmetrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const managementClusterLabel = "management_cluster"
+
+// FleetNotificationState is the state of a notification of a ManagedFleetNotificationRecord,
+// aggregated over the hosted clusters it was sent for
+type FleetNotificationState struct {
+	Name string
+	// ActiveRecords is the number of hosted clusters with a record of the notification
+	ActiveRecords int
+	// ServiceLogsSent is the number of service logs sent for the notification, over the
+	// hosted clusters with a record of it
+	ServiceLogsSent int
+}
+
+// fleetRecordState is the state of a ManagedFleetNotificationRecord
+type fleetRecordState struct {
+	managementCluster string
+	hostedClusters    int
+	notifications     []FleetNotificationState
+}
+
+// FleetNotificationCollector reports the state of the ManagedFleetNotificationRecords.
+// The hosted cluster IDs are left out of the labels, so that the number of series is
+// bounded by the number of management clusters and notifications.
+type FleetNotificationCollector struct {
+	hostedClusters  *prometheus.Desc
+	activeRecords   *prometheus.Desc
+	serviceLogsSent *prometheus.Desc
+
+	mu      sync.Mutex
+	records map[string]fleetRecordState
+}
+
+func newFleetNotificationCollector() *FleetNotificationCollector {
+	labels := []string{managementClusterLabel, notificationNameLabel}
+	return &FleetNotificationCollector{
+		hostedClusters: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "fleet_hosted_clusters"),
+			"Number of hosted clusters of a management cluster with a notification record",
+			[]string{managementClusterLabel}, nil),
+		activeRecords: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "fleet_notification_active_records"),
+			"Number of hosted clusters with a record of a fleet notification", labels, nil),
+		serviceLogsSent: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "fleet_notification_service_logs_sent"),
+			"Number of service logs sent for a fleet notification to the hosted clusters with a record of it", labels, nil),
+		records: map[string]fleetRecordState{},
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *FleetNotificationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hostedClusters
+	ch <- c.activeRecords
+	ch <- c.serviceLogsSent
+}
+
+// Collect implements prometheus.Collector. Several records may report the same management
+// cluster, so their states are summed per management cluster and notification, as series
+// with the same labels would fail the whole scrape.
+func (c *FleetNotificationCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hostedClusters := map[string]int{}
+	notifications := map[[2]string]FleetNotificationState{}
+	for _, r := range c.records {
+		hostedClusters[r.managementCluster] += r.hostedClusters
+		for _, n := range r.notifications {
+			key := [2]string{r.managementCluster, n.Name}
+			sum := notifications[key]
+			sum.ActiveRecords += n.ActiveRecords
+			sum.ServiceLogsSent += n.ServiceLogsSent
+			notifications[key] = sum
+		}
+	}
+	for managementCluster, count := range hostedClusters {
+		ch <- prometheus.MustNewConstMetric(c.hostedClusters, prometheus.GaugeValue, float64(count), managementCluster)
+	}
+	for key, n := range notifications {
+		ch <- prometheus.MustNewConstMetric(c.activeRecords, prometheus.GaugeValue, float64(n.ActiveRecords), key[0], key[1])
+		ch <- prometheus.MustNewConstMetric(c.serviceLogsSent, prometheus.GaugeValue, float64(n.ServiceLogsSent), key[0], key[1])
+	}
+}
+
+// UpdateMetricFleetNotifications replaces the state of the ManagedFleetNotificationRecord
+// of the management cluster
+func UpdateMetricFleetNotifications(record string, managementCluster string, hostedClusters int, states []FleetNotificationState) {
+	MetricFleetNotifications.mu.Lock()
+	defer MetricFleetNotifications.mu.Unlock()
+	MetricFleetNotifications.records[record] = fleetRecordState{
+		managementCluster: managementCluster,
+		hostedClusters:    hostedClusters,
+		notifications:     states,
+	}
+}
+
+// DeleteMetricFleetNotifications removes the ManagedFleetNotificationRecord from the metrics
+func DeleteMetricFleetNotifications(record string) {
+	MetricFleetNotifications.mu.Lock()
+	defer MetricFleetNotifications.mu.Unlock()
+	delete(MetricFleetNotifications.records, record)
+}
+
+func IncrementMetricFleetRecordsPruned(managementCluster string, notificationName string) {
+	MetricFleetRecordsPruned.With(prometheus.Labels{
+		managementClusterLabel: managementCluster,
+		notificationNameLabel:  notificationName}).Inc()
+}
@@ -0,0 +1,63 @@
+package localmetrics
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fleet Notification Collector", func() {
+	var collector *FleetNotificationCollector
+
+	BeforeEach(func() {
+		collector = newFleetNotificationCollector()
+	})
+
+	It("reports the state of each record", func() {
+		collector.records["ocm-agent-operator/mc-1"] = fleetRecordState{
+			managementCluster: "mc-1",
+			hostedClusters:    2,
+			notifications:     []FleetNotificationState{{Name: "AuditWebhookError", ActiveRecords: 2, ServiceLogsSent: 5}},
+		}
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP ocm_agent_operator_fleet_hosted_clusters Number of hosted clusters of a management cluster with a notification record
+# TYPE ocm_agent_operator_fleet_hosted_clusters gauge
+ocm_agent_operator_fleet_hosted_clusters{management_cluster="mc-1"} 2
+# HELP ocm_agent_operator_fleet_notification_active_records Number of hosted clusters with a record of a fleet notification
+# TYPE ocm_agent_operator_fleet_notification_active_records gauge
+ocm_agent_operator_fleet_notification_active_records{management_cluster="mc-1",notification_name="AuditWebhookError"} 2
+# HELP ocm_agent_operator_fleet_notification_service_logs_sent Number of service logs sent for a fleet notification to the hosted clusters with a record of it
+# TYPE ocm_agent_operator_fleet_notification_service_logs_sent gauge
+ocm_agent_operator_fleet_notification_service_logs_sent{management_cluster="mc-1",notification_name="AuditWebhookError"} 5
+`))).To(Succeed())
+	})
+
+	It("sums the records of the same management cluster", func() {
+		collector.records["ocm-agent-operator/mc-1"] = fleetRecordState{
+			managementCluster: "mc-1",
+			hostedClusters:    2,
+			notifications:     []FleetNotificationState{{Name: "AuditWebhookError", ActiveRecords: 2, ServiceLogsSent: 5}},
+		}
+		collector.records["other-namespace/mc-1"] = fleetRecordState{
+			managementCluster: "mc-1",
+			hostedClusters:    1,
+			notifications:     []FleetNotificationState{{Name: "AuditWebhookError", ActiveRecords: 1, ServiceLogsSent: 1}},
+		}
+		registry := prometheus.NewPedanticRegistry()
+		Expect(registry.Register(collector)).To(Succeed())
+		_, err := registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP ocm_agent_operator_fleet_hosted_clusters Number of hosted clusters of a management cluster with a notification record
+# TYPE ocm_agent_operator_fleet_hosted_clusters gauge
+ocm_agent_operator_fleet_hosted_clusters{management_cluster="mc-1"} 3
+# HELP ocm_agent_operator_fleet_notification_service_logs_sent Number of service logs sent for a fleet notification to the hosted clusters with a record of it
+# TYPE ocm_agent_operator_fleet_notification_service_logs_sent gauge
+ocm_agent_operator_fleet_notification_service_logs_sent{management_cluster="mc-1",notification_name="AuditWebhookError"} 6
+`), "ocm_agent_operator_fleet_hosted_clusters", "ocm_agent_operator_fleet_notification_service_logs_sent")).To(Succeed())
+	})
+})
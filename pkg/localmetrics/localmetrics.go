@@ -1,12 +1,27 @@
 package localmetrics
 
 import (
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
-	metricsTag = "ocm_agent_operator"
-	nameLabel  = "ocmagent_name"
+	metricsTag      = "ocm_agent_operator"
+	nameLabel       = "ocmagent_name"
+	kindLabel       = "kind"
+	fieldLabel      = "field"
+	actionLabel     = "action"
+	controllerLabel = "controller"
+)
+
+// The actions of an ensure operation on a managed resource
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionNoop   = "noop"
 )
 
 var (
@@ -22,12 +37,105 @@ var (
 		Help:      "No OCM Agent resource found",
 	}, []string{})
 
+	MetricDriftCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricsTag,
+		Name:      "drift_corrections_total",
+		Help:      "Number of managed resource fields restored after drifting from the expected configuration",
+	}, []string{nameLabel, kindLabel, fieldLabel})
+
+	MetricReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metricsTag,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciles of a controller",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{controllerLabel})
+
+	MetricEnsureOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricsTag,
+		Name:      "ensure_operations_total",
+		Help:      "Number of operations made to ensure the state of the managed resources, by kind and action",
+	}, []string{nameLabel, kindLabel, actionLabel})
+
+	MetricHandlerErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricsTag,
+		Name:      "handler_errors_total",
+		Help:      "Number of failed operations on the managed resources, by kind",
+	}, []string{nameLabel, kindLabel})
+
+	MetricLastSuccessfulReconcileAge = newReconcileAgeCollector(prometheus.NewDesc(
+		prometheus.BuildFQName("", metricsTag, "last_successful_reconcile_age_seconds"),
+		"Time since the last successful reconcile of a controller",
+		[]string{controllerLabel}, nil,
+	))
+
+	MetricNotifications = newNotificationCollector()
+
+	MetricNotificationTimeToResolve = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metricsTag,
+		Name:      "notification_time_to_resolve_seconds",
+		Help:      "Time the alert of a notification fired for before resolving",
+		Buckets:   []float64{60, 300, 900, 1800, 3600, 7200, 21600, 43200, 86400, 259200, 604800},
+	}, []string{managedNotificationLabel, notificationNameLabel, severityLabel})
+
+	MetricFleetNotifications = newFleetNotificationCollector()
+
+	MetricFleetRecordsPruned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricsTag,
+		Name:      "fleet_records_pruned_total",
+		Help:      "Number of stale fleet notification records removed",
+	}, []string{managementClusterLabel, notificationNameLabel})
+
 	MetricsList = []prometheus.Collector{
 		MetricPullSecretInvalid,
 		MetricOcmAgentResourceAbsent,
+		MetricDriftCorrections,
+		MetricReconcileDuration,
+		MetricEnsureOperations,
+		MetricHandlerErrors,
+		MetricLastSuccessfulReconcileAge,
+		MetricNotifications,
+		MetricNotificationTimeToResolve,
+		MetricFleetNotifications,
+		MetricFleetRecordsPruned,
 	}
 )
 
+// ReconcileAgeCollector reports the time since the last successful reconcile of each
+// controller, which is computed when the metric is collected
+type ReconcileAgeCollector struct {
+	desc *prometheus.Desc
+	now  func() time.Time
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newReconcileAgeCollector(desc *prometheus.Desc) *ReconcileAgeCollector {
+	return &ReconcileAgeCollector{desc: desc, now: time.Now, last: map[string]time.Time{}}
+}
+
+// Describe implements prometheus.Collector
+func (c *ReconcileAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *ReconcileAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for controllerName, last := range c.last {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(last).Seconds(), controllerName)
+	}
+}
+
+// set records the time of the last successful reconcile of the controller
+func (c *ReconcileAgeCollector) set(controllerName string, last time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last[controllerName] = last
+}
+
 func UpdateMetricPullSecretInvalid(ocmAgentName string) {
 	MetricPullSecretInvalid.With(prometheus.Labels{
 		nameLabel: ocmAgentName}).Set(float64(1))
@@ -46,3 +154,32 @@ func ResetMetricPullSecretInvalid(ocmAgentName string) {
 func ResetMetricOcmAgentResourceAbsent() {
 	MetricOcmAgentResourceAbsent.WithLabelValues().Set(float64(0))
 }
+
+func IncrementMetricDriftCorrections(ocmAgentName string, kind string, field string) {
+	MetricDriftCorrections.With(prometheus.Labels{
+		nameLabel:  ocmAgentName,
+		kindLabel:  kind,
+		fieldLabel: field}).Inc()
+}
+
+func ObserveMetricReconcileDuration(controllerName string, duration time.Duration) {
+	MetricReconcileDuration.With(prometheus.Labels{
+		controllerLabel: controllerName}).Observe(duration.Seconds())
+}
+
+func UpdateMetricLastSuccessfulReconcile(controllerName string) {
+	MetricLastSuccessfulReconcileAge.set(controllerName, MetricLastSuccessfulReconcileAge.now())
+}
+
+func IncrementMetricEnsureOperations(ocmAgentName string, kind string, action string) {
+	MetricEnsureOperations.With(prometheus.Labels{
+		nameLabel:   ocmAgentName,
+		kindLabel:   kind,
+		actionLabel: action}).Inc()
+}
+
+func IncrementMetricHandlerErrors(ocmAgentName string, kind string) {
+	MetricHandlerErrors.With(prometheus.Labels{
+		nameLabel: ocmAgentName,
+		kindLabel: kind}).Inc()
+}
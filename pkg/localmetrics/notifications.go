@@ -0,0 +1,129 @@
+package localmetrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	managedNotificationLabel = "managednotification"
+	notificationNameLabel    = "notification_name"
+	severityLabel            = "severity"
+)
+
+// NotificationState is the state of a notification of a ManagedNotification, as
+// reported in the notification metrics
+type NotificationState struct {
+	Name                string
+	Severity            string
+	ResendWait          time.Duration
+	ServiceLogSentCount int32
+	Firing              bool
+	// LastSent is the time the last service log was sent for the notification,
+	// which is zero if none was sent
+	LastSent time.Time
+	// Flaps is the number of times the alert of the notification fired again shortly
+	// after resolving, over the flapping count window
+	Flaps int
+}
+
+// NotificationCollector reports the state of the notifications of the ManagedNotifications.
+// Several ManagedNotifications may define a notification of the same name, so the series are
+// labelled with the namespaced name of their ManagedNotification.
+// The time since the last service log and the resend suppression are computed when the
+// metrics are collected, as they change without the ManagedNotification changing.
+type NotificationCollector struct {
+	sent       *prometheus.Desc
+	firing     *prometheus.Desc
+	lastSent   *prometheus.Desc
+	suppressed *prometheus.Desc
+	flaps      *prometheus.Desc
+	now        func() time.Time
+
+	mu     sync.Mutex
+	states map[string][]NotificationState
+}
+
+func newNotificationCollector() *NotificationCollector {
+	labels := []string{managedNotificationLabel, notificationNameLabel, severityLabel}
+	return &NotificationCollector{
+		sent: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "notification_service_logs_sent_total"),
+			"Number of service logs sent for a notification", labels, nil),
+		firing: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "notification_alert_firing"),
+			"Whether the alert of a notification is firing", labels, nil),
+		lastSent: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "notification_last_sent_age_seconds"),
+			"Time since the last service log was sent for a notification", labels, nil),
+		suppressed: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "notification_resend_suppressed"),
+			"Whether a service log for a notification would be suppressed by its resend wait", labels, nil),
+		flaps: prometheus.NewDesc(prometheus.BuildFQName("", metricsTag, "notification_flaps"),
+			"Number of times the alert of a notification fired again shortly after resolving, over the flapping count window", labels, nil),
+		now:    time.Now,
+		states: map[string][]NotificationState{},
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *NotificationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.sent
+	ch <- c.firing
+	ch <- c.lastSent
+	ch <- c.suppressed
+	ch <- c.flaps
+}
+
+// Collect implements prometheus.Collector
+func (c *NotificationCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for managedNotification, states := range c.states {
+		// A repeated notification name would be collected as a duplicate series, which fails
+		// the whole scrape, so only the first notification of a name is reported
+		seen := map[string]bool{}
+		for _, s := range states {
+			if seen[s.Name] {
+				continue
+			}
+			seen[s.Name] = true
+			ch <- prometheus.MustNewConstMetric(c.sent, prometheus.CounterValue, float64(s.ServiceLogSentCount), managedNotification, s.Name, s.Severity)
+			ch <- prometheus.MustNewConstMetric(c.firing, prometheus.GaugeValue, boolToFloat64(s.Firing), managedNotification, s.Name, s.Severity)
+			suppressed := false
+			if !s.LastSent.IsZero() {
+				ch <- prometheus.MustNewConstMetric(c.lastSent, prometheus.GaugeValue, now.Sub(s.LastSent).Seconds(), managedNotification, s.Name, s.Severity)
+				suppressed = now.Before(s.LastSent.Add(s.ResendWait))
+			}
+			ch <- prometheus.MustNewConstMetric(c.suppressed, prometheus.GaugeValue, boolToFloat64(suppressed), managedNotification, s.Name, s.Severity)
+			ch <- prometheus.MustNewConstMetric(c.flaps, prometheus.GaugeValue, float64(s.Flaps), managedNotification, s.Name, s.Severity)
+		}
+	}
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// UpdateMetricNotifications replaces the states of the notifications of the ManagedNotification
+func UpdateMetricNotifications(managedNotification string, states []NotificationState) {
+	MetricNotifications.mu.Lock()
+	defer MetricNotifications.mu.Unlock()
+	MetricNotifications.states[managedNotification] = states
+}
+
+func ObserveMetricNotificationTimeToResolve(managedNotification string, notificationName string, severity string, duration time.Duration) {
+	MetricNotificationTimeToResolve.With(prometheus.Labels{
+		managedNotificationLabel: managedNotification,
+		notificationNameLabel:    notificationName,
+		severityLabel:            severity}).Observe(duration.Seconds())
+}
+
+// DeleteMetricNotifications removes the notifications of the ManagedNotification from the metrics
+func DeleteMetricNotifications(managedNotification string) {
+	MetricNotifications.mu.Lock()
+	defer MetricNotifications.mu.Unlock()
+	delete(MetricNotifications.states, managedNotification)
+}
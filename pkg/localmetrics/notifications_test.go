@@ -0,0 +1,75 @@
+package localmetrics
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notification Collector", func() {
+	var (
+		collector *NotificationCollector
+		now       time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		collector = newNotificationCollector()
+		collector.now = func() time.Time { return now }
+	})
+
+	It("reports the state of each notification", func() {
+		collector.states["openshift-ocm-agent-operator/sre-managed-notifications"] = []NotificationState{
+			{Name: "test-notification", Severity: "Warning", ResendWait: 2 * time.Hour, ServiceLogSentCount: 3, Firing: true, LastSent: now.Add(-time.Hour), Flaps: 1},
+			{Name: "quiet-notification", Severity: "Info"},
+		}
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP ocm_agent_operator_notification_alert_firing Whether the alert of a notification is firing
+# TYPE ocm_agent_operator_notification_alert_firing gauge
+ocm_agent_operator_notification_alert_firing{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_alert_firing{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 1
+# HELP ocm_agent_operator_notification_flaps Number of times the alert of a notification fired again shortly after resolving, over the flapping count window
+# TYPE ocm_agent_operator_notification_flaps gauge
+ocm_agent_operator_notification_flaps{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_flaps{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 1
+# HELP ocm_agent_operator_notification_last_sent_age_seconds Time since the last service log was sent for a notification
+# TYPE ocm_agent_operator_notification_last_sent_age_seconds gauge
+ocm_agent_operator_notification_last_sent_age_seconds{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 3600
+# HELP ocm_agent_operator_notification_resend_suppressed Whether a service log for a notification would be suppressed by its resend wait
+# TYPE ocm_agent_operator_notification_resend_suppressed gauge
+ocm_agent_operator_notification_resend_suppressed{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_resend_suppressed{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 1
+# HELP ocm_agent_operator_notification_service_logs_sent_total Number of service logs sent for a notification
+# TYPE ocm_agent_operator_notification_service_logs_sent_total counter
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="quiet-notification",severity="Info"} 0
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 3
+`))).To(Succeed())
+	})
+
+	It("reports the first of the notifications that share a name", func() {
+		collector.states["openshift-ocm-agent-operator/sre-managed-notifications"] = []NotificationState{
+			{Name: "test-notification", Severity: "Warning", ServiceLogSentCount: 3},
+			{Name: "test-notification", Severity: "Warning", ServiceLogSentCount: 5},
+		}
+		registry := prometheus.NewPedanticRegistry()
+		Expect(registry.Register(collector)).To(Succeed())
+		_, err := registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP ocm_agent_operator_notification_service_logs_sent_total Number of service logs sent for a notification
+# TYPE ocm_agent_operator_notification_service_logs_sent_total counter
+ocm_agent_operator_notification_service_logs_sent_total{managednotification="openshift-ocm-agent-operator/sre-managed-notifications",notification_name="test-notification",severity="Warning"} 3
+`), "ocm_agent_operator_notification_service_logs_sent_total")).To(Succeed())
+	})
+
+	It("reports the notifications of each ManagedNotification separately", func() {
+		collector.states["openshift-ocm-agent-operator/sre-managed-notifications"] = []NotificationState{{Name: "test-notification", Severity: "Warning"}}
+		collector.states["openshift-ocm-agent-operator/cluster-notifications"] = []NotificationState{{Name: "test-notification", Severity: "Warning"}}
+		Expect(testutil.CollectAndCount(collector, "ocm_agent_operator_notification_alert_firing")).To(Equal(2))
+	})
+})
@@ -2,85 +2,203 @@ package ocmagenthandler
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/go-logr/logr"
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //go:generate mockgen -source $GOFILE -destination ../../pkg/util/test/generated/mocks/$GOPACKAGE/interfaces.go -package mocks
 
 type OcmAgentHandlerBuilder interface {
-	New() (OCMAgentHandler, error)
+	// New builds an OCMAgentHandler for the reconcile of the supplied context
+	New(ctx context.Context) (OCMAgentHandler, error)
 }
 
 type ocmAgentHandlerBuilder struct {
-	Client client.Client
+	Client      client.Client
+	APIReader   client.Reader
+	Recorder    record.EventRecorder
+	ConfigStore *operatorconfig.Store
 }
 
-func NewBuilder(c client.Client) OcmAgentHandlerBuilder {
-	return &ocmAgentHandlerBuilder{Client: c}
+// NewBuilder returns a builder of handlers that read from the cache of the supplied
+// client, and from the API server through apiReader while an OcmAgent is removed.
+// The handlers follow the operator configuration held by configStore.
+func NewBuilder(c client.Client, apiReader client.Reader, recorder record.EventRecorder, configStore *operatorconfig.Store) OcmAgentHandlerBuilder {
+	return &ocmAgentHandlerBuilder{Client: c, APIReader: apiReader, Recorder: recorder, ConfigStore: configStore}
 }
 
-func (oab *ocmAgentHandlerBuilder) New() (OCMAgentHandler, error) {
-	log := ctrl.Log.WithName("handler").WithName("OCMAgent")
-	ctx := context.Background()
+func (oab *ocmAgentHandlerBuilder) New(ctx context.Context) (OCMAgentHandler, error) {
 	oaohandler := &ocmAgentHandler{
-		Client: oab.Client,
-		Log:    log,
-		Ctx:    ctx,
-		Scheme: oab.Client.Scheme(),
+		Client:    oab.Client,
+		APIReader: oab.APIReader,
+		Scheme:    oab.Client.Scheme(),
+		Recorder:  oab.Recorder,
+		Config:    oab.ConfigStore,
 	}
+	oaohandler.setContext(ctx)
 	return oaohandler, nil
 }
 
 type OCMAgentHandler interface {
-	// EnsureOCMAgentResourcesExist ensures that an OCM Agent is deployed on the cluster.
-	EnsureOCMAgentResourcesExist(ocmagentv1alpha1.OcmAgent) error
+	// EnsureOCMAgentResourcesExist ensures that an OCM Agent is deployed on the cluster,
+	// and records the state of each managed resource in the OcmAgent status.
+	EnsureOCMAgentResourcesExist(context.Context, *ocmagentv1alpha1.OcmAgent) error
 	// EnsureOCMAgentResourcesAbsent ensures that all OCM Agent resources are removed on the cluster.
-	EnsureOCMAgentResourcesAbsent(ocmagentv1alpha1.OcmAgent) error
+	EnsureOCMAgentResourcesAbsent(context.Context, ocmagentv1alpha1.OcmAgent) error
 }
 
 type ensureResource func(agent ocmagentv1alpha1.OcmAgent) error
 
+// managedResource associates an ensureResource func with the kind and name
+// of the resource that it manages
+type managedResource struct {
+	kind   string
+	name   string
+	ensure ensureResource
+}
+
 type ocmAgentHandler struct {
-	Client client.Client
-	Log    logr.Logger
-	Ctx    context.Context
-	Scheme *runtime.Scheme
+	Client    client.Client
+	APIReader client.Reader
+	Log       logr.Logger
+	Ctx       context.Context
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	// Config holds the operator configuration, the default configuration if it is nil
+	Config *operatorconfig.Store
+
+	// liveReads is set while the resources of an OcmAgent are removed, as objects missing
+	// from a stale cache would otherwise be left behind
+	liveReads bool
+
+	// inventory collects the objects applied for the OcmAgent during a reconcile
+	inventory []ocmagentv1alpha1.ManagedObjectReference
+	// conflicts collects the singleton resources found to be managed for another OcmAgent during a reconcile
+	conflicts []string
 }
 
-func (o *ocmAgentHandler) EnsureOCMAgentResourcesExist(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+const (
+	// reasonSingletonResourceConflict is the reason of the ResourceConflict condition
+	// while a singleton resource is managed for another OcmAgent
+	reasonSingletonResourceConflict = "SingletonResourceConflict"
+	// reasonNoConflict is the reason of the ResourceConflict condition otherwise
+	reasonNoConflict = "AsExpected"
+)
+
+// setContext scopes the API calls and log lines of the handler to the supplied
+// reconcile context, so that they are cancelled along with the reconcile and
+// carry the values of its logger
+func (o *ocmAgentHandler) setContext(ctx context.Context) {
+	o.Ctx = ctx
+	o.Log = ctrl.LoggerFrom(ctx).WithName("handler").WithName("OCMAgent")
+}
+
+// reader returns the reader of the handler's API reads, which is the cache of its client
+// unless live reads are required
+func (o *ocmAgentHandler) reader() client.Reader {
+	if o.liveReads && o.APIReader != nil {
+		return o.APIReader
+	}
+	return o.Client
+}
+
+func (o *ocmAgentHandler) EnsureOCMAgentResourcesExist(ctx context.Context, ocmAgent *ocmagentv1alpha1.OcmAgent) error {
+	o.setContext(ctx)
+	o.liveReads = false
 
-	var ensureFuncs []ensureResource
 	var ensureSecretFunc ensureResource
 	if ocmAgent.Spec.FleetMode {
 		ensureSecretFunc = o.ensureFleetClientSecret
 	} else {
 		ensureSecretFunc = o.ensureAccessTokenSecret
 	}
-	ensureFuncs = []ensureResource{
-		o.ensureDeployment,
-		o.ensureAllConfigMaps,
-		ensureSecretFunc,
-		o.ensureService,
-		o.ensureNetworkPolicy,
-		o.ensureServiceMonitor,
+	resources := []managedResource{
+		{kind: "ConfigMap", name: ocmAgent.Name + oah.ConfigMapSuffix, ensure: o.ensureAllConfigMaps},
+		{kind: "Secret", name: ocmAgent.Spec.TokenSecret, ensure: ensureSecretFunc},
+		{kind: "ServiceAccount", name: ocmAgent.Name, ensure: o.ensureServiceAccount},
+		{kind: "Role", name: ocmAgent.Name, ensure: o.ensureRole},
+		{kind: "RoleBinding", name: ocmAgent.Name, ensure: o.ensureRoleBinding},
+		// The Deployment follows the resources mounted into it and the permissions of its
+		// pods, so that a change of their content is rolled out within the same reconcile
+		{kind: "Deployment", name: ocmAgent.Name, ensure: o.ensureDeployment},
+		{kind: "Service", name: ocmAgent.Name, ensure: o.ensureService},
+		{kind: "NetworkPolicy", name: buildNetworkPolicyName(*ocmAgent), ensure: o.ensureNetworkPolicy},
+		{kind: "ServiceMonitor", name: ocmAgent.Name + "-metrics", ensure: o.ensureServiceMonitor},
+		{kind: "PrometheusRule", name: ocmAgent.Name + oah.PrometheusRuleSuffix, ensure: o.ensurePrometheusRule},
+		{kind: "PodDisruptionBudget", name: ocmAgent.Name, ensure: o.ensurePodDisruptionBudget},
+		{kind: "HorizontalPodAutoscaler", name: ocmAgent.Name, ensure: o.ensureHorizontalPodAutoscaler},
 	}
-	for _, fn := range ensureFuncs {
-		err := fn(ocmAgent)
-		if err != nil {
-			return err
+
+	o.inventory = nil
+	o.conflicts = nil
+
+	// Remove the resources of the previous mode before ensuring those of the current one
+	var errs []error
+	if err := o.ensureModeTransition(ocmAgent); err != nil {
+		o.Log.Error(err, "failed to transition the OCM Agent mode")
+		errs = append(errs, fmt.Errorf("mode transition: %w", err))
+	}
+
+	// Run every ensure func so that a failing resource doesn't hide the state of the others
+	statuses := make([]ocmagentv1alpha1.ManagedResourceStatus, 0, len(resources))
+	for _, r := range resources {
+		status := ocmagentv1alpha1.ManagedResourceStatus{
+			Kind:         r.kind,
+			Name:         r.name,
+			State:        ocmagentv1alpha1.ManagedResourceReconciled,
+			LastSyncTime: metav1.Now(),
+		}
+		if err := r.ensure(*ocmAgent); err != nil {
+			o.Log.Error(err, "failed to ensure resource", "kind", r.kind, "name", r.name)
+			status.State = ocmagentv1alpha1.ManagedResourceFailed
+			status.LastError = err.Error()
+			errs = append(errs, fmt.Errorf("%s %s: %w", r.kind, r.name, err))
 		}
+		// The sync time only moves when the state of the resource changes, so that an
+		// unchanged resource doesn't cause a status write on every reconcile
+		if previous := managedResourceStatus(ocmAgent.Status.ManagedResources, r.kind, r.name); previous != nil &&
+			previous.State == status.State && previous.LastError == status.LastError {
+			status.LastSyncTime = previous.LastSyncTime
+		}
+		statuses = append(statuses, status)
 	}
+	ocmAgent.Status.ManagedResources = statuses
+	setResourceConflictCondition(ocmAgent, o.conflicts)
 
-	return nil
+	// The objects of a failed resource may be missing from the inventory,
+	// so orphans are only removed after every resource was reconciled
+	if len(errs) == 0 {
+		inventory := sortInventory(o.inventory)
+		if !o.Config.Get().Enabled(ocmagentv1alpha1.FeatureOrphanCleanup) {
+			// The orphans are kept in the inventory, to be removed once the feature is enabled
+			ocmAgent.Status.Inventory = sortInventory(append(inventory, ocmAgent.Status.Inventory...))
+		} else if err := o.ensureOrphansDeleted(*ocmAgent, inventory); err != nil {
+			o.Log.Error(err, "failed to remove orphaned resources")
+			errs = append(errs, fmt.Errorf("orphaned resources: %w", err))
+		} else {
+			ocmAgent.Status.Inventory = inventory
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
 }
 
-func (o *ocmAgentHandler) EnsureOCMAgentResourcesAbsent(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+func (o *ocmAgentHandler) EnsureOCMAgentResourcesAbsent(ctx context.Context, ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	o.setContext(ctx)
+	o.liveReads = true
 
 	ensureFuncs := []ensureResource{
 		o.ensureDeploymentDeleted,
@@ -88,18 +206,56 @@ func (o *ocmAgentHandler) EnsureOCMAgentResourcesAbsent(ocmAgent ocmagentv1alpha
 		o.ensureAllConfigMapsDeleted,
 		o.ensureNetworkPolicyDeleted,
 		o.ensureServiceMonitorDeleted,
+		o.ensurePrometheusRuleDeleted,
+		o.ensurePodDisruptionBudgetDeleted,
+		o.ensureHorizontalPodAutoscalerDeleted,
+		o.ensureRBACDeleted,
 	}
 
 	if !ocmAgent.Spec.FleetMode {
 		ensureFuncs = append(ensureFuncs, o.ensureAccessTokenSecretDeleted)
 	}
 
+	// Remove any other object that is labelled or inventoried as managed for the OcmAgent
+	ensureFuncs = append(ensureFuncs, func(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+		return o.ensureOrphansDeleted(ocmAgent, nil)
+	})
+
+	var errs []error
 	for _, fn := range ensureFuncs {
 		err := fn(ocmAgent)
 		if err != nil {
-			return err
+			errs = append(errs, err)
 		}
 	}
 
+	return utilerrors.NewAggregate(errs)
+}
+
+// managedResourceStatus returns the status of the managed resource of the given kind and name,
+// or nil if it has none
+func managedResourceStatus(statuses []ocmagentv1alpha1.ManagedResourceStatus, kind, name string) *ocmagentv1alpha1.ManagedResourceStatus {
+	for i := range statuses {
+		if statuses[i].Kind == kind && statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
 	return nil
 }
+
+// setResourceConflictCondition reports in the OcmAgent status whether any singleton
+// resource was found to be managed for another OcmAgent
+func setResourceConflictCondition(ocmAgent *ocmagentv1alpha1.OcmAgent, conflicts []string) {
+	condition := metav1.Condition{
+		Type:               ocmagentv1alpha1.ConditionResourceConflict,
+		Status:             metav1.ConditionFalse,
+		Reason:             reasonNoConflict,
+		ObservedGeneration: ocmAgent.Generation,
+	}
+	if len(conflicts) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonSingletonResourceConflict
+		condition.Message = strings.Join(conflicts, "; ")
+	}
+	meta.SetStatusCondition(&ocmAgent.Status.Conditions, condition)
+}
@@ -0,0 +1,298 @@
+package ocmagenthandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+)
+
+// driftFunc returns the paths of the fields of a resource on the cluster that have
+// drifted from the configuration that the OCM Agent Operator manages
+type driftFunc func(current client.Object) []string
+
+// applyResource ensures that the supplied resource exists on the cluster and that
+// the fields set on it match, using server-side apply with the operator's field manager.
+//
+// Only the fields set on the supplied resource are owned by the operator, so defaults
+// populated by the API server and fields set by other controllers are kept. If the
+// resource exists and a driftFunc is supplied, the resource is only applied when the
+// driftFunc reports drifted fields. Every create, update and failure to apply the resource
+// is recorded as an Event on the OcmAgent. If owned is true the
+// OcmAgent is set as the controller of the resource, unless the resource is deployed to
+// another namespace.
+//
+// Every resource is labelled with the OcmAgent it is managed for and recorded in the
+// inventory of the reconcile, so that resources that are no longer desired can be removed.
+func (o *ocmAgentHandler) applyResource(ocmAgent ocmagentv1alpha1.OcmAgent, resource client.Object, owned bool, detectDrift driftFunc) error {
+	gvk, err := apiutil.GVKForObject(resource, o.Scheme)
+	if err != nil {
+		return err
+	}
+	kind := strings.ToLower(gvk.Kind)
+	namespacedName := client.ObjectKeyFromObject(resource)
+
+	// The labels are copied, as builders may share the label map with selectors
+	instanceLabels := map[string]string{oah.OCMAgentInstanceLabel: ocmAgent.Name}
+	labels := map[string]string{}
+	for k, v := range resource.GetLabels() {
+		labels[k] = v
+	}
+	labels[oah.OCMAgentInstanceLabel] = ocmAgent.Name
+	resource.SetLabels(labels)
+	o.inventory = append(o.inventory, ocmagentv1alpha1.ManagedObjectReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  namespacedName.Namespace,
+		Name:       namespacedName.Name,
+	})
+
+	obj, err := o.Scheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	current, ok := obj.(client.Object)
+	if !ok {
+		return fmt.Errorf("%s is not a client object", gvk.String())
+	}
+
+	// Does the resource already exist?
+	o.Log.Info(fmt.Sprintf("ensuring %s exists", kind), "resource", namespacedName.String())
+	exists := true
+	if err := o.getAppliedResource(namespacedName, current); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			// Return unexpectedly
+			localmetrics.IncrementMetricHandlerErrors(ocmAgent.Name, gvk.Kind)
+			return err
+		}
+		exists = false
+	}
+
+	var drift []string
+	if exists {
+		// It does exist, check if it is what we expected
+		if detectDrift != nil {
+			// The instance label is managed on every resource. A resource without it was created
+			// before it was managed, so it is labelled as an update rather than reported as drift.
+			compared := current
+			relabel := fieldDrift{}
+			relabel.subset("metadata.labels", instanceLabels, current.GetLabels())
+			if len(relabel) > 0 {
+				compared = current.DeepCopyObject().(client.Object)
+				currentLabels := map[string]string{}
+				for k, v := range current.GetLabels() {
+					currentLabels[k] = v
+				}
+				for k, v := range instanceLabels {
+					currentLabels[k] = v
+				}
+				compared.SetLabels(currentLabels)
+			}
+			drift = detectDrift(compared)
+			if len(drift) == 0 && len(relabel) == 0 {
+				localmetrics.IncrementMetricEnsureOperations(ocmAgent.Name, gvk.Kind, localmetrics.ActionNoop)
+				return nil
+			}
+		}
+		// Hand over fields previously written with create/update to the apply field manager,
+		// so that fields the operator stops setting are removed rather than orphaned
+		if err := o.upgradeManagedFields(current); err != nil {
+			localmetrics.IncrementMetricHandlerErrors(ocmAgent.Name, gvk.Kind)
+			o.Recorder.Eventf(&ocmAgent, corev1.EventTypeWarning, reasonUpdateFailed, "Failed to update %s %s: %v", gvk.Kind, namespacedName.String(), err)
+			return err
+		}
+		// Take over the pod template fields set by others, so that the apply removes those the
+		// operator doesn't set rather than leaving them in place to be detected again
+		if len(drift) > 0 {
+			if err := o.adoptPodTemplateFields(current, gvk.GroupVersion().String()); err != nil {
+				localmetrics.IncrementMetricHandlerErrors(ocmAgent.Name, gvk.Kind)
+				o.Recorder.Eventf(&ocmAgent, corev1.EventTypeWarning, reasonUpdateFailed, "Failed to update %s %s: %v", gvk.Kind, namespacedName.String(), err)
+				return err
+			}
+		}
+	} else {
+		o.Log.Info(fmt.Sprintf("An OCMAgent %s does not exist; will be created.", kind), "resource", namespacedName.String())
+	}
+
+	// Owner references can't cross namespaces, so a resource deployed to a target namespace
+	// other than that of the OcmAgent is only removed through its finalizer
+	if owned && (ocmAgent.Spec.TargetNamespace == "" || ocmAgent.Spec.TargetNamespace == ocmAgent.Namespace) {
+		if err := controllerutil.SetControllerReference(&ocmAgent, resource, o.Scheme); err != nil {
+			return err
+		}
+	}
+	resource.GetObjectKind().SetGroupVersionKind(gvk)
+	resource.SetResourceVersion("")
+	resource.SetManagedFields(nil)
+
+	err = o.Client.Patch(o.Ctx, resource, client.Apply, client.FieldOwner(oah.OCMAgentOperatorFieldManager), client.ForceOwnership)
+	if err != nil {
+		localmetrics.IncrementMetricHandlerErrors(ocmAgent.Name, gvk.Kind)
+		if exists {
+			o.Recorder.Eventf(&ocmAgent, corev1.EventTypeWarning, reasonUpdateFailed, "Failed to update %s %s: %v", gvk.Kind, namespacedName.String(), err)
+		} else {
+			o.Recorder.Eventf(&ocmAgent, corev1.EventTypeWarning, reasonCreateFailed, "Failed to create %s %s: %v", gvk.Kind, namespacedName.String(), err)
+		}
+		return err
+	}
+	o.recordApplied(ocmAgent, gvk.Kind, namespacedName, exists, drift)
+	return nil
+}
+
+// getAppliedResource reads a resource that the handler applies. The cache only holds the
+// resources labelled with an OcmAgent, so a resource missing from the cache is read from the
+// API server, as it may exist without the label, such as when it was created by an earlier
+// version of the operator. The resource is then labelled when it is applied.
+func (o *ocmAgentHandler) getAppliedResource(key types.NamespacedName, obj client.Object) error {
+	err := o.reader().Get(o.Ctx, key, obj)
+	if k8serrors.IsNotFound(err) && !o.liveReads && o.APIReader != nil {
+		return o.APIReader.Get(o.Ctx, key, obj)
+	}
+	return err
+}
+
+// recordApplied records an Event on the OcmAgent and an ensure operation for a resource that was applied. A resource
+// updated while the OcmAgent spec is unchanged since the last reconcile had drifted from the
+// configuration that the operator manages, so its restored fields are also reported in the
+// logs and in the drift corrections metric.
+func (o *ocmAgentHandler) recordApplied(ocmAgent ocmagentv1alpha1.OcmAgent, kind string, namespacedName types.NamespacedName, exists bool, drift []string) {
+	if !exists {
+		localmetrics.IncrementMetricEnsureOperations(ocmAgent.Name, kind, localmetrics.ActionCreate)
+		o.Recorder.Eventf(&ocmAgent, corev1.EventTypeNormal, reasonCreated, "Created %s %s", kind, namespacedName.String())
+		return
+	}
+	localmetrics.IncrementMetricEnsureOperations(ocmAgent.Name, kind, localmetrics.ActionUpdate)
+	if ocmAgent.Generation != ocmAgent.Status.ObservedGeneration || len(drift) == 0 {
+		o.Recorder.Eventf(&ocmAgent, corev1.EventTypeNormal, reasonUpdated, "Updated %s %s", kind, namespacedName.String())
+		return
+	}
+	for _, field := range drift {
+		o.Log.Info(fmt.Sprintf("An OCMAgent %s contained unexpected configuration and was restored.", strings.ToLower(kind)), "resource", namespacedName.String(), "field", field)
+		localmetrics.IncrementMetricDriftCorrections(ocmAgent.Name, kind, field)
+	}
+	o.Recorder.Eventf(&ocmAgent, corev1.EventTypeWarning, reasonDriftCorrected, "Restored %s of %s %s", strings.Join(drift, ", "), kind, namespacedName.String())
+}
+
+// upgradeManagedFields migrates the ownership of fields set on the resource through
+// client-side create/update calls to the operator's server-side apply field manager
+func (o *ocmAgentHandler) upgradeManagedFields(current client.Object) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(current, sets.New(oah.OCMAgentOperatorFieldManager), oah.OCMAgentOperatorFieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+	return o.Client.Patch(o.Ctx, current, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// podTemplatePath is the path of the pod template of the resources that have one
+var podTemplatePath = fieldpath.MakePathOrDie("spec", "template")
+
+// adoptPodTemplateFields transfers the ownership of the pod template fields set by other field
+// managers to the operator's apply field manager, so that the next apply removes the fields the
+// operator doesn't set, such as an env var added with kubectl edit. Fields outside of the pod
+// template, such as those set by the Deployment controller, are left to their managers.
+func (o *ocmAgentHandler) adoptPodTemplateFields(current client.Object, apiVersion string) error {
+	entries, changed, err := adoptManagedFields(current.GetManagedFields(), podTemplatePath, oah.OCMAgentOperatorFieldManager, apiVersion)
+	if err != nil || !changed {
+		return err
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": current.GetResourceVersion()},
+		{"op": "replace", "path": "/metadata/managedFields", "value": entries},
+	})
+	if err != nil {
+		return err
+	}
+	return o.Client.Patch(o.Ctx, current, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// adoptManagedFields moves the fields under prefix that are owned by other managers to the apply
+// entry of the manager, returning the updated entries and whether any field was moved. Entries
+// of subresources, such as status, are left unchanged.
+func adoptManagedFields(entries []metav1.ManagedFieldsEntry, prefix fieldpath.Path, manager, apiVersion string) ([]metav1.ManagedFieldsEntry, bool, error) {
+	adopted := &fieldpath.Set{}
+	var result []metav1.ManagedFieldsEntry
+	applyIndex := -1
+	for _, entry := range entries {
+		if entry.Subresource != "" || entry.FieldsV1 == nil {
+			result = append(result, entry)
+			continue
+		}
+		if entry.Manager == manager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			applyIndex = len(result)
+			result = append(result, entry)
+			continue
+		}
+		owned := &fieldpath.Set{}
+		if err := owned.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil, false, err
+		}
+		under := fieldsUnder(owned, prefix)
+		if under.Empty() {
+			result = append(result, entry)
+			continue
+		}
+		adopted = adopted.Union(under)
+		if rest := owned.Difference(under); !rest.Empty() {
+			raw, err := rest.ToJSON()
+			if err != nil {
+				return nil, false, err
+			}
+			entry.FieldsV1 = &metav1.FieldsV1{Raw: raw}
+			result = append(result, entry)
+		}
+	}
+	if adopted.Empty() {
+		return entries, false, nil
+	}
+
+	if applyIndex < 0 {
+		result = append(result, metav1.ManagedFieldsEntry{
+			Manager:    manager,
+			Operation:  metav1.ManagedFieldsOperationApply,
+			APIVersion: apiVersion,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte("{}")},
+		})
+		applyIndex = len(result) - 1
+	}
+	owned := &fieldpath.Set{}
+	if err := owned.FromJSON(bytes.NewReader(result[applyIndex].FieldsV1.Raw)); err != nil {
+		return nil, false, err
+	}
+	raw, err := owned.Union(adopted).ToJSON()
+	if err != nil {
+		return nil, false, err
+	}
+	now := metav1.Now()
+	result[applyIndex].FieldsV1 = &metav1.FieldsV1{Raw: raw}
+	result[applyIndex].Time = &now
+	return result, true, nil
+}
+
+// fieldsUnder returns the fields of the set under the prefix, including their full path
+func fieldsUnder(set *fieldpath.Set, prefix fieldpath.Path) *fieldpath.Set {
+	sub := set
+	for _, pe := range prefix {
+		sub = sub.WithPrefix(pe)
+	}
+	under := &fieldpath.Set{}
+	sub.Iterate(func(p fieldpath.Path) {
+		under.Insert(append(prefix.Copy(), p...))
+	})
+	return under
+}
@@ -0,0 +1,292 @@
+package ocmagenthandler
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oahconst "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCM Agent Apply Engine", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testConfigMap       *corev1.ConfigMap
+		testNamespacedName  types.NamespacedName
+		testRecorder        *record.FakeRecorder
+		dataDrifted         driftFunc
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = testconst.TestOCMAgent
+		testRecorder = record.NewFakeRecorder(10)
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: testRecorder,
+		}
+		testConfigMap = buildOCMAgentConfigMap(testOcmAgent, "")
+		testNamespacedName = client.ObjectKeyFromObject(testConfigMap)
+		dataDrifted = func(current client.Object) []string { return []string{"data"} }
+	})
+
+	When("the resource does not exist", func() {
+		It("applies it with the operator field manager", func() {
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, testConfigMap.Name)
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+					func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
+						patchOpts := &client.PatchOptions{}
+						patchOpts.ApplyOptions(opts)
+						Expect(patchOpts.FieldManager).To(Equal(oahconst.OCMAgentOperatorFieldManager))
+						Expect(*patchOpts.Force).To(BeTrue())
+						Expect(d.APIVersion).To(Equal("v1"))
+						Expect(d.Kind).To(Equal("ConfigMap"))
+						Expect(d.OwnerReferences).To(HaveLen(1))
+						return nil
+					}),
+			)
+			created := localmetrics.MetricEnsureOperations.WithLabelValues(testOcmAgent.Name, "ConfigMap", localmetrics.ActionCreate)
+			before := testutil.ToFloat64(created)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+			Expect(err).To(BeNil())
+			Expect(testRecorder.Events).To(Receive(And(ContainSubstring("Normal Created"), ContainSubstring("ConfigMap "+testNamespacedName.String()))))
+			Expect(testutil.ToFloat64(created)).To(Equal(before + 1))
+		})
+		It("reports a failure to create it", func() {
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, testConfigMap.Name)
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).Return(k8serrs.NewForbidden(schema.GroupResource{}, testConfigMap.Name, nil)),
+			)
+			handlerErrors := localmetrics.MetricHandlerErrors.WithLabelValues(testOcmAgent.Name, "ConfigMap")
+			before := testutil.ToFloat64(handlerErrors)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+			Expect(err).NotTo(BeNil())
+			Expect(testRecorder.Events).To(Receive(And(ContainSubstring("Warning CreateFailed"), ContainSubstring("ConfigMap "+testNamespacedName.String()))))
+			Expect(testutil.ToFloat64(handlerErrors)).To(Equal(before + 1))
+		})
+		It("does not set a controller reference across namespaces", func() {
+			testOcmAgent.Namespace = oahconst.OCMAgentNamespace
+			testOcmAgent.Spec.TargetNamespace = "ocm-agent-staging"
+			testConfigMap = buildOCMAgentConfigMap(testOcmAgent, "")
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, testConfigMap.Name)
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), client.ObjectKeyFromObject(testConfigMap), gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+					func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
+						Expect(d.Namespace).To(Equal("ocm-agent-staging"))
+						Expect(d.OwnerReferences).To(BeEmpty())
+						Expect(d.Labels).To(HaveKeyWithValue(oahconst.OCMAgentInstanceLabel, testOcmAgent.Name))
+						return nil
+					}),
+			)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+			Expect(err).To(BeNil())
+			Expect(testOcmAgentHandler.inventory).To(ContainElement(ocmagentv1alpha1.ManagedObjectReference{
+				APIVersion: "v1", Kind: "ConfigMap", Namespace: "ocm-agent-staging", Name: testConfigMap.Name,
+			}))
+		})
+	})
+
+	When("the resource exists without the instance label", func() {
+		var mockAPIReader *clientmocks.MockClient
+		BeforeEach(func() {
+			mockAPIReader = clientmocks.NewMockClient(mockCtrl)
+			testOcmAgentHandler.APIReader = mockAPIReader
+		})
+		It("reads it from the API server and labels it", func() {
+			// The cache only holds labelled resources
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, testConfigMap.Name)
+			existing := testConfigMap.DeepCopy()
+			existing.ResourceVersion = "1"
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+				mockAPIReader.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+					func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
+						Expect(d.Labels).To(HaveKeyWithValue(oahconst.OCMAgentInstanceLabel, testOcmAgent.Name))
+						return nil
+					}),
+			)
+			created := localmetrics.MetricEnsureOperations.WithLabelValues(testOcmAgent.Name, "ConfigMap", localmetrics.ActionCreate)
+			updated := localmetrics.MetricEnsureOperations.WithLabelValues(testOcmAgent.Name, "ConfigMap", localmetrics.ActionUpdate)
+			drifted := localmetrics.MetricDriftCorrections.WithLabelValues(testOcmAgent.Name, "ConfigMap", "metadata.labels")
+			createdBefore, updatedBefore, driftedBefore := testutil.ToFloat64(created), testutil.ToFloat64(updated), testutil.ToFloat64(drifted)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, func(current client.Object) []string {
+				drift := fieldDrift{}
+				drift.subset("metadata.labels", testConfigMap.Labels, current.GetLabels())
+				return drift
+			})
+			Expect(err).To(BeNil())
+			// Labelling the resource is an update rather than drift
+			Expect(testRecorder.Events).To(Receive(Equal("Normal Updated Updated ConfigMap " + testNamespacedName.String())))
+			Expect(testutil.ToFloat64(created)).To(Equal(createdBefore))
+			Expect(testutil.ToFloat64(updated)).To(Equal(updatedBefore + 1))
+			Expect(testutil.ToFloat64(drifted)).To(Equal(driftedBefore))
+		})
+		It("creates it if it is not found on the API server either", func() {
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, testConfigMap.Name)
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+				mockAPIReader.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1),
+			)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+			Expect(err).To(BeNil())
+			Expect(testRecorder.Events).To(Receive(ContainSubstring("Normal Created")))
+		})
+	})
+
+	When("the resource exists", func() {
+		var existing *corev1.ConfigMap
+		BeforeEach(func() {
+			existing = testConfigMap.DeepCopy()
+			existing.ResourceVersion = "1"
+			withInstanceLabel(existing, testOcmAgent)
+		})
+		It("does not apply it if it has not changed", func() {
+			mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing)
+			noop := localmetrics.MetricEnsureOperations.WithLabelValues(testOcmAgent.Name, "ConfigMap", localmetrics.ActionNoop)
+			before := testutil.ToFloat64(noop)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, func(current client.Object) []string { return nil })
+			Expect(err).To(BeNil())
+			Expect(testRecorder.Events).To(BeEmpty())
+			Expect(testutil.ToFloat64(noop)).To(Equal(before + 1))
+		})
+		It("applies it without ownership if it is not managed", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+					func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
+						Expect(d.OwnerReferences).To(BeEmpty())
+						Expect(d.ResourceVersion).To(BeEmpty())
+						return nil
+					}),
+			)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, false, dataDrifted)
+			Expect(err).To(BeNil())
+		})
+		It("reports the drifted fields it restored", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1),
+			)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+			Expect(err).To(BeNil())
+			Expect(testRecorder.Events).To(Receive(And(ContainSubstring("DriftCorrected"), ContainSubstring("data"))))
+			Expect(testutil.ToFloat64(localmetrics.MetricDriftCorrections.WithLabelValues(testOcmAgent.Name, "ConfigMap", "data"))).To(BeNumerically(">=", 1))
+		})
+		It("names every drifted field in a single Event", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1),
+			)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, func(current client.Object) []string {
+				return []string{"data", "metadata.annotations"}
+			})
+			Expect(err).To(BeNil())
+			Expect(testRecorder.Events).To(Receive(Equal("Warning DriftCorrected Restored data, metadata.annotations of ConfigMap " + testNamespacedName.String())))
+			Expect(testRecorder.Events).To(BeEmpty())
+		})
+		It("reports an update rather than drift when the OcmAgent spec changed", func() {
+			testOcmAgent.Generation = 2
+			testOcmAgent.Status.ObservedGeneration = 1
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1),
+			)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+			Expect(err).To(BeNil())
+			Expect(testRecorder.Events).To(Receive(Equal("Normal Updated Updated ConfigMap " + testNamespacedName.String())))
+		})
+		It("reports a failure to update it", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing),
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).Return(k8serrs.NewConflict(schema.GroupResource{}, testConfigMap.Name, nil)),
+			)
+			err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+			Expect(err).NotTo(BeNil())
+			Expect(testRecorder.Events).To(Receive(ContainSubstring("Warning UpdateFailed")))
+		})
+		When("its fields were previously written with create/update", func() {
+			BeforeEach(func() {
+				existing.ManagedFields = []metav1.ManagedFieldsEntry{{
+					Manager:    oahconst.OCMAgentOperatorFieldManager,
+					Operation:  metav1.ManagedFieldsOperationUpdate,
+					APIVersion: "v1",
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:fake":{}}}`)},
+				}}
+			})
+			It("hands the fields over to the apply field manager first", func() {
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, *existing),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(patch.Type()).To(Equal(types.JSONPatchType))
+							return nil
+						}),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1),
+				)
+				err := testOcmAgentHandler.applyResource(testOcmAgent, testConfigMap, true, dataDrifted)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	When("taking over the pod template fields of other field managers", func() {
+		It("leaves the fields outside of the pod template and the subresources to their managers", func() {
+			entries := []metav1.ManagedFieldsEntry{
+				{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, FieldsType: "FieldsV1",
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:deployment.kubernetes.io/revision":{}}}}`)}},
+				{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status", FieldsType: "FieldsV1",
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:replicas":{}}}`)}},
+			}
+			adopted, changed, err := adoptManagedFields(entries, podTemplatePath, oahconst.OCMAgentOperatorFieldManager, "apps/v1")
+			Expect(err).To(BeNil())
+			Expect(changed).To(BeFalse())
+			Expect(adopted).To(Equal(entries))
+		})
+		It("adds an apply entry for the operator if it has none", func() {
+			entries := []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate, FieldsType: "FieldsV1",
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:nodeSelector":{}}}}}`)}},
+			}
+			adopted, changed, err := adoptManagedFields(entries, podTemplatePath, oahconst.OCMAgentOperatorFieldManager, "apps/v1")
+			Expect(err).To(BeNil())
+			Expect(changed).To(BeTrue())
+			Expect(adopted).To(HaveLen(1))
+			Expect(adopted[0].Manager).To(Equal(oahconst.OCMAgentOperatorFieldManager))
+			Expect(adopted[0].Operation).To(Equal(metav1.ManagedFieldsOperationApply))
+			Expect(adopted[0].APIVersion).To(Equal("apps/v1"))
+			Expect(string(adopted[0].FieldsV1.Raw)).To(Equal(`{"f:spec":{"f:template":{"f:spec":{"f:nodeSelector":{}}}}}`))
+		})
+	})
+})
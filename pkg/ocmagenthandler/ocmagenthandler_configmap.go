@@ -1,16 +1,14 @@
 package ocmagenthandler
 
 import (
-	"context"
 	"fmt"
-	"reflect"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	configv1 "github.com/openshift/api/config/v1"
 
@@ -22,7 +20,7 @@ import (
 func buildOCMAgentConfigMap(ocmAgent ocmagentv1alpha1.OcmAgent, clusterId string) *corev1.ConfigMap {
 
 	// We are ensuring to keep the configmap name always unique from secret name so adding a suffix
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name + ocmagenthandler.ConfigMapSuffix)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name+ocmagenthandler.ConfigMapSuffix)
 
 	CMData := map[string]string{
 		oah.OCMAgentConfigServicesKey: strings.Join(ocmAgent.Spec.AgentConfig.Services, ","),
@@ -44,8 +42,14 @@ func buildOCMAgentConfigMap(ocmAgent ocmagentv1alpha1.OcmAgent, clusterId string
 	return cm
 }
 
-func buildTrustedCaConfigMap() *corev1.ConfigMap {
-	namespacedName := oah.BuildNamespacedName(oah.TrustedCaBundleConfigMapName)
+// buildTrustedCaConfigMapName returns the name of the trusted CA bundle configmap of the OcmAgent,
+// which is unique to each OcmAgent so that several OCM Agents can be deployed to a namespace
+func buildTrustedCaConfigMapName(ocmAgent ocmagentv1alpha1.OcmAgent) string {
+	return ocmAgent.Name + "-" + oah.TrustedCaBundleConfigMapName
+}
+
+func buildTrustedCaConfigMap(ocmAgent ocmagentv1alpha1.OcmAgent) *corev1.ConfigMap {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, buildTrustedCaConfigMapName(ocmAgent))
 	labels := map[string]string{
 		oah.InjectCaBundleIndicator: "true",
 	}
@@ -60,7 +64,8 @@ func buildTrustedCaConfigMap() *corev1.ConfigMap {
 }
 
 func buildCAMOConfigMap(ocmAgent ocmagentv1alpha1.OcmAgent) (*corev1.ConfigMap, error) {
-	oaServiceURL, err := oah.BuildServiceURL(ocmAgent.Name, ocmAgent.Namespace)
+	serviceNamespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	oaServiceURL, err := oah.BuildServiceURL(serviceNamespacedName.Name, serviceNamespacedName.Namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -102,19 +107,14 @@ func (o *ocmAgentHandler) ensureAllConfigMaps(ocmAgent ocmagentv1alpha1.OcmAgent
 	}
 
 	if !ocmAgent.Spec.FleetMode {
-		// Ensure the CAMO ConfigMap
-		camoCM, err := buildCAMOConfigMap(ocmAgent)
-		if err != nil {
-			return err
-		}
-		err = o.ensureConfigMap(ocmAgent, camoCM, false)
+		err = o.ensureCAMOConfigMap(ocmAgent)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Ensure the trusted-ca-build ConfigMap
-	trustedCACM := buildTrustedCaConfigMap()
+	trustedCACM := buildTrustedCaConfigMap(ocmAgent)
 	err = o.ensureConfigMap(ocmAgent, trustedCACM, true)
 	if err != nil {
 		return err
@@ -123,80 +123,121 @@ func (o *ocmAgentHandler) ensureAllConfigMaps(ocmAgent ocmagentv1alpha1.OcmAgent
 	return nil
 }
 
-// ensureConfigMaps ensures that the OCM Agent Operator-managed configmap
-// exists on the cluster and that the configuration matches what is expected.
-// And apply the ownerReference to the configmaps if needed
-func (o *ocmAgentHandler) ensureConfigMap(ocmAgent ocmagentv1alpha1.OcmAgent, cm *corev1.ConfigMap, manager bool) error {
+// ensureCAMOConfigMap ensures that the CAMO configmap points alertmanager at the OCM Agent.
+// There is a single CAMO configmap on the cluster, so while it is managed for another OcmAgent
+// it is left untouched and the conflict is recorded, to be reported in the OcmAgent status.
+func (o *ocmAgentHandler) ensureCAMOConfigMap(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	current := &corev1.ConfigMap{}
+	if err := o.reader().Get(o.Ctx, oah.CAMOConfigMapNamespacedName, current); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+	} else {
+		owner, err := o.fetchCAMOConfigMapOwner(ocmAgent, current)
+		if err != nil {
+			return err
+		}
+		if owner != "" {
+			conflict := fmt.Sprintf("ConfigMap %s is managed for OcmAgent %s", oah.CAMOConfigMapNamespacedName.String(), owner)
+			o.Log.Info("CAMO configmap is managed for another OcmAgent, skipping", "resource", oah.CAMOConfigMapNamespacedName.String(), "owner", owner)
+			o.Recorder.Event(&ocmAgent, corev1.EventTypeWarning, reasonSingletonResourceConflict, conflict)
+			o.conflicts = append(o.conflicts, conflict)
+			return nil
+		}
+	}
 
-	foundResource := &corev1.ConfigMap{}
-	namespacedName := types.NamespacedName{
-		Namespace: cm.Namespace,
-		Name:      cm.Name,
+	camoCM, err := buildCAMOConfigMap(ocmAgent)
+	if err != nil {
+		return err
 	}
+	return o.ensureConfigMap(ocmAgent, camoCM, false)
+}
 
-	// Does the resource already exist?
-	o.Log.Info("ensuring configmap exists", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
-		if k8serrors.IsNotFound(err) {
-			// It does not exist, so must be created.
-			o.Log.Info(fmt.Sprintf("configmap does not exist, %s/%s; will be created.",
-				cm.Namespace,
-				cm.Name))
-			// Set the controller reference if needed
-			if manager {
-				if err := controllerutil.SetControllerReference(&ocmAgent, cm, o.Scheme); err != nil {
-					return err
-				}
-			}
-			// and create it
-			err = o.Client.Create(o.Ctx, cm)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Return unexpectedly
+// ensureCAMOConfigMapDeleted removes the CAMO configmap that points alertmanager at the OCM Agent,
+// unless it is managed for another OcmAgent
+func (o *ocmAgentHandler) ensureCAMOConfigMapDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	foundResource := &corev1.ConfigMap{}
+	o.Log.Info("ensuring configmap removed", "resource", oah.CAMOConfigMapNamespacedName.String())
+	if err := o.reader().Get(o.Ctx, oah.CAMOConfigMapNamespacedName, foundResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
 			return err
 		}
-	} else {
-		// skip update the configmap for trusted-ca-bundle to avoid the race with CNO
-		if cm.Name != oah.TrustedCaBundleConfigMapName {
-			// It does exist, check if it is what we expected
-			if !reflect.DeepEqual(foundResource.Data, cm.Data) {
-				// Specs aren't equal, update and fix.
-				o.Log.Info(fmt.Sprintf("configmap exists but contains unexpected configuration, %s/%s. Restoring.",
-					cm.Namespace, cm.Name))
-				foundResource = cm.DeepCopy()
-				if err = o.Client.Update(context.TODO(), foundResource); err != nil {
-					return err
-				}
-			}
+		return nil
+	}
+	owner, err := o.fetchCAMOConfigMapOwner(ocmAgent, foundResource)
+	if err != nil {
+		return err
+	}
+	if owner != "" {
+		o.Log.Info("CAMO configmap is managed for another OcmAgent, not removing it", "resource", oah.CAMOConfigMapNamespacedName.String(), "owner", owner)
+		return nil
+	}
+	return o.deleteResource(ocmAgent, foundResource)
+}
+
+// fetchCAMOConfigMapOwner returns the name of the other OcmAgent that the CAMO configmap is
+// managed for, or an empty string if it is not managed for another OcmAgent that still exists.
+// A CAMO configmap without the instance label was created by an earlier operator version, and
+// is taken over by the OcmAgent.
+func (o *ocmAgentHandler) fetchCAMOConfigMapOwner(ocmAgent ocmagentv1alpha1.OcmAgent, camoCM *corev1.ConfigMap) (string, error) {
+	owner := camoCM.Labels[oah.OCMAgentInstanceLabel]
+	if owner == "" || owner == ocmAgent.Name {
+		return "", nil
+	}
+	other := &ocmagentv1alpha1.OcmAgent{}
+	if err := o.reader().Get(o.Ctx, types.NamespacedName{Namespace: ocmAgent.Namespace, Name: owner}, other); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
 		}
+		return "", err
 	}
-	return nil
+	return owner, nil
+}
+
+// ensureConfigMap ensures that the OCM Agent Operator-managed configmap
+// exists on the cluster and that the configuration matches what is expected.
+// And apply the ownerReference to the configmaps if needed.
+// Only the fields set on the supplied configmap are applied, so the CA bundle
+// that the cluster network operator injects into the trusted-ca-bundle configmap is kept.
+func (o *ocmAgentHandler) ensureConfigMap(ocmAgent ocmagentv1alpha1.OcmAgent, cm *corev1.ConfigMap, manager bool) error {
+	return o.applyResource(ocmAgent, cm, manager, func(current client.Object) []string {
+		return configMapConfigChanged(current.(*corev1.ConfigMap), cm)
+	})
+}
+
+// configMapConfigChanged returns the paths of the fields of the current configmap
+// that differ from the labels and data set on the expected configmap
+func configMapConfigChanged(current, expected *corev1.ConfigMap) []string {
+	drift := fieldDrift{}
+	drift.subset("metadata.labels", expected.Labels, current.Labels)
+	if expected.Data != nil {
+		drift.equal("data", expected.Data, current.Data)
+	}
+	return drift
 }
 
 func (o *ocmAgentHandler) ensureAllConfigMapsDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
 
 	cmsToDelete := []types.NamespacedName{
-		oah.BuildNamespacedName(ocmAgent.Name),
-		oah.CAMOConfigMapNamespacedName,
+		oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name+ocmagenthandler.ConfigMapSuffix),
+		oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, buildTrustedCaConfigMapName(ocmAgent)),
 	}
 
 	for _, cm := range cmsToDelete {
-		err := o.ensureConfigMapDeleted(cm)
+		err := o.ensureConfigMapDeleted(ocmAgent, cm)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return o.ensureCAMOConfigMapDeleted(ocmAgent)
 }
 
-func (o *ocmAgentHandler) ensureConfigMapDeleted(n types.NamespacedName) error {
+func (o *ocmAgentHandler) ensureConfigMapDeleted(ocmAgent ocmagentv1alpha1.OcmAgent, n types.NamespacedName) error {
 	foundResource := &corev1.ConfigMap{}
 	o.Log.Info("ensuring configmap removed", "resource", n.String())
 	// Does the resource already exist?
-	if err := o.Client.Get(o.Ctx, n, foundResource); err != nil {
+	if err := o.reader().Get(o.Ctx, n, foundResource); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			// Return unexpected error
 			return err
@@ -206,7 +247,7 @@ func (o *ocmAgentHandler) ensureConfigMapDeleted(n types.NamespacedName) error {
 		}
 	}
 	// It does, so remove it
-	err := o.Client.Delete(o.Ctx, foundResource)
+	err := o.deleteResource(ocmAgent, foundResource)
 	if err != nil {
 		return err
 	}
@@ -215,7 +256,7 @@ func (o *ocmAgentHandler) ensureConfigMapDeleted(n types.NamespacedName) error {
 
 func (o *ocmAgentHandler) fetchClusterVersion() (*configv1.ClusterVersion, error) {
 	cv := &configv1.ClusterVersion{}
-	err := o.Client.Get(o.Ctx, types.NamespacedName{Name: "version"}, cv)
+	err := o.reader().Get(o.Ctx, oah.ClusterVersionNamespacedName, cv)
 	if err != nil {
 		return nil, err
 	}
@@ -10,6 +10,7 @@ import (
 	k8serrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
@@ -36,10 +37,11 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 		mockClient = clientmocks.NewMockClient(mockCtrl)
 		testOcmAgent = testconst.TestOCMAgent
 		testOcmAgentHandler = ocmAgentHandler{
-			Client: mockClient,
-			Log:    testconst.Logger,
-			Ctx:    testconst.Context,
-			Scheme: testconst.Scheme,
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
 		}
 		testClusterId = "9345c78b-b6b6-4f42-b242-79bfcc403b0a"
 	})
@@ -66,6 +68,7 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 			testNamespacedName = oahconst.BuildNamespacedName(testOcmAgent.Name)
 			testNamespacedName.Name = testNamespacedName.Name + testconst.TestConfigMapSuffix
 			testConfigMap = buildOCMAgentConfigMap(testOcmAgent, testClusterId)
+			withInstanceLabel(testConfigMap, testOcmAgent)
 		})
 		When("the OCM Agent config already exists", func() {
 			When("the config differs from what is expected", func() {
@@ -76,13 +79,13 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 					goldenConfig := buildOCMAgentConfigMap(testOcmAgent, testClusterId)
 					gomock.InOrder(
 						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).SetArg(2, *testConfigMap),
-						mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
-							func(ctx context.Context, d *corev1.ConfigMap, opts ...client.UpdateOptions) error {
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+							func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
 								Expect(d.Data).To(Equal(goldenConfig.Data))
 								return nil
 							}),
 					)
-					err := testOcmAgentHandler.ensureConfigMap(testOcmAgent, testConfigMap, true)
+					err := testOcmAgentHandler.ensureConfigMap(testOcmAgent, goldenConfig, true)
 					Expect(err).To(BeNil())
 				})
 			})
@@ -102,8 +105,8 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testConfigMap.Name)
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Return(notFound),
-					mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
-						func(ctx context.Context, d *corev1.ConfigMap, opts ...client.CreateOptions) error {
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
 							Expect(reflect.DeepEqual(d.Data, testConfigMap.Data)).To(BeTrue())
 							return nil
 						}),
@@ -119,7 +122,7 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 					gomock.InOrder(
 						mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(notFound),
 					)
-					err := testOcmAgentHandler.ensureConfigMapDeleted(testNamespacedName)
+					err := testOcmAgentHandler.ensureConfigMapDeleted(testOcmAgent, testNamespacedName)
 					Expect(err).To(BeNil())
 				})
 			})
@@ -129,7 +132,7 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 						mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, *testConfigMap),
 						mockClient.EXPECT().Delete(gomock.Any(), testConfigMap),
 					)
-					err := testOcmAgentHandler.ensureConfigMapDeleted(testNamespacedName)
+					err := testOcmAgentHandler.ensureConfigMapDeleted(testOcmAgent, testNamespacedName)
 					Expect(err).To(BeNil())
 				})
 			})
@@ -149,6 +152,57 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 				Expect(cm.Namespace).To(Equal(oahconst.CAMOConfigMapNamespacedName.Namespace))
 				Expect(cm.Data).To(HaveKey(oahconst.OCMAgentServiceURLKey))
 			})
+			It("points at the OCM Agent service in the target namespace", func() {
+				testOcmAgent.Spec.TargetNamespace = "ocm-agent-staging"
+				cm, err = buildCAMOConfigMap(testOcmAgent)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cm.Data[oahconst.OCMAgentServiceURLKey]).To(ContainSubstring(testOcmAgent.Name + ".ocm-agent-staging.svc"))
+			})
+		})
+		When("the CAMO configmap is managed for another OcmAgent", func() {
+			var testCAMOConfigMap *corev1.ConfigMap
+			var testOtherNamespacedName types.NamespacedName
+			BeforeEach(func() {
+				testCAMOConfigMap, _ = buildCAMOConfigMap(testOcmAgent)
+				testCAMOConfigMap.Labels = map[string]string{oahconst.OCMAgentInstanceLabel: "other-ocm-agent"}
+				testOtherNamespacedName = types.NamespacedName{Namespace: testOcmAgent.Namespace, Name: "other-ocm-agent"}
+			})
+			It("leaves it untouched and records the conflict", func() {
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), oahconst.CAMOConfigMapNamespacedName, gomock.Any()).Times(1).SetArg(2, *testCAMOConfigMap),
+					mockClient.EXPECT().Get(gomock.Any(), testOtherNamespacedName, gomock.Any()).Times(1).Return(nil),
+				)
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				err := testOcmAgentHandler.ensureCAMOConfigMap(testOcmAgent)
+				Expect(err).To(BeNil())
+				Expect(testOcmAgentHandler.conflicts).To(HaveLen(1))
+				Expect(testOcmAgentHandler.conflicts[0]).To(ContainSubstring("other-ocm-agent"))
+			})
+			It("takes it over if the other OcmAgent no longer exists", func() {
+				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testOtherNamespacedName.Name)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), oahconst.CAMOConfigMapNamespacedName, gomock.Any()).Times(1).SetArg(2, *testCAMOConfigMap),
+					mockClient.EXPECT().Get(gomock.Any(), testOtherNamespacedName, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Get(gomock.Any(), oahconst.CAMOConfigMapNamespacedName, gomock.Any()).Times(1).SetArg(2, *testCAMOConfigMap),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, cm *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(cm.Labels).To(HaveKeyWithValue(oahconst.OCMAgentInstanceLabel, testOcmAgent.Name))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensureCAMOConfigMap(testOcmAgent)
+				Expect(err).To(BeNil())
+				Expect(testOcmAgentHandler.conflicts).To(BeEmpty())
+			})
+			It("does not remove it", func() {
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), oahconst.CAMOConfigMapNamespacedName, gomock.Any()).Times(1).SetArg(2, *testCAMOConfigMap),
+					mockClient.EXPECT().Get(gomock.Any(), testOtherNamespacedName, gomock.Any()).Times(1).Return(nil),
+				)
+				mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+				err := testOcmAgentHandler.ensureCAMOConfigMapDeleted(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
 		})
 	})
 
@@ -157,17 +211,18 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 		var testNamespacedName types.NamespacedName
 		When("building the Trusted CA configmap", func() {
 			BeforeEach(func() {
-				testcm = buildTrustedCaConfigMap()
+				testcm = buildTrustedCaConfigMap(testOcmAgent)
 			})
 			It("builds successfully", func() {
-				Expect(testcm.Name).To(Equal("trusted-ca-bundle"))
+				Expect(testcm.Name).To(Equal(testOcmAgent.Name + "-trusted-ca-bundle"))
 				Expect(testcm.Namespace).To(Equal(oahconst.OCMAgentNamespace))
 				Expect(testcm.ObjectMeta.Labels).Should(HaveKey(oahconst.InjectCaBundleIndicator))
 			})
 		})
 		When("the trusted ca bundle being updated", func() {
 			BeforeEach(func() {
-				testcm = buildTrustedCaConfigMap()
+				testcm = buildTrustedCaConfigMap(testOcmAgent)
+				withInstanceLabel(testcm, testOcmAgent)
 				testcm.Data = map[string]string{"aaa": "bbb"}
 				testNamespacedName = oahconst.BuildNamespacedName(testcm.Name)
 			})
@@ -195,8 +250,8 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 		It("Adds one if requested", func() {
 			gomock.InOrder(
 				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Return(notFound),
-				mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
-					func(ctx context.Context, d *corev1.ConfigMap, opts ...client.CreateOptions) error {
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
 						Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
 						Expect(*d.ObjectMeta.OwnerReferences[0].BlockOwnerDeletion).To(BeTrue())
 						Expect(*d.ObjectMeta.OwnerReferences[0].Controller).To(BeTrue())
@@ -210,8 +265,8 @@ var _ = Describe("OCM Agent ConfigMap Handler", func() {
 		It("Does not add one if not requested", func() {
 			gomock.InOrder(
 				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Return(notFound),
-				mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
-					func(ctx context.Context, d *corev1.ConfigMap, opts ...client.CreateOptions) error {
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, d *corev1.ConfigMap, patch client.Patch, opts ...client.PatchOption) error {
 						Expect(d.ObjectMeta.OwnerReferences).To(BeNil())
 						return nil
 					}),
@@ -1,21 +1,20 @@
 package ocmagenthandler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
-	"reflect"
 	"sort"
 
-	"github.com/go-logr/logr"
-
 	oconfigv1 "github.com/openshift/api/config/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	"github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
@@ -23,7 +22,7 @@ import (
 )
 
 func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployment {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
 	labels := map[string]string{
 		"app": ocmAgent.Name,
 	}
@@ -65,7 +64,7 @@ func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployme
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: oah.TrustedCaBundleConfigMapName,
+						Name: buildTrustedCaConfigMapName(ocmAgent),
 					},
 					Items: []corev1.KeyToPath{
 						{
@@ -112,21 +111,17 @@ func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployme
 		return volumeMounts[i].Name < volumeMounts[j].Name
 	})
 
-	// Define resource limits for the config
-	resourceLimits := corev1.ResourceList{
-		corev1.ResourceCPU:    k8sresource.MustParse(oah.ResourceLimitsCPU),
-		corev1.ResourceMemory: k8sresource.MustParse(oah.ResourceLimitsMemory),
-	}
-	// Define resource requests for the config
-	resourceRequests := corev1.ResourceList{
-		corev1.ResourceCPU:    k8sresource.MustParse(oah.ResourceRequestsCPU),
-		corev1.ResourceMemory: k8sresource.MustParse(oah.ResourceRequestsMemory),
-	}
-
 	// Construct the command arguments of the agent
 	ocmAgentCommand := buildOCMAgentArgs(ocmAgent)
 
-	replicas := int32(ocmAgent.Spec.Replicas)
+	// Leave the replica count to the HorizontalPodAutoscaler when autoscaling is enabled
+	var replicas *int32
+	if ocmAgent.Spec.Autoscaling == nil {
+		r := ocmAgent.Spec.Replicas
+		replicas = &r
+	}
+	allowPrivilegeEscalation := false
+	runAsNonRoot := true
 	dep := appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      namespacedName.Name,
@@ -134,33 +129,20 @@ func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployme
 			Labels:    labels,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
+			Replicas: replicas,
 			Selector: &labelSelectors,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					Volumes:            volumes,
-					ServiceAccountName: oah.OCMAgentServiceAccount,
-					Affinity: &corev1.Affinity{
-						NodeAffinity: &corev1.NodeAffinity{
-							PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{
-								Preference: corev1.NodeSelectorTerm{
-									MatchExpressions: []corev1.NodeSelectorRequirement{{
-										Key:      "node-role.kubernetes.io/infra",
-										Operator: corev1.NodeSelectorOpExists,
-									}},
-								},
-								Weight: 1,
-							}},
-						},
-					},
-					Tolerations: []corev1.Toleration{{
-						Operator: corev1.TolerationOpExists,
-						Effect:   corev1.TaintEffectNoSchedule,
-						Key:      "node-role.kubernetes.io/infra",
-					}},
+					Volumes:                   volumes,
+					ServiceAccountName:        ocmAgent.Name,
+					NodeSelector:              ocmAgent.Spec.NodeSelector,
+					Affinity:                  buildOCMAgentAffinity(ocmAgent),
+					Tolerations:               buildOCMAgentTolerations(ocmAgent),
+					TopologySpreadConstraints: buildOCMAgentTopologySpreadConstraints(ocmAgent),
+					PriorityClassName:         ocmAgent.Spec.PriorityClassName,
 					Containers: []corev1.Container{{
 						Env:          envVars,
 						VolumeMounts: volumeMounts,
@@ -170,6 +152,7 @@ func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployme
 						Ports: []corev1.ContainerPort{{
 							ContainerPort: oah.OCMAgentPort,
 							Name:          oah.OCMAgentPortName,
+							Protocol:      corev1.ProtocolTCP,
 						}},
 						ReadinessProbe: &corev1.Probe{
 							ProbeHandler: corev1.ProbeHandler{
@@ -179,6 +162,10 @@ func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployme
 									Port:   intstr.FromInt(oah.OCMAgentPort),
 								},
 							},
+							TimeoutSeconds:   oah.OCMAgentProbeTimeoutSeconds,
+							PeriodSeconds:    oah.OCMAgentProbePeriodSeconds,
+							SuccessThreshold: oah.OCMAgentProbeSuccessThreshold,
+							FailureThreshold: oah.OCMAgentProbeFailureThreshold,
 						},
 						LivenessProbe: &corev1.Probe{
 							ProbeHandler: corev1.ProbeHandler{
@@ -188,10 +175,21 @@ func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployme
 									Port:   intstr.FromInt(oah.OCMAgentPort),
 								},
 							},
+							TimeoutSeconds:   oah.OCMAgentProbeTimeoutSeconds,
+							PeriodSeconds:    oah.OCMAgentProbePeriodSeconds,
+							SuccessThreshold: oah.OCMAgentProbeSuccessThreshold,
+							FailureThreshold: oah.OCMAgentProbeFailureThreshold,
 						},
-						Resources: corev1.ResourceRequirements{
-							Limits:   resourceLimits,
-							Requests: resourceRequests,
+						Resources: buildOCMAgentResources(ocmAgent),
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+							Capabilities: &corev1.Capabilities{
+								Drop: []corev1.Capability{"ALL"},
+							},
+							RunAsNonRoot: &runAsNonRoot,
+							SeccompProfile: &corev1.SeccompProfile{
+								Type: corev1.SeccompProfileTypeRuntimeDefault,
+							},
 						},
 					}},
 				},
@@ -201,6 +199,80 @@ func buildOCMAgentDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) appsv1.Deployme
 	return dep
 }
 
+// buildOCMAgentResources returns the compute resources of the OCM Agent container,
+// defaulting to the operator's built-in requests and limits, as the OcmAgent isn't defaulted
+// while the webhooks are disabled
+func buildOCMAgentResources(ocmAgent ocmagentv1alpha1.OcmAgent) corev1.ResourceRequirements {
+	if ocmAgent.Spec.Resources != nil {
+		return *ocmAgent.Spec.Resources.DeepCopy()
+	}
+	return oah.BuildDefaultResources()
+}
+
+// buildOCMAgentAffinity returns the affinity of the OCM Agent pods, defaulting to
+// preferring infra nodes and, when running more than one replica, separate nodes
+func buildOCMAgentAffinity(ocmAgent ocmagentv1alpha1.OcmAgent) *corev1.Affinity {
+	if ocmAgent.Spec.Affinity != nil {
+		return ocmAgent.Spec.Affinity.DeepCopy()
+	}
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{
+				Preference: corev1.NodeSelectorTerm{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      oah.InfraNodeLabel,
+						Operator: corev1.NodeSelectorOpExists,
+					}},
+				},
+				Weight: 1,
+			}},
+		},
+	}
+	if multiReplica(ocmAgent) {
+		affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": ocmAgent.Name},
+					},
+					TopologyKey: corev1.LabelHostname,
+				},
+				Weight: 100,
+			}},
+		}
+	}
+	return affinity
+}
+
+// buildOCMAgentTopologySpreadConstraints returns the topology spread constraints of the
+// OCM Agent pods, defaulting to spreading them across zones when running more than one replica
+func buildOCMAgentTopologySpreadConstraints(ocmAgent ocmagentv1alpha1.OcmAgent) []corev1.TopologySpreadConstraint {
+	if ocmAgent.Spec.TopologySpreadConstraints != nil || !multiReplica(ocmAgent) {
+		return ocmAgent.Spec.TopologySpreadConstraints
+	}
+	return []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelTopologyZone,
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": ocmAgent.Name},
+		},
+	}}
+}
+
+// buildOCMAgentTolerations returns the tolerations of the OCM Agent pods,
+// defaulting to tolerating infra nodes
+func buildOCMAgentTolerations(ocmAgent ocmagentv1alpha1.OcmAgent) []corev1.Toleration {
+	if ocmAgent.Spec.Tolerations != nil {
+		return ocmAgent.Spec.Tolerations
+	}
+	return []corev1.Toleration{{
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+		Key:      oah.InfraNodeLabel,
+	}}
+}
+
 // buildOCMAgentArgs returns the full command argument list to run the OCM Agent
 // in a deployment.
 func buildOCMAgentArgs(ocmAgent ocmagentv1alpha1.OcmAgent) []string {
@@ -234,61 +306,35 @@ func buildOCMAgentArgs(ocmAgent ocmagentv1alpha1.OcmAgent) []string {
 // ensureDeployment ensures that an OCMAgent Deployment exists on the cluster
 // and that its configuration matches what is expected.
 func (o *ocmAgentHandler) ensureDeployment(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name)
-	foundResource := &appsv1.Deployment{}
-	populationFunc := func() appsv1.Deployment {
-		return buildOCMAgentDeployment(ocmAgent)
+	envVars, err := o.buildEnvVars(ocmAgent)
+	if err != nil {
+		return err
 	}
 
-	envVars, err := o.buildEnvVars(ocmAgent)
+	checksum, err := o.buildConfigChecksum(ocmAgent)
 	if err != nil {
 		return err
 	}
 
 	// Populate the resource with template and append the env vars
-	resource := populationFunc()
+	resource := buildOCMAgentDeployment(ocmAgent)
 	resource.Spec.Template.Spec.Containers[0].Env = envVars
-
-	// Does the resource already exist?
-	o.Log.Info("ensuring deployment exists", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
-		if k8serrors.IsNotFound(err) {
-			// It does not exist, so must be created.
-			o.Log.Info("An OCMAgent deployment does not exist; will be created.")
-			// Set the controller reference
-			if err := controllerutil.SetControllerReference(&ocmAgent, &resource, o.Scheme); err != nil {
-				return err
-			}
-			// and create it
-			err = o.Client.Create(o.Ctx, &resource)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Return unexpectedly
-			return err
-		}
-	} else {
-		// It does exist, check if it is what we expected
-		if deploymentConfigChanged(foundResource, &resource, ocmAgent, o.Log) {
-			// Specs aren't equal, update and fix.
-			o.Log.Info("An OCMAgent deployment exists but contains unexpected configuration. Restoring.")
-			foundResource.Spec = *resource.Spec.DeepCopy()
-			if err = o.Client.Update(o.Ctx, foundResource); err != nil {
-				return err
-			}
-		}
+	resource.Spec.Template.Annotations = map[string]string{
+		oah.OCMAgentConfigChecksumAnnotation: checksum,
 	}
-	return nil
+
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		return deploymentConfigChanged(current.(*appsv1.Deployment), &resource)
+	})
 }
 
 // ensureDeploymentDeleted removes the deployment from the cluster
 func (o *ocmAgentHandler) ensureDeploymentDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
 	foundResource := &appsv1.Deployment{}
 	// Does the resource already exist?
 	o.Log.Info("ensuring deployment removed", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			// Return unexpected error
 			return err
@@ -297,113 +343,72 @@ func (o *ocmAgentHandler) ensureDeploymentDeleted(ocmAgent ocmagentv1alpha1.OcmA
 			return nil
 		}
 	}
-	err := o.Client.Delete(o.Ctx, foundResource)
+	err := o.deleteResource(ocmAgent, foundResource)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// deploymentConfigChanged flags if the two supplied deployments differ in configuration
-// that the OCM Agent Operator manages
-func deploymentConfigChanged(current, expected *appsv1.Deployment, ocmAgent ocmagentv1alpha1.OcmAgent, log logr.Logger) bool {
-	changed := false
-
-	// Compare labels
-	if !reflect.DeepEqual(current.Labels, expected.Labels) {
-		return true
-	}
-	if !reflect.DeepEqual(current.Spec.Template.Labels, expected.Spec.Template.Labels) {
-		return true
-	}
+// deploymentConfigChanged returns the paths of the fields of the current deployment that
+// differ from the configuration of the expected deployment that the OCM Agent Operator manages.
+// Fields left unset in the expected deployment, such as those defaulted by the API server,
+// are ignored, while the command, args and env of the containers must match exactly.
+func deploymentConfigChanged(current, expected *appsv1.Deployment) []string {
+	drift := fieldDrift{}
+
+	drift.subset("metadata.labels", expected.Labels, current.Labels)
+	drift.derivative("spec.replicas", expected.Spec.Replicas, current.Spec.Replicas)
+	drift.derivative("spec.selector", expected.Spec.Selector, current.Spec.Selector)
+	drift.subset("spec.template.metadata.labels", expected.Spec.Template.Labels, current.Spec.Template.Labels)
+	drift.subset("spec.template.metadata.annotations", expected.Spec.Template.Annotations, current.Spec.Template.Annotations)
+
+	curPod, expPod := current.Spec.Template.Spec, expected.Spec.Template.Spec
+	drift.derivative("spec.template.spec.serviceAccountName", expPod.ServiceAccountName, curPod.ServiceAccountName)
+	drift.derivative("spec.template.spec.volumes", expPod.Volumes, curPod.Volumes)
+	drift.derivative("spec.template.spec.securityContext", expPod.SecurityContext, curPod.SecurityContext)
+	// The scheduling fields are configurable on the OcmAgent, so are compared exactly
+	// to also catch values that are left over after being unset on the OcmAgent
+	drift.equal("spec.template.spec.nodeSelector", expPod.NodeSelector, curPod.NodeSelector)
+	drift.equal("spec.template.spec.affinity", expPod.Affinity, curPod.Affinity)
+	drift.equal("spec.template.spec.tolerations", expPod.Tolerations, curPod.Tolerations)
+	drift.equal("spec.template.spec.topologySpreadConstraints", expPod.TopologySpreadConstraints, curPod.TopologySpreadConstraints)
+	drift.equal("spec.template.spec.priorityClassName", expPod.PriorityClassName, curPod.PriorityClassName)
 
 	// There may be multiple containers eventually, so let's do a loop
-	for _, name := range []string{ocmAgent.Name} {
-		var curImage, expImage string
-		var curReadinessProbeHTTPGet, curLivenessProbeHTTPGet, expReadinessProbeHTTPGet, expLivenessProbeHTTPGet *corev1.HTTPGetAction
-		var curEnvs, expEnvs []corev1.EnvVar
-		// Assign current container spec
-		for i, c := range current.Spec.Template.Spec.Containers {
-			if name == c.Name {
-				curImage = current.Spec.Template.Spec.Containers[i].Image
-				// get current readiness probe HTTPGetter only if ReadinessProbe is set
-				if current.Spec.Template.Spec.Containers[i].ReadinessProbe != nil {
-					curReadinessProbeHTTPGet = current.Spec.Template.Spec.Containers[i].ReadinessProbe.HTTPGet
-				}
-				// get current liveness probe HTTPGetter only if LivenessProbe is set
-				if current.Spec.Template.Spec.Containers[i].LivenessProbe != nil {
-					curLivenessProbeHTTPGet = current.Spec.Template.Spec.Containers[i].LivenessProbe.HTTPGet
-				}
-				curEnvs = current.Spec.Template.Spec.Containers[i].Env
-				break
-			}
-		}
-		// Assign expected container spec
-		for i, c := range expected.Spec.Template.Spec.Containers {
-			if name == c.Name {
-				expImage = expected.Spec.Template.Spec.Containers[i].Image
-				expReadinessProbeHTTPGet = expected.Spec.Template.Spec.Containers[i].ReadinessProbe.HTTPGet
-				expLivenessProbeHTTPGet = expected.Spec.Template.Spec.Containers[i].LivenessProbe.HTTPGet
-				expEnvs = expected.Spec.Template.Spec.Containers[i].Env
+	for _, exp := range expPod.Containers {
+		path := fmt.Sprintf("spec.template.spec.containers[%s]", exp.Name)
+		var cur *corev1.Container
+		for i := range curPod.Containers {
+			if curPod.Containers[i].Name == exp.Name {
+				cur = &curPod.Containers[i]
 				break
 			}
 		}
-
-		if len(curImage) == 0 {
-			log.V(2).Info(fmt.Sprintf("current deployment %s/%s did not contain expected %s container", current.Namespace, current.Name, name))
-			changed = true
-			break
-		} else if curImage != expImage {
-			changed = true
-		}
-
-		// Compare readiness probe change
-		if !reflect.DeepEqual(curReadinessProbeHTTPGet, expReadinessProbeHTTPGet) {
-			log.V(2).Info(fmt.Sprintf("current readiness probe http getter %s/%s did not match expected readiness http getter", curReadinessProbeHTTPGet, expReadinessProbeHTTPGet))
-			changed = true
-		}
-
-		// Compare readiness probe change
-		if !reflect.DeepEqual(curLivenessProbeHTTPGet, expLivenessProbeHTTPGet) {
-			log.V(2).Info(fmt.Sprintf("current liveness probe http getter %s/%s did not match expected liveness probe http getter", curLivenessProbeHTTPGet, expLivenessProbeHTTPGet))
-			changed = true
-		}
-
-		if !reflect.DeepEqual(curEnvs, expEnvs) {
-			log.V(2).Info(fmt.Sprintf("current env vars %s/%s did not match expected env vars", curEnvs, expEnvs))
-			changed = true
+		if cur == nil {
+			drift = append(drift, path)
+			continue
 		}
-
-	}
-
-	// Compare replicas
-	if *(current.Spec.Replicas) != *(expected.Spec.Replicas) {
-		log.V(2).Info(fmt.Sprintf("current deployment %s/%s did not contain expected replicas: %v", current.Namespace, current.Name, *(expected.Spec.Replicas)))
-		changed = true
+		drift.equal(path+".image", exp.Image, cur.Image)
+		drift.equal(path+".command", exp.Command, cur.Command)
+		drift.equal(path+".args", exp.Args, cur.Args)
+		drift.equal(path+".env", exp.Env, cur.Env)
+		drift.derivative(path+".ports", exp.Ports, cur.Ports)
+		drift.derivative(path+".volumeMounts", exp.VolumeMounts, cur.VolumeMounts)
+		drift.equal(path+".resources", exp.Resources, cur.Resources)
+		drift.derivative(path+".readinessProbe", exp.ReadinessProbe, cur.ReadinessProbe)
+		drift.derivative(path+".livenessProbe", exp.LivenessProbe, cur.LivenessProbe)
+		drift.derivative(path+".securityContext", exp.SecurityContext, cur.SecurityContext)
 	}
 
-	// Compare affinity
-	if !reflect.DeepEqual(current.Spec.Template.Spec.Affinity, expected.Spec.Template.Spec.Affinity) {
-		log.V(2).Info(fmt.Sprintf("current deployment %s/%s did not contain expected affinity", current.Namespace, current.Name))
-		changed = true
-	}
-
-	// Compare tolerations
-	if !reflect.DeepEqual(current.Spec.Template.Spec.Tolerations, expected.Spec.Template.Spec.Tolerations) {
-		log.V(2).Info(fmt.Sprintf("current deployment %s/%s did not contain expected tolerations", current.Namespace, current.Name))
-		changed = true
-	}
-
-	// TODO compare more things if needed
-
-	return changed
+	return drift
 }
 
 // buildEnvVars build the slice of environments to set to the OCM Agent deployment
 func (o *ocmAgentHandler) buildEnvVars(ocmAgent ocmagentv1alpha1.OcmAgent) ([]corev1.EnvVar, error) {
 	envVars := []corev1.EnvVar{}
 	proxy := oconfigv1.Proxy{}
-	err := o.Client.Get(o.Ctx, oah.ProxyNamespacedName, &proxy)
+	err := o.reader().Get(o.Ctx, oah.ProxyNamespacedName, &proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -417,3 +422,41 @@ func (o *ocmAgentHandler) buildEnvVars(ocmAgent ocmagentv1alpha1.OcmAgent) ([]co
 
 	return envVars, nil
 }
+
+// buildConfigChecksum returns the checksum of the content of the token secret, the OCM Agent
+// configmap and the trusted CA bundle configmap that are mounted into the OCM Agent pods.
+// A resource that does not exist yet contributes no content to the checksum.
+func (o *ocmAgentHandler) buildConfigChecksum(ocmAgent ocmagentv1alpha1.OcmAgent) (string, error) {
+	tokenSecret := &corev1.Secret{}
+	configMap := &corev1.ConfigMap{}
+	trustedCaConfigMap := &corev1.ConfigMap{}
+	mounted := []struct {
+		name string
+		obj  client.Object
+	}{
+		{name: ocmAgent.Spec.TokenSecret, obj: tokenSecret},
+		{name: ocmAgent.Name + ocmagenthandler.ConfigMapSuffix, obj: configMap},
+		{name: buildTrustedCaConfigMapName(ocmAgent), obj: trustedCaConfigMap},
+	}
+	for _, m := range mounted {
+		if err := o.reader().Get(o.Ctx, oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, m.name), m.obj); err != nil && !k8serrors.IsNotFound(err) {
+			return "", err
+		}
+	}
+	return configChecksum(tokenSecret, configMap, trustedCaConfigMap)
+}
+
+// configChecksum returns the SHA-256 checksum of the data of the token secret and configmaps
+func configChecksum(tokenSecret *corev1.Secret, configMap, trustedCaConfigMap *corev1.ConfigMap) (string, error) {
+	// Maps are marshalled with sorted keys, so the checksum is stable
+	content, err := json.Marshal([]interface{}{
+		tokenSecret.Data,
+		configMap.Data,
+		trustedCaConfigMap.Data,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -2,15 +2,21 @@ package ocmagenthandler
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"reflect"
 
 	oconfigv1 "github.com/openshift/api/config/v1"
 	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
 	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/golang/mock/gomock"
@@ -38,10 +44,11 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 		testOcmAgent = testconst.TestOCMAgent
 		testHSOcmAgent = testconst.TestHSOCMAgent
 		testOcmAgentHandler = ocmAgentHandler{
-			Client: mockClient,
-			Log:    testconst.Logger,
-			Ctx:    testconst.Context,
-			Scheme: testconst.Scheme,
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
 		}
 	})
 
@@ -85,6 +92,57 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 		})
 	})
 
+	Context("When building an OCM Agent Deployment with scheduling configuration", func() {
+		var configuredOcmAgent ocmagentv1alpha1.OcmAgent
+		BeforeEach(func() {
+			configuredOcmAgent = *testOcmAgent.DeepCopy()
+			configuredOcmAgent.Spec.Resources = &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: k8sresource.MustParse("256Mi")},
+			}
+			configuredOcmAgent.Spec.NodeSelector = map[string]string{"node-role.kubernetes.io/worker": ""}
+			configuredOcmAgent.Spec.Tolerations = []corev1.Toleration{}
+			configuredOcmAgent.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+			configuredOcmAgent.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+			}}
+			configuredOcmAgent.Spec.PriorityClassName = "system-cluster-critical"
+		})
+		It("uses the configured values", func() {
+			deployment := buildOCMAgentDeployment(configuredOcmAgent)
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Containers[0].Resources).To(Equal(*configuredOcmAgent.Spec.Resources))
+			Expect(podSpec.NodeSelector).To(Equal(configuredOcmAgent.Spec.NodeSelector))
+			Expect(podSpec.Tolerations).To(BeEmpty())
+			Expect(podSpec.Affinity).To(Equal(configuredOcmAgent.Spec.Affinity))
+			Expect(podSpec.TopologySpreadConstraints).To(Equal(configuredOcmAgent.Spec.TopologySpreadConstraints))
+			Expect(podSpec.PriorityClassName).To(Equal("system-cluster-critical"))
+		})
+		It("defaults to the infra node placement when not configured", func() {
+			deployment := buildOCMAgentDeployment(testOcmAgent)
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Tolerations).To(HaveLen(1))
+			Expect(podSpec.Tolerations[0].Key).To(Equal(ocmagenthandler.InfraNodeLabel))
+			Expect(podSpec.Affinity.NodeAffinity).NotTo(BeNil())
+			Expect(podSpec.NodeSelector).To(BeEmpty())
+			Expect(podSpec.PriorityClassName).To(BeEmpty())
+		})
+		It("detects drift when the configuration is removed from the OCM Agent", func() {
+			current := buildOCMAgentDeployment(configuredOcmAgent)
+			expected := buildOCMAgentDeployment(testOcmAgent)
+			changed := deploymentConfigChanged(&current, &expected)
+			Expect(changed).To(ContainElements(
+				"spec.template.spec.nodeSelector",
+				"spec.template.spec.affinity",
+				"spec.template.spec.tolerations",
+				"spec.template.spec.topologySpreadConstraints",
+				"spec.template.spec.priorityClassName",
+				"spec.template.spec.containers["+testOcmAgent.Name+"].resources",
+			))
+		})
+	})
+
 	Context("When building an OCM Agent HS Deployment", func() {
 		It("deploys with the expected configured values", func() {
 			deployment := buildOCMAgentDeployment(testHSOcmAgent)
@@ -132,13 +190,56 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 		})
 	})
 
+	Context("When calculating the config checksum", func() {
+		var testSecret corev1.Secret
+		var testConfigMap, testTrustedCaConfigMap corev1.ConfigMap
+		BeforeEach(func() {
+			testSecret = buildOCMAgentAccessTokenSecret([]byte("token"), testOcmAgent)
+			testConfigMap = *buildOCMAgentConfigMap(testOcmAgent, "cluster-id")
+			testTrustedCaConfigMap = corev1.ConfigMap{Data: map[string]string{"ca-bundle.crt": "bundle"}}
+		})
+		It("is stable for the same content", func() {
+			first, err := configChecksum(&testSecret, &testConfigMap, &testTrustedCaConfigMap)
+			Expect(err).To(BeNil())
+			second, err := configChecksum(testSecret.DeepCopy(), testConfigMap.DeepCopy(), testTrustedCaConfigMap.DeepCopy())
+			Expect(err).To(BeNil())
+			Expect(first).To(Equal(second))
+		})
+		It("changes with the content of each resource", func() {
+			original, _ := configChecksum(&testSecret, &testConfigMap, &testTrustedCaConfigMap)
+
+			rotatedSecret := testSecret.DeepCopy()
+			rotatedSecret.Data[ocmagenthandler.OCMAgentAccessTokenSecretKey] = []byte("rotated")
+			checksum, _ := configChecksum(rotatedSecret, &testConfigMap, &testTrustedCaConfigMap)
+			Expect(checksum).NotTo(Equal(original))
+
+			changedConfigMap := testConfigMap.DeepCopy()
+			changedConfigMap.Data[ocmagenthandler.OCMAgentConfigURLKey] = "https://api.example.com"
+			checksum, _ = configChecksum(&testSecret, changedConfigMap, &testTrustedCaConfigMap)
+			Expect(checksum).NotTo(Equal(original))
+
+			changedTrustedCa := testTrustedCaConfigMap.DeepCopy()
+			changedTrustedCa.Data["ca-bundle.crt"] = "new bundle"
+			checksum, _ = configChecksum(&testSecret, &testConfigMap, changedTrustedCa)
+			Expect(checksum).NotTo(Equal(original))
+		})
+	})
+
 	Context("Managing the OCM Agent deployment", func() {
 		var testDeployment appsv1.Deployment
 		var testNamespacedName types.NamespacedName
 		var testProxy, testNoProxy oconfigv1.Proxy
+		var testTokenSecret corev1.Secret
+		var testChecksum string
 		BeforeEach(func() {
 			testNamespacedName = ocmagenthandler.BuildNamespacedName(testOcmAgent.Name)
+			testTokenSecret = buildOCMAgentAccessTokenSecret([]byte("token"), testOcmAgent)
+			testChecksum, _ = configChecksum(&testTokenSecret, &corev1.ConfigMap{}, &corev1.ConfigMap{})
 			testDeployment = buildOCMAgentDeployment(testOcmAgent)
+			testDeployment.Spec.Template.Annotations = map[string]string{
+				ocmagenthandler.OCMAgentConfigChecksumAnnotation: testChecksum,
+			}
+			withInstanceLabel(&testDeployment, testOcmAgent)
 			testProxy = oconfigv1.Proxy{
 				Status: oconfigv1.ProxyStatus{
 					HTTPProxy: "proxy.test:8080",
@@ -148,6 +249,17 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 			testNoProxy = oconfigv1.Proxy{}
 		})
 
+		// expectMountedResourceLookups expects the token secret, OCM Agent configmap and
+		// trusted CA bundle configmap to be looked up when building the config checksum
+		expectMountedResourceLookups := func() []*gomock.Call {
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, "")
+			return []*gomock.Call{
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.BuildNamespacedName(testOcmAgent.Spec.TokenSecret), gomock.Any()).Times(1).SetArg(2, testTokenSecret),
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.BuildNamespacedName(testOcmAgent.Name+testconst.TestConfigMapSuffix), gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.BuildNamespacedName(buildTrustedCaConfigMapName(testOcmAgent)), gomock.Any()).Times(1).Return(notFound),
+			}
+		}
+
 		When("the OCM Agent deployment already exists", func() {
 			When("the deployment differs from what is expected", func() {
 				BeforeEach(func() {
@@ -157,30 +269,129 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 				})
 				It("updates the deployment", func() {
 					goldenDeployment := buildOCMAgentDeployment(testOcmAgent)
-					gomock.InOrder(
-						mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testProxy),
+					calls := []*gomock.Call{mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testProxy)}
+					calls = append(calls, expectMountedResourceLookups()...)
+					calls = append(calls,
 						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testDeployment),
-						mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
-							func(ctx context.Context, d *appsv1.Deployment, opts ...client.UpdateOptions) error {
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *appsv1.Deployment, patch client.Patch, opts ...client.PatchOption) error {
 								Expect(d.Spec.Replicas).To(Equal(goldenDeployment.Spec.Replicas))
 								Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal(goldenDeployment.Spec.Template.Spec.Containers[0].Image))
 								return nil
 							}),
 					)
+					gomock.InOrder(calls...)
+					err := testOcmAgentHandler.ensureDeployment(testOcmAgent)
+					Expect(err).To(BeNil())
+				})
+			})
+			When("another field manager added an env var to the deployment", func() {
+				var container string
+				BeforeEach(func() {
+					container = testDeployment.Spec.Template.Spec.Containers[0].Name
+					testDeployment.Spec.Template.Spec.Containers[0].Env = append(testDeployment.Spec.Template.Spec.Containers[0].Env,
+						corev1.EnvVar{Name: "FOO", Value: "bar"})
+					testDeployment.ResourceVersion = "2"
+					testDeployment.ManagedFields = []metav1.ManagedFieldsEntry{
+						{
+							Manager:    ocmagenthandler.OCMAgentOperatorFieldManager,
+							Operation:  metav1.ManagedFieldsOperationApply,
+							APIVersion: "apps/v1",
+							FieldsType: "FieldsV1",
+							FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"` + container + `\"}":{".":{},"f:name":{}}}}}}}`)},
+						},
+						{
+							Manager:    "kubectl-edit",
+							Operation:  metav1.ManagedFieldsOperationUpdate,
+							APIVersion: "apps/v1",
+							FieldsType: "FieldsV1",
+							FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:note":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"` + container + `\"}":{"f:env":{"k:{\"name\":\"FOO\"}":{".":{},"f:name":{},"f:value":{}}}}}}}}}`)},
+						},
+					}
+				})
+				It("takes over the env var so that the apply removes it", func() {
+					calls := []*gomock.Call{mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testNoProxy)}
+					calls = append(calls, expectMountedResourceLookups()...)
+					calls = append(calls,
+						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testDeployment),
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *appsv1.Deployment, patch client.Patch, opts ...client.PatchOption) error {
+								Expect(patch.Type()).To(Equal(types.JSONPatchType))
+								data, err := patch.Data(d)
+								Expect(err).To(BeNil())
+								var ops []struct {
+									Op    string
+									Path  string
+									Value json.RawMessage
+								}
+								Expect(json.Unmarshal(data, &ops)).To(Succeed())
+								Expect(ops).To(HaveLen(2))
+								Expect(string(ops[0].Value)).To(Equal(`"2"`))
+								var entries []metav1.ManagedFieldsEntry
+								Expect(json.Unmarshal(ops[1].Value, &entries)).To(Succeed())
+								Expect(entries).To(HaveLen(2))
+								Expect(entries[0].Manager).To(Equal(ocmagenthandler.OCMAgentOperatorFieldManager))
+								Expect(string(entries[0].FieldsV1.Raw)).To(ContainSubstring(`k:{\"name\":\"FOO\"}`))
+								Expect(entries[1].Manager).To(Equal("kubectl-edit"))
+								Expect(string(entries[1].FieldsV1.Raw)).To(Equal(`{"f:metadata":{"f:annotations":{"f:note":{}}}}`))
+								return nil
+							}),
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *appsv1.Deployment, patch client.Patch, opts ...client.PatchOption) error {
+								for _, env := range d.Spec.Template.Spec.Containers[0].Env {
+									Expect(env.Name).NotTo(Equal("FOO"))
+								}
+								return nil
+							}),
+					)
+					gomock.InOrder(calls...)
 					err := testOcmAgentHandler.ensureDeployment(testOcmAgent)
 					Expect(err).To(BeNil())
 				})
 			})
 			When("the deployment matches what is expected", func() {
 				It("does not update the deployment", func() {
-					gomock.InOrder(
-						mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testNoProxy),
+					calls := []*gomock.Call{mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testNoProxy)}
+					calls = append(calls, expectMountedResourceLookups()...)
+					calls = append(calls, mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testDeployment))
+					gomock.InOrder(calls...)
+					err := testOcmAgentHandler.ensureDeployment(testOcmAgent)
+					Expect(err).To(BeNil())
+				})
+			})
+			When("the content of a mounted resource has changed", func() {
+				BeforeEach(func() {
+					testTokenSecret.Data[ocmagenthandler.OCMAgentAccessTokenSecretKey] = []byte("rotated")
+				})
+				It("rolls out the deployment with the new config checksum", func() {
+					calls := []*gomock.Call{mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testNoProxy)}
+					calls = append(calls, expectMountedResourceLookups()...)
+					calls = append(calls,
 						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testDeployment),
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *appsv1.Deployment, patch client.Patch, opts ...client.PatchOption) error {
+								checksum := d.Spec.Template.Annotations[ocmagenthandler.OCMAgentConfigChecksumAnnotation]
+								Expect(checksum).NotTo(BeEmpty())
+								Expect(checksum).NotTo(Equal(testChecksum))
+								return nil
+							}),
 					)
+					gomock.InOrder(calls...)
 					err := testOcmAgentHandler.ensureDeployment(testOcmAgent)
 					Expect(err).To(BeNil())
 				})
 			})
+			When("a mounted resource cannot be fetched", func() {
+				It("returns the error", func() {
+					fakeError := k8serrs.NewInternalError(fmt.Errorf("fake error"))
+					gomock.InOrder(
+						mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testNoProxy),
+						mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(fakeError),
+					)
+					err := testOcmAgentHandler.ensureDeployment(testOcmAgent)
+					Expect(err).To(Equal(fakeError))
+				})
+			})
 		})
 
 		When("the OCM Agent deployment does not already exist", func() {
@@ -193,11 +404,12 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 			})
 			It("creates the deployment", func() {
 				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testDeployment.Name)
-				gomock.InOrder(
-					mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testProxy),
+				calls := []*gomock.Call{mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).SetArg(2, testProxy)}
+				calls = append(calls, expectMountedResourceLookups()...)
+				calls = append(calls,
 					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
-					mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
-						func(ctx context.Context, d *appsv1.Deployment, opts ...client.CreateOptions) error {
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *appsv1.Deployment, patch client.Patch, opts ...client.PatchOption) error {
 							Expect(reflect.DeepEqual(d.Spec, testDeployment.Spec)).To(BeTrue())
 							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
 							Expect(*d.ObjectMeta.OwnerReferences[0].BlockOwnerDeletion).To(BeTrue())
@@ -205,6 +417,7 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 							return nil
 						}),
 				)
+				gomock.InOrder(calls...)
 				err := testOcmAgentHandler.ensureDeployment(testOcmAgent)
 				Expect(err).To(BeNil())
 			})
@@ -235,53 +448,118 @@ var _ = Describe("OCM Agent Deployment Handler", func() {
 
 		When("checking if the OCM Agent deployment has been changed", func() {
 			var goldenDeployment appsv1.Deployment
+			var containerPath string
 			BeforeEach(func() {
 				goldenDeployment = buildOCMAgentDeployment(testOcmAgent)
+				containerPath = "spec.template.spec.containers[" + testOcmAgent.Name + "]"
 			})
 			It("should detect a label change", func() {
 				testDeployment.Labels = map[string]string{"dummy": "value"}
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf("metadata.labels"))
+			})
+			It("should detect a config checksum change", func() {
+				goldenDeployment.Spec.Template.Annotations = map[string]string{
+					ocmagenthandler.OCMAgentConfigChecksumAnnotation: "new-checksum",
+				}
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf("spec.template.metadata.annotations"))
 			})
 			It("should detect an image change", func() {
 				testDeployment.Spec.Template.Spec.Containers[0].Image = "something else"
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".image"))
 			})
 			It("should handle missing readiness probe", func() {
 				testDeployment.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".readinessProbe"))
 			})
 			It("should handle missing liveness probe", func() {
 				testDeployment.Spec.Template.Spec.Containers[0].LivenessProbe = nil
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".livenessProbe"))
 			})
 			It("should detect a readiness probe change", func() {
 				testDeployment.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet = nil
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".readinessProbe"))
 			})
 			It("should detect a liveness probe change", func() {
 				testDeployment.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet = nil
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".livenessProbe"))
 			})
 			It("should detect a replica change", func() {
 				replicas := int32(5000)
 				testDeployment.Spec.Replicas = &replicas
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf("spec.replicas"))
 			})
 			It("should detect a affinity change", func() {
 				testDeployment.Spec.Template.Spec.Affinity = nil
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeTrue())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf("spec.template.spec.affinity"))
+			})
+			It("should detect a command change", func() {
+				testDeployment.Spec.Template.Spec.Containers[0].Command = append(testDeployment.Spec.Template.Spec.Containers[0].Command, "--debug")
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".command"))
+			})
+			It("should detect added args", func() {
+				testDeployment.Spec.Template.Spec.Containers[0].Args = []string{"--debug"}
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".args"))
+			})
+			It("should detect a volume change", func() {
+				testDeployment.Spec.Template.Spec.Volumes = testDeployment.Spec.Template.Spec.Volumes[1:]
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf("spec.template.spec.volumes"))
+			})
+			It("should detect a volume mount change", func() {
+				testDeployment.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath = "/elsewhere"
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".volumeMounts"))
+			})
+			It("should detect a resources change", func() {
+				testDeployment.Spec.Template.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory] = k8sresource.MustParse("1Gi")
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".resources"))
+			})
+			It("should detect a service account change", func() {
+				testDeployment.Spec.Template.Spec.ServiceAccountName = "default"
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf("spec.template.spec.serviceAccountName"))
+			})
+			It("should detect a security context change", func() {
+				privileged := true
+				testDeployment.Spec.Template.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = &privileged
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath + ".securityContext"))
+			})
+			It("should detect a missing container", func() {
+				testDeployment.Spec.Template.Spec.Containers[0].Name = "other"
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(ConsistOf(containerPath))
+			})
+			It("should not detect fields defaulted by the API server", func() {
+				revisionHistoryLimit := int32(10)
+				optional := false
+				testDeployment.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+				testDeployment.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+				testDeployment.Spec.Template.Spec.SchedulerName = "default-scheduler"
+				testDeployment.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+				testDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+				testDeployment.Spec.Template.Spec.Containers[0].ReadinessProbe.TimeoutSeconds = 1
+				testDeployment.Spec.Template.Spec.Containers[0].ReadinessProbe.PeriodSeconds = 10
+				testDeployment.Spec.Template.Spec.Containers[0].LivenessProbe.FailureThreshold = 3
+				testDeployment.Spec.Template.Spec.Volumes[0].Secret.Optional = &optional
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(BeEmpty())
 			})
 			It("not detect a change if there are no differences", func() {
-				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment, testOcmAgent, testconst.Logger)
-				Expect(changed).To(BeFalse())
+				changed := deploymentConfigChanged(&testDeployment, &goldenDeployment)
+				Expect(changed).To(BeEmpty())
 			})
 		})
 	})
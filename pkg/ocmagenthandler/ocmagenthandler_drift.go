@@ -0,0 +1,47 @@
+package ocmagenthandler
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// fieldDrift collects the paths of the fields of a managed resource that have
+// drifted from the configuration that the OCM Agent Operator manages
+type fieldDrift []string
+
+// add flags the field as drifted, reporting each path only once
+func (d *fieldDrift) add(path string) {
+	for _, p := range *d {
+		if p == path {
+			return
+		}
+	}
+	*d = append(*d, path)
+}
+
+// derivative flags the field as drifted unless the current value is a semantic
+// derivative of the expected value, i.e. ignoring fields that are unset in the
+// expected value and so are either defaulted by the API server or set by others
+func (d *fieldDrift) derivative(path string, expected, current interface{}) {
+	if !equality.Semantic.DeepDerivative(expected, current) {
+		d.add(path)
+	}
+}
+
+// equal flags the field as drifted unless the current value is semantically
+// equal to the expected value
+func (d *fieldDrift) equal(path string, expected, current interface{}) {
+	if !equality.Semantic.DeepEqual(expected, current) {
+		d.add(path)
+	}
+}
+
+// subset flags the field as drifted unless every expected key is present in the
+// current map with the expected value
+func (d *fieldDrift) subset(path string, expected, current map[string]string) {
+	for k, v := range expected {
+		if cur, ok := current[k]; !ok || cur != v {
+			d.add(path)
+			return
+		}
+	}
+}
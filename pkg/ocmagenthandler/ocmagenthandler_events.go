@@ -0,0 +1,53 @@
+package ocmagenthandler
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+)
+
+// The reasons of the Events recorded on an OcmAgent for the resources managed for it
+// and for the transitions of its mode
+const (
+	reasonCreated        = "Created"
+	reasonUpdated        = "Updated"
+	reasonDriftCorrected = "DriftCorrected"
+	reasonDeleted        = "Deleted"
+	reasonCreateFailed   = "CreateFailed"
+	reasonUpdateFailed   = "UpdateFailed"
+	reasonDeleteFailed   = "DeleteFailed"
+
+	reasonModeChanged          = "ModeChanged"
+	reasonModeTransitionFailed = "ModeTransitionFailed"
+)
+
+// resourceKind returns the kind of the resource
+func (o *ocmAgentHandler) resourceKind(resource client.Object) string {
+	if gvk, err := apiutil.GVKForObject(resource, o.Scheme); err == nil {
+		return gvk.Kind
+	}
+	return resource.GetObjectKind().GroupVersionKind().Kind
+}
+
+// deleteResource removes the resource from the cluster, and records its removal as an
+// Event on the OcmAgent and in the ensure operations metric. A resource that is already
+// gone is not reported, and its NotFound error is returned to the caller.
+func (o *ocmAgentHandler) deleteResource(ocmAgent ocmagentv1alpha1.OcmAgent, resource client.Object) error {
+	kind := o.resourceKind(resource)
+	namespacedName := client.ObjectKeyFromObject(resource)
+	err := o.Client.Delete(o.Ctx, resource)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			localmetrics.IncrementMetricHandlerErrors(ocmAgent.Name, kind)
+			o.Recorder.Eventf(&ocmAgent, corev1.EventTypeWarning, reasonDeleteFailed, "Failed to delete %s %s: %v", kind, namespacedName.String(), err)
+		}
+		return err
+	}
+	localmetrics.IncrementMetricEnsureOperations(ocmAgent.Name, kind, localmetrics.ActionDelete)
+	o.Recorder.Eventf(&ocmAgent, corev1.EventTypeNormal, reasonDeleted, "Deleted %s %s", kind, namespacedName.String())
+	return nil
+}
@@ -0,0 +1,77 @@
+package ocmagenthandler
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/localmetrics"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCM Agent Events", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testRecorder        *record.FakeRecorder
+		testService         *corev1.Service
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = testconst.TestOCMAgent
+		testRecorder = record.NewFakeRecorder(10)
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: testRecorder,
+		}
+		testService = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: "ocm-agent"}}
+	})
+
+	When("deleting a resource", func() {
+		It("records the deletion", func() {
+			mockClient.EXPECT().Delete(gomock.Any(), testService).Return(nil)
+			deleted := localmetrics.MetricEnsureOperations.WithLabelValues(testOcmAgent.Name, "Service", localmetrics.ActionDelete)
+			before := testutil.ToFloat64(deleted)
+			err := testOcmAgentHandler.deleteResource(testOcmAgent, testService)
+			Expect(err).To(BeNil())
+			Expect(testRecorder.Events).To(Receive(Equal("Normal Deleted Deleted Service test-namespace/ocm-agent")))
+			Expect(testutil.ToFloat64(deleted)).To(Equal(before + 1))
+		})
+		It("records a failure to delete it", func() {
+			mockClient.EXPECT().Delete(gomock.Any(), testService).Return(fmt.Errorf("fake error"))
+			handlerErrors := localmetrics.MetricHandlerErrors.WithLabelValues(testOcmAgent.Name, "Service")
+			before := testutil.ToFloat64(handlerErrors)
+			err := testOcmAgentHandler.deleteResource(testOcmAgent, testService)
+			Expect(err).NotTo(BeNil())
+			Expect(testRecorder.Events).To(Receive(Equal("Warning DeleteFailed Failed to delete Service test-namespace/ocm-agent: fake error")))
+			Expect(testutil.ToFloat64(handlerErrors)).To(Equal(before + 1))
+		})
+		It("does not record a resource that is already gone", func() {
+			mockClient.EXPECT().Delete(gomock.Any(), testService).Return(k8serrs.NewNotFound(schema.GroupResource{}, testService.Name))
+			err := testOcmAgentHandler.deleteResource(testOcmAgent, testService)
+			Expect(k8serrs.IsNotFound(err)).To(BeTrue())
+			Expect(testRecorder.Events).To(BeEmpty())
+		})
+	})
+})
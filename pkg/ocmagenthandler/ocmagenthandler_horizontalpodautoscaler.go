@@ -0,0 +1,107 @@
+package ocmagenthandler
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+func buildOCMAgentHorizontalPodAutoscaler(ocmAgent ocmagentv1alpha1.OcmAgent) autoscalingv2.HorizontalPodAutoscaler {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	autoscaling := ocmAgent.Spec.Autoscaling
+
+	minReplicas := int32(oah.OCMAgentHPADefaultMinReplicas)
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, buildResourceUtilizationMetric(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, buildResourceUtilizationMetric(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+	if len(metrics) == 0 {
+		metrics = append(metrics, buildResourceUtilizationMetric(corev1.ResourceCPU, oah.OCMAgentHPADefaultCPUUtilization))
+	}
+
+	hpa := autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+			Labels: map[string]string{
+				"app": ocmAgent.Name,
+			},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       ocmAgent.Name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+	return hpa
+}
+
+func buildResourceUtilizationMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
+
+// ensureHorizontalPodAutoscaler ensures that an OCMAgent HorizontalPodAutoscaler exists on the cluster
+// while autoscaling is enabled, and that it is removed otherwise.
+func (o *ocmAgentHandler) ensureHorizontalPodAutoscaler(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	if ocmAgent.Spec.Autoscaling == nil {
+		return o.ensureHorizontalPodAutoscalerDeleted(ocmAgent)
+	}
+	resource := buildOCMAgentHorizontalPodAutoscaler(ocmAgent)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		cur := current.(*autoscalingv2.HorizontalPodAutoscaler)
+		drift := fieldDrift{}
+		drift.subset("metadata.labels", resource.Labels, cur.Labels)
+		drift.derivative("spec.scaleTargetRef", resource.Spec.ScaleTargetRef, cur.Spec.ScaleTargetRef)
+		drift.derivative("spec.minReplicas", resource.Spec.MinReplicas, cur.Spec.MinReplicas)
+		drift.derivative("spec.maxReplicas", resource.Spec.MaxReplicas, cur.Spec.MaxReplicas)
+		drift.equal("spec.metrics", resource.Spec.Metrics, cur.Spec.Metrics)
+		return drift
+	})
+}
+
+func (o *ocmAgentHandler) ensureHorizontalPodAutoscalerDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	foundResource := &autoscalingv2.HorizontalPodAutoscaler{}
+	// Does the resource already exist?
+	o.Log.Info("ensuring horizontalpodautoscaler removed", "resource", namespacedName.String())
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			// Return unexpected error
+			return err
+		} else {
+			// Resource deleted
+			return nil
+		}
+	}
+	err := o.deleteResource(ocmAgent, foundResource)
+	if err != nil {
+		return err
+	}
+	return nil
+}
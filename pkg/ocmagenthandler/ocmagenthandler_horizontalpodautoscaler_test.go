@@ -0,0 +1,157 @@
+package ocmagenthandler
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCM Agent HorizontalPodAutoscaler Handler", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testNamespacedName  types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = *testconst.TestOCMAgent.DeepCopy()
+		testOcmAgent.Spec.Autoscaling = &ocmagentv1alpha1.AutoscalingConfig{MaxReplicas: 3}
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
+		}
+		testNamespacedName = oah.BuildNamespacedName(testOcmAgent.Name)
+	})
+
+	Context("When building an OCM Agent HorizontalPodAutoscaler", func() {
+		It("scales the OCM Agent deployment", func() {
+			hpa := buildOCMAgentHorizontalPodAutoscaler(testOcmAgent)
+			Expect(hpa.Name).To(Equal(testOcmAgent.Name))
+			Expect(hpa.Spec.ScaleTargetRef.Kind).To(Equal("Deployment"))
+			Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(testOcmAgent.Name))
+			Expect(*hpa.Spec.MinReplicas).To(Equal(int32(oah.OCMAgentHPADefaultMinReplicas)))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(3)))
+		})
+		It("targets the default CPU utilization if no target is set", func() {
+			hpa := buildOCMAgentHorizontalPodAutoscaler(testOcmAgent)
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(hpa.Spec.Metrics[0].Resource.Name).To(Equal(corev1.ResourceCPU))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(oah.OCMAgentHPADefaultCPUUtilization)))
+		})
+		It("targets the configured utilization", func() {
+			minReplicas := int32(2)
+			memory := int32(70)
+			testOcmAgent.Spec.Autoscaling.MinReplicas = &minReplicas
+			testOcmAgent.Spec.Autoscaling.TargetMemoryUtilizationPercentage = &memory
+			hpa := buildOCMAgentHorizontalPodAutoscaler(testOcmAgent)
+			Expect(*hpa.Spec.MinReplicas).To(Equal(minReplicas))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(hpa.Spec.Metrics[0].Resource.Name).To(Equal(corev1.ResourceMemory))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(memory))
+		})
+	})
+
+	Context("When building an OCM Agent Deployment", func() {
+		It("leaves the replica count to the autoscaler", func() {
+			deployment := buildOCMAgentDeployment(testOcmAgent)
+			Expect(deployment.Spec.Replicas).To(BeNil())
+		})
+		It("does not report the autoscaled replica count as drift", func() {
+			expected := buildOCMAgentDeployment(testOcmAgent)
+			current := expected.DeepCopy()
+			replicas := int32(3)
+			current.Spec.Replicas = &replicas
+			Expect(deploymentConfigChanged(current, &expected)).To(BeEmpty())
+		})
+		It("spreads the pods when it may scale to more than one replica", func() {
+			testOcmAgent.Spec.Replicas = 1
+			Expect(multiReplica(testOcmAgent)).To(BeTrue())
+		})
+	})
+
+	Context("Managing the OCM Agent HorizontalPodAutoscaler", func() {
+		When("autoscaling is enabled", func() {
+			It("creates the HorizontalPodAutoscaler", func() {
+				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testOcmAgent.Name)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *autoscalingv2.HorizontalPodAutoscaler, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(d.Spec.MaxReplicas).To(Equal(int32(3)))
+							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensureHorizontalPodAutoscaler(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+			It("does not update a HorizontalPodAutoscaler that matches what is expected", func() {
+				testHPA := buildOCMAgentHorizontalPodAutoscaler(testOcmAgent)
+				withInstanceLabel(&testHPA, testOcmAgent)
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testHPA)
+				err := testOcmAgentHandler.ensureHorizontalPodAutoscaler(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+			It("restores a HorizontalPodAutoscaler that has been changed", func() {
+				testHPA := buildOCMAgentHorizontalPodAutoscaler(testOcmAgent)
+				testHPA.Spec.MaxReplicas = 10
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testHPA),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *autoscalingv2.HorizontalPodAutoscaler, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(d.Spec.MaxReplicas).To(Equal(int32(3)))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensureHorizontalPodAutoscaler(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+		When("autoscaling is disabled", func() {
+			BeforeEach(func() {
+				testOcmAgent.Spec.Autoscaling = nil
+			})
+			It("removes the HorizontalPodAutoscaler", func() {
+				testHPA := autoscalingv2.HorizontalPodAutoscaler{}
+				testHPA.Name = testNamespacedName.Name
+				testHPA.Namespace = testNamespacedName.Namespace
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testHPA),
+					mockClient.EXPECT().Delete(gomock.Any(), &testHPA),
+				)
+				err := testOcmAgentHandler.ensureHorizontalPodAutoscaler(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+			It("does nothing if the HorizontalPodAutoscaler is already removed", func() {
+				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testOcmAgent.Name)
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound)
+				err := testOcmAgentHandler.ensureHorizontalPodAutoscaler(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+})
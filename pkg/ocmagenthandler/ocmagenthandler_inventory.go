@@ -0,0 +1,158 @@
+package ocmagenthandler
+
+import (
+	"sort"
+
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+// managedKinds are the kinds of the resources managed for an OcmAgent in the operator namespace
+var managedKinds = []schema.GroupVersionKind{
+	appsv1.SchemeGroupVersion.WithKind("Deployment"),
+	corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+	corev1.SchemeGroupVersion.WithKind("Secret"),
+	corev1.SchemeGroupVersion.WithKind("Service"),
+	netv1.SchemeGroupVersion.WithKind("NetworkPolicy"),
+	monitorv1.SchemeGroupVersion.WithKind("ServiceMonitor"),
+	monitorv1.SchemeGroupVersion.WithKind("PrometheusRule"),
+	policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget"),
+	autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"),
+	corev1.SchemeGroupVersion.WithKind("ServiceAccount"),
+	rbacv1.SchemeGroupVersion.WithKind("Role"),
+	rbacv1.SchemeGroupVersion.WithKind("RoleBinding"),
+}
+
+// sortInventory returns the inventory without duplicates, sorted by kind, namespace and name
+func sortInventory(inventory []ocmagentv1alpha1.ManagedObjectReference) []ocmagentv1alpha1.ManagedObjectReference {
+	seen := map[ocmagentv1alpha1.ManagedObjectReference]bool{}
+	sorted := make([]ocmagentv1alpha1.ManagedObjectReference, 0, len(inventory))
+	for _, r := range inventory {
+		if !seen[r] {
+			seen[r] = true
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Kind != sorted[j].Kind {
+			return sorted[i].Kind < sorted[j].Kind
+		}
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// ensureOrphansDeleted removes the objects managed for the OcmAgent that are not in the desired
+// inventory. Those are the objects in the operator namespace that are labelled with the OcmAgent
+// instance, and the objects recorded in the inventory of the OcmAgent status.
+func (o *ocmAgentHandler) ensureOrphansDeleted(ocmAgent ocmagentv1alpha1.OcmAgent, desired []ocmagentv1alpha1.ManagedObjectReference) error {
+	labelled, err := o.listLabelledObjects(ocmAgent)
+	if err != nil {
+		return err
+	}
+
+	keep := map[ocmagentv1alpha1.ManagedObjectReference]bool{}
+	for _, r := range desired {
+		keep[r] = true
+	}
+
+	var errs []error
+	for _, r := range sortInventory(append(labelled, ocmAgent.Status.Inventory...)) {
+		if keep[r] {
+			continue
+		}
+		if err := o.ensureObjectDeleted(ocmAgent, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// listLabelledObjects returns the objects in the operator namespace that are labelled
+// with the OcmAgent instance
+func (o *ocmAgentHandler) listLabelledObjects(ocmAgent ocmagentv1alpha1.OcmAgent) ([]ocmagentv1alpha1.ManagedObjectReference, error) {
+	namespace := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name).Namespace
+	var labelled []ocmagentv1alpha1.ManagedObjectReference
+	for _, gvk := range managedKinds {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := o.reader().List(o.Ctx, list, client.InNamespace(namespace),
+			client.MatchingLabels{oah.OCMAgentInstanceLabel: ocmAgent.Name})
+		if err != nil {
+			// The ServiceMonitor and PrometheusRule kinds aren't served on clusters without the Prometheus Operator
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, item := range list.Items {
+			labelled = append(labelled, ocmagentv1alpha1.ManagedObjectReference{
+				APIVersion: gvk.GroupVersion().String(),
+				Kind:       gvk.Kind,
+				Namespace:  item.Namespace,
+				Name:       item.Name,
+			})
+		}
+	}
+	return labelled, nil
+}
+
+// ensureObjectDeleted removes the referenced object from the cluster. The inventory of the
+// OcmAgent status can be stale or written by others, so only objects of the managed kinds in
+// the operator or target namespace that are still labelled with the OcmAgent instance are removed.
+func (o *ocmAgentHandler) ensureObjectDeleted(ocmAgent ocmagentv1alpha1.OcmAgent, r ocmagentv1alpha1.ManagedObjectReference) error {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(r.APIVersion, r.Kind))
+	key := client.ObjectKey{Namespace: r.Namespace, Name: r.Name}
+	if !isManagedKind(obj.GroupVersionKind()) || !isManagedNamespace(ocmAgent, r.Namespace) {
+		o.Log.Info("skipping removal of an unmanaged resource", "kind", r.Kind, "resource", key.String())
+		return nil
+	}
+	if err := o.reader().Get(o.Ctx, key, obj); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if obj.GetLabels()[oah.OCMAgentInstanceLabel] != ocmAgent.Name {
+		o.Log.Info("skipping removal of a resource not managed for the OcmAgent", "kind", r.Kind, "resource", key.String())
+		return nil
+	}
+	o.Log.Info("removing orphaned resource", "kind", r.Kind, "resource", key.String())
+	if err := o.deleteResource(ocmAgent, obj); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func isManagedKind(gvk schema.GroupVersionKind) bool {
+	for _, k := range managedKinds {
+		if k == gvk {
+			return true
+		}
+	}
+	return false
+}
+
+// isManagedNamespace returns whether the resources of the OcmAgent can be managed in the namespace
+func isManagedNamespace(ocmAgent ocmagentv1alpha1.OcmAgent, namespace string) bool {
+	return namespace == oah.BuildNamespacedName(ocmAgent.Name).Namespace ||
+		namespace == oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name).Namespace
+}
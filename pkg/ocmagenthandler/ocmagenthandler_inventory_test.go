@@ -0,0 +1,163 @@
+package ocmagenthandler
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCM Agent Inventory Handler", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testNamespace       string
+
+		desiredService ocmagentv1alpha1.ManagedObjectReference
+		orphanedHPA    ocmagentv1alpha1.ManagedObjectReference
+	)
+
+	// listReturns stubs the List of the supplied kind to return objects with the supplied names
+	listReturns := func(kind string, names ...string) *gomock.Call {
+		return mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *metav1.PartialObjectMetadataList, _ ...client.ListOption) error {
+				if list.Kind != kind+"List" {
+					return nil
+				}
+				for _, name := range names {
+					list.Items = append(list.Items, metav1.PartialObjectMetadata{
+						ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+					})
+				}
+				return nil
+			}).Times(len(managedKinds))
+	}
+
+	// getReturns stubs the Get of the objects to return their metadata with the supplied instance label
+	getReturns := func(instance string) *gomock.Call {
+		return mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, key client.ObjectKey, obj *metav1.PartialObjectMetadata, _ ...client.GetOption) error {
+				obj.SetNamespace(key.Namespace)
+				obj.SetName(key.Name)
+				obj.SetLabels(map[string]string{ocmagenthandler.OCMAgentInstanceLabel: instance})
+				return nil
+			}).AnyTimes()
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = *testconst.TestOCMAgent.DeepCopy()
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
+		}
+		testNamespace = ocmagenthandler.BuildNamespacedName(testOcmAgent.Name).Namespace
+		desiredService = ocmagentv1alpha1.ManagedObjectReference{APIVersion: "v1", Kind: "Service", Namespace: testNamespace, Name: testOcmAgent.Name}
+		orphanedHPA = ocmagentv1alpha1.ManagedObjectReference{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler", Namespace: testNamespace, Name: testOcmAgent.Name}
+	})
+
+	Context("When sorting the inventory", func() {
+		It("removes duplicates and sorts by kind, namespace and name", func() {
+			b := ocmagentv1alpha1.ManagedObjectReference{APIVersion: "v1", Kind: "Service", Namespace: testNamespace, Name: "b"}
+			sorted := sortInventory([]ocmagentv1alpha1.ManagedObjectReference{b, orphanedHPA, desiredService, b})
+			Expect(sorted).To(Equal([]ocmagentv1alpha1.ManagedObjectReference{orphanedHPA, b, desiredService}))
+		})
+	})
+
+	Context("When removing orphaned resources", func() {
+		It("lists the labelled resources in the operator namespace", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(),
+				client.InNamespace(testNamespace),
+				client.MatchingLabels{ocmagenthandler.OCMAgentInstanceLabel: testOcmAgent.Name},
+			).Times(len(managedKinds)).Return(nil)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, nil)
+			Expect(err).To(BeNil())
+		})
+		It("deletes labelled resources that are no longer desired", func() {
+			listReturns("HorizontalPodAutoscaler", orphanedHPA.Name)
+			getReturns(testOcmAgent.Name)
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, obj *metav1.PartialObjectMetadata, _ ...client.DeleteOption) error {
+					Expect(obj.GroupVersionKind()).To(Equal(schema.GroupVersionKind{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}))
+					Expect(obj.Name).To(Equal(orphanedHPA.Name))
+					return nil
+				}).Times(1)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, []ocmagentv1alpha1.ManagedObjectReference{desiredService})
+			Expect(err).To(BeNil())
+		})
+		It("deletes resources recorded in the inventory that are no longer desired", func() {
+			testOcmAgent.Status.Inventory = []ocmagentv1alpha1.ManagedObjectReference{desiredService, orphanedHPA}
+			listReturns("Service", desiredService.Name)
+			getReturns(testOcmAgent.Name)
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, []ocmagentv1alpha1.ManagedObjectReference{desiredService})
+			Expect(err).To(BeNil())
+		})
+		It("keeps resources recorded in the inventory that are labelled for another OcmAgent", func() {
+			sharedConfigMap := ocmagentv1alpha1.ManagedObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: testNamespace, Name: "shared"}
+			testOcmAgent.Status.Inventory = []ocmagentv1alpha1.ManagedObjectReference{sharedConfigMap}
+			listReturns("ConfigMap")
+			getReturns("other-ocm-agent")
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, nil)
+			Expect(err).To(BeNil())
+		})
+		It("keeps resources recorded in the inventory that are not found", func() {
+			testOcmAgent.Status.Inventory = []ocmagentv1alpha1.ManagedObjectReference{orphanedHPA}
+			listReturns("HorizontalPodAutoscaler")
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(k8serrors.NewNotFound(schema.GroupResource{}, orphanedHPA.Name))
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, nil)
+			Expect(err).To(BeNil())
+		})
+		It("ignores inventory entries of unmanaged kinds or namespaces", func() {
+			testOcmAgent.Status.Inventory = []ocmagentv1alpha1.ManagedObjectReference{
+				{APIVersion: "v1", Kind: "Namespace", Name: testNamespace},
+				{APIVersion: "v1", Kind: "Secret", Namespace: "openshift-config", Name: "pull-secret"},
+			}
+			listReturns("Secret")
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, nil)
+			Expect(err).To(BeNil())
+		})
+		It("keeps the desired resources", func() {
+			testOcmAgent.Status.Inventory = []ocmagentv1alpha1.ManagedObjectReference{desiredService}
+			listReturns("Service", desiredService.Name)
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, []ocmagentv1alpha1.ManagedObjectReference{desiredService})
+			Expect(err).To(BeNil())
+		})
+		It("skips kinds that are not served by the cluster", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Times(len(managedKinds)).Return(&meta.NoKindMatchError{})
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, nil)
+			Expect(err).To(BeNil())
+		})
+		It("returns an error if the resources cannot be listed", func() {
+			fakeError := fmt.Errorf("fake error")
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(fakeError)
+			err := testOcmAgentHandler.ensureOrphansDeleted(testOcmAgent, nil)
+			Expect(err).To(Equal(fakeError))
+		})
+	})
+})
@@ -0,0 +1,56 @@
+package ocmagenthandler
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+)
+
+// buildOCMAgentMode returns the mode that the OCM Agent is configured to run in
+func buildOCMAgentMode(ocmAgent ocmagentv1alpha1.OcmAgent) ocmagentv1alpha1.OcmAgentMode {
+	if ocmAgent.Spec.FleetMode {
+		return ocmagentv1alpha1.OcmAgentModeFleet
+	}
+	return ocmagentv1alpha1.OcmAgentModeCluster
+}
+
+// ensureModeTransition records the mode of the OCM Agent in its status. When the mode
+// differs from the mode the OCM Agent was last deployed in, the resources that only
+// belong to the previous mode are removed first, and the transition is recorded as an Event.
+func (o *ocmAgentHandler) ensureModeTransition(ocmAgent *ocmagentv1alpha1.OcmAgent) error {
+	mode := buildOCMAgentMode(*ocmAgent)
+	previousMode := ocmAgent.Status.Mode
+	if previousMode == "" || previousMode == mode {
+		ocmAgent.Status.Mode = mode
+		return nil
+	}
+
+	o.Log.Info("OCM Agent mode changed, removing resources of the previous mode", "previousMode", previousMode, "mode", mode)
+	previous := ocmAgent.DeepCopy()
+	previous.Spec.FleetMode = previousMode == ocmagentv1alpha1.OcmAgentModeFleet
+
+	ensureFuncs := []ensureResource{o.ensureNetworkPolicyDeleted}
+	if previousMode == ocmagentv1alpha1.OcmAgentModeCluster {
+		ensureFuncs = append(ensureFuncs, o.ensureCAMOConfigMapDeleted, o.ensureOwnedAccessTokenSecretDeleted)
+	}
+
+	var errs []error
+	for _, fn := range ensureFuncs {
+		if err := fn(*previous); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		// Keep the previous mode, so that the transition is retried on the next reconcile
+		o.Recorder.Eventf(ocmAgent, corev1.EventTypeWarning, reasonModeTransitionFailed, "Failed to remove the resources of the %s mode", previousMode)
+		return utilerrors.NewAggregate(errs)
+	}
+
+	now := metav1.Now()
+	ocmAgent.Status.Mode = mode
+	ocmAgent.Status.LastModeTransitionTime = &now
+	o.Recorder.Eventf(ocmAgent, corev1.EventTypeNormal, reasonModeChanged, "Switched the OCM Agent from %s to %s mode and removed the resources of the %s mode", previousMode, mode, previousMode)
+	return nil
+}
@@ -0,0 +1,129 @@
+package ocmagenthandler
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCM Agent Mode Handler", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testRecorder        *record.FakeRecorder
+		notFound            error
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = *testconst.TestOCMAgent.DeepCopy()
+		testRecorder = record.NewFakeRecorder(10)
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: testRecorder,
+		}
+		notFound = k8serrs.NewNotFound(schema.GroupResource{}, "")
+	})
+
+	Context("When the OCM Agent has no recorded mode", func() {
+		It("records the mode without removing any resources", func() {
+			err := testOcmAgentHandler.ensureModeTransition(&testOcmAgent)
+			Expect(err).To(BeNil())
+			Expect(testOcmAgent.Status.Mode).To(Equal(ocmagentv1alpha1.OcmAgentModeCluster))
+			Expect(testOcmAgent.Status.LastModeTransitionTime).To(BeNil())
+			Expect(testRecorder.Events).To(BeEmpty())
+		})
+	})
+
+	Context("When the OCM Agent mode is unchanged", func() {
+		It("does nothing", func() {
+			testOcmAgent.Status.Mode = ocmagentv1alpha1.OcmAgentModeCluster
+			err := testOcmAgentHandler.ensureModeTransition(&testOcmAgent)
+			Expect(err).To(BeNil())
+			Expect(testOcmAgent.Status.LastModeTransitionTime).To(BeNil())
+			Expect(testRecorder.Events).To(BeEmpty())
+		})
+	})
+
+	Context("When the OCM Agent switches to fleet mode", func() {
+		var testNetworkPolicy netv1.NetworkPolicy
+		var testSecret corev1.Secret
+		BeforeEach(func() {
+			testNetworkPolicy = buildNetworkPolicy(testOcmAgent, operatorconfig.AllowedIngressNamespacesDefault)
+			testSecret = buildOCMAgentAccessTokenSecret([]byte("token"), testOcmAgent)
+			testOcmAgent.Status.Mode = ocmagentv1alpha1.OcmAgentModeCluster
+			testOcmAgent.Spec.FleetMode = true
+		})
+		It("removes the cluster mode resources and records the transition", func() {
+			Expect(controllerutil.SetControllerReference(&testOcmAgent, &testSecret, testconst.Scheme)).To(Succeed())
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.BuildNamespacedName(testOcmAgent.Name+ocmagenthandler.OCMAgentNetworkPolicySuffix), gomock.Any()).Times(1).SetArg(2, testNetworkPolicy),
+				mockClient.EXPECT().Delete(gomock.Any(), &testNetworkPolicy),
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.CAMOConfigMapNamespacedName, gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.BuildNamespacedName(testOcmAgent.Spec.TokenSecret), gomock.Any()).Times(1).SetArg(2, testSecret),
+				mockClient.EXPECT().Delete(gomock.Any(), &testSecret),
+			)
+			err := testOcmAgentHandler.ensureModeTransition(&testOcmAgent)
+			Expect(err).To(BeNil())
+			Expect(testOcmAgent.Status.Mode).To(Equal(ocmagentv1alpha1.OcmAgentModeFleet))
+			Expect(testOcmAgent.Status.LastModeTransitionTime).NotTo(BeNil())
+			Expect(testRecorder.Events).To(Receive(ContainSubstring("Deleted NetworkPolicy")))
+			Expect(testRecorder.Events).To(Receive(ContainSubstring("Deleted Secret")))
+			Expect(testRecorder.Events).To(Receive(ContainSubstring("ModeChanged")))
+		})
+		It("keeps a token secret that is not owned by the OcmAgent", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(notFound),
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.BuildNamespacedName(testOcmAgent.Spec.TokenSecret), gomock.Any()).Times(1).SetArg(2, testSecret),
+			)
+			err := testOcmAgentHandler.ensureModeTransition(&testOcmAgent)
+			Expect(err).To(BeNil())
+			Expect(testOcmAgent.Status.Mode).To(Equal(ocmagentv1alpha1.OcmAgentModeFleet))
+		})
+		It("keeps the previous mode if the resources cannot be removed", func() {
+			fakeError := fmt.Errorf("fake error")
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(3).Return(fakeError)
+			err := testOcmAgentHandler.ensureModeTransition(&testOcmAgent)
+			Expect(err).To(HaveOccurred())
+			Expect(testOcmAgent.Status.Mode).To(Equal(ocmagentv1alpha1.OcmAgentModeCluster))
+			Expect(testOcmAgent.Status.LastModeTransitionTime).To(BeNil())
+			Expect(testRecorder.Events).To(Receive(ContainSubstring("ModeTransitionFailed")))
+		})
+	})
+
+	Context("When the OCM Agent switches to cluster mode", func() {
+		It("removes the fleet mode network policy", func() {
+			testOcmAgent.Status.Mode = ocmagentv1alpha1.OcmAgentModeFleet
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), ocmagenthandler.BuildNamespacedName(testOcmAgent.Name+ocmagenthandler.OCMFleetAgentNetworkPolicySuffix), gomock.Any()).Times(1).Return(notFound),
+			)
+			err := testOcmAgentHandler.ensureModeTransition(&testOcmAgent)
+			Expect(err).To(BeNil())
+			Expect(testOcmAgent.Status.Mode).To(Equal(ocmagentv1alpha1.OcmAgentModeCluster))
+			Expect(testRecorder.Events).To(Receive(ContainSubstring("ModeChanged")))
+		})
+	})
+})
@@ -1,36 +1,37 @@
 package ocmagenthandler
 
 import (
-	"context"
-	"reflect"
-
-	"k8s.io/apimachinery/pkg/types"
-
 	netv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
 )
 
-func buildNetworkPolicy(ocmAgent ocmagentv1alpha1.OcmAgent) netv1.NetworkPolicy {
-	var (
-		namespacedName    types.NamespacedName
-		namespaceSelector *metav1.LabelSelector
-	)
+// buildNetworkPolicyName returns the name of the network policy for the mode the OCM Agent runs in
+func buildNetworkPolicyName(ocmAgent ocmagentv1alpha1.OcmAgent) string {
+	if ocmAgent.Spec.FleetMode {
+		return ocmAgent.Name + oah.OCMFleetAgentNetworkPolicySuffix
+	}
+	return ocmAgent.Name + oah.OCMAgentNetworkPolicySuffix
+}
+
+// buildNetworkPolicy returns the network policy of the OCM Agent, which in fleet mode
+// allows ingress from the supplied namespaces
+func buildNetworkPolicy(ocmAgent ocmagentv1alpha1.OcmAgent, fleetIngressNamespaces []string) netv1.NetworkPolicy {
+	var namespaceSelector *metav1.LabelSelector
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, buildNetworkPolicyName(ocmAgent))
 	if ocmAgent.Spec.FleetMode {
-		namespacedName = oah.BuildNamespacedName(ocmAgent.Name + oah.OCMFleetAgentNetworkPolicySuffix)
 		namespaceSelector = &metav1.LabelSelector{
 			MatchExpressions: []metav1.LabelSelectorRequirement{{
 				Key:      "name",
 				Operator: "In",
-				Values:   []string{"observatorium-mst-production", "openshift-monitoring"},
+				Values:   fleetIngressNamespaces,
 			}},
 		}
 	} else {
-		namespacedName = oah.BuildNamespacedName(ocmAgent.Name + oah.OCMAgentNetworkPolicySuffix)
 		namespaceSelector = &metav1.LabelSelector{
 			MatchLabels: map[string]string{"name": "openshift-monitoring"},
 		}
@@ -60,63 +61,23 @@ func buildNetworkPolicy(ocmAgent ocmagentv1alpha1.OcmAgent) netv1.NetworkPolicy
 // ensureNetworkPolicy ensures that an OCMAgent NetworkPolicy exists on the cluster
 // and that its configuration matches what is expected.
 func (o *ocmAgentHandler) ensureNetworkPolicy(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	var namespacedName types.NamespacedName
-	if ocmAgent.Spec.FleetMode {
-		namespacedName = oah.BuildNamespacedName(ocmAgent.Name + oah.OCMFleetAgentNetworkPolicySuffix)
-	} else {
-		namespacedName = oah.BuildNamespacedName(ocmAgent.Name + oah.OCMAgentNetworkPolicySuffix)
-	}
-	foundResource := &netv1.NetworkPolicy{}
-	populationFunc := func() netv1.NetworkPolicy {
-		return buildNetworkPolicy(ocmAgent)
-	}
-	// Does the resource already exist?
-	o.Log.Info("ensuring networkpolicy exists", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
-		if k8serrors.IsNotFound(err) {
-			// It does not exist, so must be created.
-			o.Log.Info("An OCMAgent NetworkPolicy does not exist; will be created.")
-			// Populate the resource with the template
-			resource := populationFunc()
-			// Set the controller reference
-			if err := controllerutil.SetControllerReference(&ocmAgent, &resource, o.Scheme); err != nil {
-				return err
-			}
-			// and create it
-			err = o.Client.Create(o.Ctx, &resource)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Return unexpectedly
-			return err
-		}
-	} else {
-		// It does exist, check if it is what we expected
-		resource := populationFunc()
-		if !reflect.DeepEqual(foundResource.Spec, resource.Spec) {
-			// Specs aren't equal, update and fix.
-			o.Log.Info("An OCMAgent network policy exists but contains unexpected configuration. Restoring.")
-			foundResource.Spec = *resource.Spec.DeepCopy()
-			if err = o.Client.Update(context.TODO(), foundResource); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	resource := buildNetworkPolicy(ocmAgent, o.Config.Get().AllowedIngressNamespaces)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		cur := current.(*netv1.NetworkPolicy)
+		drift := fieldDrift{}
+		drift.derivative("spec.podSelector", resource.Spec.PodSelector, cur.Spec.PodSelector)
+		drift.derivative("spec.ingress", resource.Spec.Ingress, cur.Spec.Ingress)
+		drift.derivative("spec.policyTypes", resource.Spec.PolicyTypes, cur.Spec.PolicyTypes)
+		return drift
+	})
 }
 
 func (o *ocmAgentHandler) ensureNetworkPolicyDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	var namespacedName types.NamespacedName
-	if ocmAgent.Spec.FleetMode {
-		namespacedName = oah.BuildNamespacedName(ocmAgent.Name + oah.OCMFleetAgentNetworkPolicySuffix)
-	} else {
-		namespacedName = oah.BuildNamespacedName(ocmAgent.Name + oah.OCMAgentNetworkPolicySuffix)
-	}
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, buildNetworkPolicyName(ocmAgent))
 	foundResource := &netv1.NetworkPolicy{}
 	// Does the resource already exist?
 	o.Log.Info("ensuring networkpolicy removed", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			// Return unexpected error
 			return err
@@ -125,7 +86,7 @@ func (o *ocmAgentHandler) ensureNetworkPolicyDeleted(ocmAgent ocmagentv1alpha1.O
 			return nil
 		}
 	}
-	err := o.Client.Delete(o.Ctx, foundResource)
+	err := o.deleteResource(ocmAgent, foundResource)
 	if err != nil {
 		return err
 	}
@@ -11,11 +11,13 @@ import (
 
 	netv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
 	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
 	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
 
 	. "github.com/onsi/ginkgo"
@@ -38,18 +40,19 @@ var _ = Describe("OCM Agent NetworkPolicy Handler", func() {
 		testOcmAgent = testconst.TestOCMAgent
 		testHSOcmAgent = testconst.TestHSOCMAgent
 		testOcmAgentHandler = ocmAgentHandler{
-			Client: mockClient,
-			Log:    testconst.Logger,
-			Ctx:    testconst.Context,
-			Scheme: testconst.Scheme,
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
 		}
 	})
 
 	Context("When building an OCM Agent NetworkPolicy", func() {
 		var np, nph netv1.NetworkPolicy
 		BeforeEach(func() {
-			np = buildNetworkPolicy(testOcmAgent)
-			nph = buildNetworkPolicy(testHSOcmAgent)
+			np = buildNetworkPolicy(testOcmAgent, operatorconfig.AllowedIngressNamespacesDefault)
+			nph = buildNetworkPolicy(testHSOcmAgent, operatorconfig.AllowedIngressNamespacesDefault)
 		})
 		It("Has the expected name and namespace", func() {
 			Expect(np.Name).To(Equal(testOcmAgent.Name + oah.OCMAgentNetworkPolicySuffix))
@@ -63,12 +66,14 @@ var _ = Describe("OCM Agent NetworkPolicy Handler", func() {
 		var testNetworkPolicy, testHSNetworkPolicy netv1.NetworkPolicy
 		var testNamespacedName, testHSNamespacedName types.NamespacedName
 		BeforeEach(func() {
-			testNetworkPolicy = buildNetworkPolicy(testOcmAgent)
+			testNetworkPolicy = buildNetworkPolicy(testOcmAgent, operatorconfig.AllowedIngressNamespacesDefault)
+			withInstanceLabel(&testNetworkPolicy, testOcmAgent)
 			testNamespacedName = types.NamespacedName{
 				Namespace: testNetworkPolicy.Namespace,
 				Name:      testNetworkPolicy.Name,
 			}
-			testHSNetworkPolicy = buildNetworkPolicy(testHSOcmAgent)
+			testHSNetworkPolicy = buildNetworkPolicy(testHSOcmAgent, operatorconfig.AllowedIngressNamespacesDefault)
+			withInstanceLabel(&testHSNetworkPolicy, testHSOcmAgent)
 			testHSNamespacedName = types.NamespacedName{
 				Namespace: testHSNetworkPolicy.Namespace,
 				Name:      testHSNetworkPolicy.Name,
@@ -81,11 +86,11 @@ var _ = Describe("OCM Agent NetworkPolicy Handler", func() {
 					testHSNetworkPolicy.Spec.PodSelector.MatchLabels = map[string]string{"fake": "fake"}
 				})
 				It("updates the networkpolicy", func() {
-					goldenNetworkPolicy := buildNetworkPolicy(testOcmAgent)
+					goldenNetworkPolicy := buildNetworkPolicy(testOcmAgent, operatorconfig.AllowedIngressNamespacesDefault)
 					gomock.InOrder(
 						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).SetArg(2, testNetworkPolicy),
-						mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
-							func(ctx context.Context, d *netv1.NetworkPolicy, opts ...client.UpdateOptions) error {
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+							func(ctx context.Context, d *netv1.NetworkPolicy, patch client.Patch, opts ...client.PatchOption) error {
 								Expect(reflect.DeepEqual(d.Spec, goldenNetworkPolicy.Spec)).To(BeTrue())
 								return nil
 							}),
@@ -94,11 +99,11 @@ var _ = Describe("OCM Agent NetworkPolicy Handler", func() {
 					Expect(err).To(BeNil())
 				})
 				It("updates the fleet OA networkpolicy", func() {
-					goldenNetworkPolicy := buildNetworkPolicy(testHSOcmAgent)
+					goldenNetworkPolicy := buildNetworkPolicy(testHSOcmAgent, operatorconfig.AllowedIngressNamespacesDefault)
 					gomock.InOrder(
 						mockClient.EXPECT().Get(gomock.Any(), testHSNamespacedName, gomock.Any()).SetArg(2, testHSNetworkPolicy),
-						mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
-							func(ctx context.Context, d *netv1.NetworkPolicy, opts ...client.UpdateOptions) error {
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+							func(ctx context.Context, d *netv1.NetworkPolicy, patch client.Patch, opts ...client.PatchOption) error {
 								Expect(reflect.DeepEqual(d.Spec, goldenNetworkPolicy.Spec)).To(BeTrue())
 								return nil
 							}),
@@ -107,6 +112,23 @@ var _ = Describe("OCM Agent NetworkPolicy Handler", func() {
 					Expect(err).To(BeNil())
 				})
 			})
+			When("other ingress namespaces are configured", func() {
+				It("allows ingress from those namespaces in fleet mode", func() {
+					cfg := operatorconfig.Default()
+					cfg.AllowedIngressNamespaces = []string{"observatorium-mst-stage"}
+					testOcmAgentHandler.Config = operatorconfig.NewStore(cfg)
+					gomock.InOrder(
+						mockClient.EXPECT().Get(gomock.Any(), testHSNamespacedName, gomock.Any()).SetArg(2, testHSNetworkPolicy),
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+							func(ctx context.Context, d *netv1.NetworkPolicy, patch client.Patch, opts ...client.PatchOption) error {
+								Expect(d.Spec.Ingress[0].From[0].NamespaceSelector.MatchExpressions[0].Values).To(ConsistOf("observatorium-mst-stage"))
+								return nil
+							}),
+					)
+					err := testOcmAgentHandler.ensureNetworkPolicy(testHSOcmAgent)
+					Expect(err).To(BeNil())
+				})
+			})
 			When("the networkpolicy matches what is expected", func() {
 				It("does not update the networkpolicy", func() {
 					gomock.InOrder(
@@ -130,8 +152,8 @@ var _ = Describe("OCM Agent NetworkPolicy Handler", func() {
 				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testNetworkPolicy.Name)
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Return(notFound),
-					mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
-						func(ctx context.Context, d *netv1.NetworkPolicy, opts ...client.CreateOptions) error {
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, d *netv1.NetworkPolicy, patch client.Patch, opts ...client.PatchOption) error {
 							Expect(reflect.DeepEqual(d.Spec, testNetworkPolicy.Spec)).To(BeTrue())
 							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
 							Expect(*d.ObjectMeta.OwnerReferences[0].BlockOwnerDeletion).To(BeTrue())
@@ -146,8 +168,8 @@ var _ = Describe("OCM Agent NetworkPolicy Handler", func() {
 				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testHSNetworkPolicy.Name)
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), testHSNamespacedName, gomock.Any()).Return(notFound),
-					mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
-						func(ctx context.Context, d *netv1.NetworkPolicy, opts ...client.CreateOptions) error {
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, d *netv1.NetworkPolicy, patch client.Patch, opts ...client.PatchOption) error {
 							Expect(reflect.DeepEqual(d.Spec, testHSNetworkPolicy.Spec)).To(BeTrue())
 							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
 							Expect(*d.ObjectMeta.OwnerReferences[0].BlockOwnerDeletion).To(BeTrue())
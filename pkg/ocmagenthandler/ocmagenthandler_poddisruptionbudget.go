@@ -0,0 +1,80 @@
+package ocmagenthandler
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+// multiReplica returns true if the OCM Agent runs, or may be scaled to, more than one replica
+func multiReplica(ocmAgent ocmagentv1alpha1.OcmAgent) bool {
+	if ocmAgent.Spec.Autoscaling != nil {
+		return ocmAgent.Spec.Autoscaling.MaxReplicas > 1
+	}
+	return ocmAgent.Spec.Replicas > 1
+}
+
+func buildOCMAgentPodDisruptionBudget(ocmAgent ocmagentv1alpha1.OcmAgent) policyv1.PodDisruptionBudget {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	labels := map[string]string{
+		"app": ocmAgent.Name,
+	}
+	maxUnavailable := intstr.FromInt(oah.OCMAgentPDBMaxUnavailable)
+	pdb := policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+	return pdb
+}
+
+// ensurePodDisruptionBudget ensures that an OCMAgent PodDisruptionBudget exists on the cluster
+// while the OCM Agent runs more than one replica, and that it is removed otherwise.
+func (o *ocmAgentHandler) ensurePodDisruptionBudget(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	if !multiReplica(ocmAgent) {
+		return o.ensurePodDisruptionBudgetDeleted(ocmAgent)
+	}
+	resource := buildOCMAgentPodDisruptionBudget(ocmAgent)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		cur := current.(*policyv1.PodDisruptionBudget)
+		drift := fieldDrift{}
+		drift.subset("metadata.labels", resource.Labels, cur.Labels)
+		drift.equal("spec.maxUnavailable", resource.Spec.MaxUnavailable, cur.Spec.MaxUnavailable)
+		drift.derivative("spec.selector", resource.Spec.Selector, cur.Spec.Selector)
+		return drift
+	})
+}
+
+func (o *ocmAgentHandler) ensurePodDisruptionBudgetDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	foundResource := &policyv1.PodDisruptionBudget{}
+	// Does the resource already exist?
+	o.Log.Info("ensuring poddisruptionbudget removed", "resource", namespacedName.String())
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			// Return unexpected error
+			return err
+		} else {
+			// Resource deleted
+			return nil
+		}
+	}
+	err := o.deleteResource(ocmAgent, foundResource)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package ocmagenthandler
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCM Agent PodDisruptionBudget Handler", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testNamespacedName  types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = *testconst.TestOCMAgent.DeepCopy()
+		testOcmAgent.Spec.Replicas = 3
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
+		}
+		testNamespacedName = oah.BuildNamespacedName(testOcmAgent.Name)
+	})
+
+	Context("When building an OCM Agent PodDisruptionBudget", func() {
+		It("selects the OCM Agent pods", func() {
+			pdb := buildOCMAgentPodDisruptionBudget(testOcmAgent)
+			Expect(pdb.Name).To(Equal(testOcmAgent.Name))
+			Expect(pdb.Spec.Selector.MatchLabels).To(HaveKeyWithValue("app", testOcmAgent.Name))
+			Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(oah.OCMAgentPDBMaxUnavailable))
+		})
+	})
+
+	Context("When building an OCM Agent Deployment", func() {
+		It("spreads the pods when running more than one replica", func() {
+			deployment := buildOCMAgentDeployment(testOcmAgent)
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Affinity.PodAntiAffinity).NotTo(BeNil())
+			Expect(podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm.TopologyKey).To(Equal(corev1.LabelHostname))
+			Expect(podSpec.TopologySpreadConstraints).To(HaveLen(1))
+			Expect(podSpec.TopologySpreadConstraints[0].TopologyKey).To(Equal(corev1.LabelTopologyZone))
+		})
+		It("does not spread the pods when running one replica", func() {
+			testOcmAgent.Spec.Replicas = 1
+			deployment := buildOCMAgentDeployment(testOcmAgent)
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Affinity.PodAntiAffinity).To(BeNil())
+			Expect(podSpec.TopologySpreadConstraints).To(BeEmpty())
+		})
+		It("does not override configured placement", func() {
+			testOcmAgent.Spec.Affinity = &corev1.Affinity{}
+			testOcmAgent.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{}
+			deployment := buildOCMAgentDeployment(testOcmAgent)
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Affinity.PodAntiAffinity).To(BeNil())
+			Expect(podSpec.TopologySpreadConstraints).To(BeEmpty())
+		})
+	})
+
+	Context("Managing the OCM Agent PodDisruptionBudget", func() {
+		When("the OCM Agent runs more than one replica", func() {
+			It("creates the PodDisruptionBudget", func() {
+				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testOcmAgent.Name)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *policyv1.PodDisruptionBudget, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(d.Spec.MaxUnavailable).NotTo(BeNil())
+							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensurePodDisruptionBudget(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+			It("does not update a PodDisruptionBudget that matches what is expected", func() {
+				testPDB := buildOCMAgentPodDisruptionBudget(testOcmAgent)
+				withInstanceLabel(&testPDB, testOcmAgent)
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testPDB)
+				err := testOcmAgentHandler.ensurePodDisruptionBudget(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+		When("the OCM Agent is scaled back to one replica", func() {
+			BeforeEach(func() {
+				testOcmAgent.Spec.Replicas = 1
+			})
+			It("removes the PodDisruptionBudget", func() {
+				testPDB := buildOCMAgentPodDisruptionBudget(testOcmAgent)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testPDB),
+					mockClient.EXPECT().Delete(gomock.Any(), &testPDB),
+				)
+				err := testOcmAgentHandler.ensurePodDisruptionBudget(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+			It("does nothing if the PodDisruptionBudget is already removed", func() {
+				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testOcmAgent.Name)
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound)
+				err := testOcmAgentHandler.ensurePodDisruptionBudget(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+})
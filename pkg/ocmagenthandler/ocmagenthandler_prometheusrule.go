@@ -0,0 +1,153 @@
+package ocmagenthandler
+
+import (
+	"fmt"
+	"time"
+
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+)
+
+// buildPrometheusRule returns the PrometheusRule holding the alerts on the health of the
+// OCM Agent and of the operator managing it, with the supplied thresholds. The pull secret
+// is only used in cluster mode and the fleet notification records only exist in fleet mode,
+// so their alerts are only set in those modes.
+func buildPrometheusRule(ocmAgent ocmagentv1alpha1.OcmAgent, thresholds operatorconfig.AlertThresholds) monitorv1.PrometheusRule {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name+oah.PrometheusRuleSuffix)
+	labels := map[string]string{
+		"app": ocmAgent.Name,
+	}
+
+	// An empty range isn't valid in PromQL
+	reconcileErrorWindow := thresholds.ReconcileErrorWindow
+	if reconcileErrorWindow <= 0 {
+		reconcileErrorWindow = operatorconfig.ReconcileErrorWindowDefault
+	}
+
+	var rules []monitorv1.Rule
+	if !ocmAgent.Spec.FleetMode {
+		rules = append(rules, monitorv1.Rule{
+			Alert: "OCMAgentPullSecretInvalid",
+			Expr:  intstr.FromString(fmt.Sprintf(`ocm_agent_operator_pull_secret_invalid{ocmagent_name="%s"} == 1`, ocmAgent.Name)),
+			For:   promDuration(thresholds.PullSecretInvalidFor),
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "The OCM Agent can't obtain its access token",
+				"description": "The cloud.openshift.com auth of the cluster pull secret is missing or invalid, so the OCM Agent can't send service logs.",
+			},
+		})
+	}
+	rules = append(rules,
+		monitorv1.Rule{
+			Alert: "OCMAgentUnavailable",
+			Expr:  intstr.FromString(fmt.Sprintf(`kube_deployment_status_replicas_available{namespace="%s",deployment="%s"} == 0`, namespacedName.Namespace, ocmAgent.Name)),
+			For:   promDuration(thresholds.AgentUnavailableFor),
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "The OCM Agent has no available replicas",
+				"description": fmt.Sprintf("The OCM Agent deployment %s has no available replicas, so no service logs are sent.", client.ObjectKey{Namespace: namespacedName.Namespace, Name: ocmAgent.Name}.String()),
+			},
+		},
+		monitorv1.Rule{
+			Alert: "OCMAgentReconcileFailing",
+			Expr: intstr.FromString(fmt.Sprintf(`increase(ocm_agent_operator_handler_errors_total{ocmagent_name="%s"}[%s]) >= %d`,
+				ocmAgent.Name, promDuration(reconcileErrorWindow), thresholds.ReconcileErrors)),
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "The OCM Agent Operator keeps failing to reconcile the OCM Agent",
+				"description": fmt.Sprintf("The OCM Agent Operator failed {{ $value }} operations on the resources of the OcmAgent %s within %s.", ocmAgent.Name, promDuration(reconcileErrorWindow)),
+			},
+		},
+	)
+	if ocmAgent.Spec.FleetMode {
+		rules = append(rules, monitorv1.Rule{
+			Alert: "OCMAgentFleetRecordsStale",
+			Expr: intstr.FromString(fmt.Sprintf(`ocm_agent_operator_last_successful_reconcile_age_seconds{controller="fleetnotification"} > %d`,
+				int64(thresholds.FleetRecordsStaleFor.Seconds()))),
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "The fleet notification records are not pruned",
+				"description": fmt.Sprintf("The ManagedFleetNotificationRecords were not reconciled successfully for more than %s, so their stale items are kept.", promDuration(thresholds.FleetRecordsStaleFor)),
+			},
+		})
+	}
+
+	return monitorv1.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+			Labels:    labels,
+		},
+		Spec: monitorv1.PrometheusRuleSpec{
+			Groups: []monitorv1.RuleGroup{{
+				Name:  ocmAgent.Name + ".rules",
+				Rules: rules,
+			}},
+		},
+	}
+}
+
+// promDuration formats the duration in the largest whole unit that Prometheus accepts,
+// as the output of time.Duration.String, such as 15m0s, isn't valid in a PromQL range.
+// Prometheus durations have no unit below the second, so a sub-second duration is rounded
+// up to the next second, and a duration that isn't positive is formatted as 0s.
+func promDuration(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
+	if rem := d % time.Second; rem != 0 {
+		d += time.Second - rem
+	}
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
+
+// ensurePrometheusRule ensures that the OCMAgent PrometheusRule exists on the cluster
+// and that its alerts match the configured thresholds
+func (o *ocmAgentHandler) ensurePrometheusRule(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	resource := buildPrometheusRule(ocmAgent, o.Config.Get().Alerts)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		cur := current.(*monitorv1.PrometheusRule)
+		drift := fieldDrift{}
+		drift.subset("metadata.labels", resource.Labels, cur.Labels)
+		drift.equal("spec.groups", resource.Spec.Groups, cur.Spec.Groups)
+		return drift
+	})
+}
+
+func (o *ocmAgentHandler) ensurePrometheusRuleDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name+oah.PrometheusRuleSuffix)
+	foundResource := &monitorv1.PrometheusRule{}
+	// Does the resource already exist?
+	o.Log.Info("ensuring prometheusrule removed", "resource", namespacedName.String())
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			// Return unexpected error
+			return err
+		}
+		// Resource deleted
+		return nil
+	}
+	return o.deleteResource(ocmAgent, foundResource)
+}
@@ -0,0 +1,208 @@
+package ocmagenthandler
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// alertNames returns the names of the alerts of the PrometheusRule
+func alertNames(rule monitorv1.PrometheusRule) []string {
+	var names []string
+	for _, group := range rule.Spec.Groups {
+		for _, r := range group.Rules {
+			names = append(names, r.Alert)
+		}
+	}
+	return names
+}
+
+// findAlert returns the rule of the named alert of the PrometheusRule
+func findAlert(rule monitorv1.PrometheusRule, name string) monitorv1.Rule {
+	for _, group := range rule.Spec.Groups {
+		for _, r := range group.Rules {
+			if r.Alert == name {
+				return r
+			}
+		}
+	}
+	return monitorv1.Rule{}
+}
+
+var _ = Describe("OCM Agent PrometheusRule Handler", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testThresholds      operatorconfig.AlertThresholds
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = ocmagentv1alpha1.OcmAgent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-ocm-agent",
+			},
+			Spec:   ocmagentv1alpha1.OcmAgentSpec{},
+			Status: ocmagentv1alpha1.OcmAgentStatus{},
+		}
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
+		}
+		testThresholds = operatorconfig.Default().Alerts
+	})
+
+	Context("When building an OCM Agent PrometheusRule", func() {
+		It("Sets a correct name", func() {
+			rule := buildPrometheusRule(testOcmAgent, testThresholds)
+			Expect(rule.Name).To(Equal(testOcmAgent.Name + oah.PrometheusRuleSuffix))
+			Expect(rule.Namespace).To(Equal(oah.OCMAgentNamespace))
+		})
+		It("alerts on the pull secret in cluster mode", func() {
+			rule := buildPrometheusRule(testOcmAgent, testThresholds)
+			Expect(alertNames(rule)).To(ConsistOf("OCMAgentPullSecretInvalid", "OCMAgentUnavailable", "OCMAgentReconcileFailing"))
+		})
+		It("alerts on the fleet records in fleet mode", func() {
+			testOcmAgent.Spec.FleetMode = true
+			rule := buildPrometheusRule(testOcmAgent, testThresholds)
+			Expect(alertNames(rule)).To(ConsistOf("OCMAgentFleetRecordsStale", "OCMAgentUnavailable", "OCMAgentReconcileFailing"))
+		})
+		It("uses the configured thresholds", func() {
+			testOcmAgent.Spec.FleetMode = true
+			testThresholds.AgentUnavailableFor = 90 * time.Second
+			testThresholds.ReconcileErrors = 2
+			testThresholds.ReconcileErrorWindow = time.Hour
+			testThresholds.FleetRecordsStaleFor = 6 * time.Hour
+			rule := buildPrometheusRule(testOcmAgent, testThresholds)
+			Expect(findAlert(rule, "OCMAgentUnavailable").For).To(Equal("90s"))
+			Expect(findAlert(rule, "OCMAgentUnavailable").Expr.StrVal).To(Equal(
+				`kube_deployment_status_replicas_available{namespace="openshift-ocm-agent-operator",deployment="test-ocm-agent"} == 0`))
+			Expect(findAlert(rule, "OCMAgentReconcileFailing").Expr.StrVal).To(Equal(
+				`increase(ocm_agent_operator_handler_errors_total{ocmagent_name="test-ocm-agent"}[1h]) >= 2`))
+			Expect(findAlert(rule, "OCMAgentFleetRecordsStale").Expr.StrVal).To(Equal(
+				`ocm_agent_operator_last_successful_reconcile_age_seconds{controller="fleetnotification"} > 21600`))
+		})
+	})
+
+	Context("When formatting a duration for Prometheus", func() {
+		It("uses the largest whole unit", func() {
+			Expect(promDuration(2 * time.Hour)).To(Equal("2h"))
+			Expect(promDuration(90 * time.Minute)).To(Equal("90m"))
+			Expect(promDuration(90 * time.Second)).To(Equal("90s"))
+		})
+		It("rounds a sub-second duration up to the next second", func() {
+			Expect(promDuration(500 * time.Millisecond)).To(Equal("1s"))
+			Expect(promDuration(time.Minute - time.Millisecond)).To(Equal("1m"))
+			Expect(promDuration(time.Minute + time.Millisecond)).To(Equal("61s"))
+		})
+		It("formats a duration that isn't positive as 0s", func() {
+			Expect(promDuration(0)).To(Equal("0s"))
+			Expect(promDuration(-time.Minute)).To(Equal("0s"))
+		})
+		It("doesn't alert on an empty reconcile error window", func() {
+			testThresholds.ReconcileErrorWindow = 0
+			rule := buildPrometheusRule(testOcmAgent, testThresholds)
+			Expect(findAlert(rule, "OCMAgentReconcileFailing").Expr.StrVal).To(ContainSubstring("[30m]"))
+		})
+	})
+
+	Context("Managing the OCM Agent PrometheusRule", func() {
+		var testPrometheusRule monitorv1.PrometheusRule
+		var testNamespacedName types.NamespacedName
+		BeforeEach(func() {
+			testNamespacedName = oah.BuildNamespacedName(testOcmAgent.Name + oah.PrometheusRuleSuffix)
+			testPrometheusRule = buildPrometheusRule(testOcmAgent, testThresholds)
+			withInstanceLabel(&testPrometheusRule, testOcmAgent)
+		})
+		When("the OCM Agent PrometheusRule already exists", func() {
+			When("the thresholds of the PrometheusRule differ from the configured thresholds", func() {
+				It("updates the PrometheusRule", func() {
+					cfg := operatorconfig.Default()
+					cfg.Alerts.PullSecretInvalidFor = time.Hour
+					testOcmAgentHandler.Config = operatorconfig.NewStore(cfg)
+					goldenRule := buildPrometheusRule(testOcmAgent, cfg.Alerts)
+					gomock.InOrder(
+						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testPrometheusRule),
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *monitorv1.PrometheusRule, patch client.Patch, opts ...client.PatchOption) error {
+								Expect(reflect.DeepEqual(d.Spec, goldenRule.Spec)).To(BeTrue())
+								Expect(findAlert(*d, "OCMAgentPullSecretInvalid").For).To(Equal("1h"))
+								return nil
+							}),
+					)
+					err := testOcmAgentHandler.ensurePrometheusRule(testOcmAgent)
+					Expect(err).To(BeNil())
+				})
+			})
+			When("the PrometheusRule matches what is expected", func() {
+				It("does not update the PrometheusRule", func() {
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testPrometheusRule)
+					err := testOcmAgentHandler.ensurePrometheusRule(testOcmAgent)
+					Expect(err).To(BeNil())
+				})
+			})
+		})
+		When("the OCM Agent PrometheusRule does not already exist", func() {
+			It("creates the PrometheusRule", func() {
+				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testPrometheusRule.Name)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *monitorv1.PrometheusRule, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(reflect.DeepEqual(d.Spec, testPrometheusRule.Spec)).To(BeTrue())
+							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensurePrometheusRule(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+		When("the OCM Agent PrometheusRule should be removed", func() {
+			When("the PrometheusRule is already removed", func() {
+				It("does nothing", func() {
+					notFound := k8serrs.NewNotFound(schema.GroupResource{}, testPrometheusRule.Name)
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound)
+					err := testOcmAgentHandler.ensurePrometheusRuleDeleted(testOcmAgent)
+					Expect(err).To(BeNil())
+				})
+			})
+			When("the PrometheusRule exists on the cluster", func() {
+				It("removes the PrometheusRule", func() {
+					gomock.InOrder(
+						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testPrometheusRule),
+						mockClient.EXPECT().Delete(gomock.Any(), &testPrometheusRule),
+					)
+					err := testOcmAgentHandler.ensurePrometheusRuleDeleted(testOcmAgent)
+					Expect(err).To(BeNil())
+				})
+			})
+		})
+	})
+})
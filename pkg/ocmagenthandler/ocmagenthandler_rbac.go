@@ -0,0 +1,181 @@
+package ocmagenthandler
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+)
+
+func buildOCMAgentServiceAccount(ocmAgent ocmagentv1alpha1.OcmAgent) corev1.ServiceAccount {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	return corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+			Labels:    map[string]string{"app": ocmAgent.Name},
+		},
+	}
+}
+
+// buildOCMAgentRoleRules returns the permissions the OCM Agent needs in the mode it runs in.
+// In fleet mode the agent records the notifications it sends for each hosted cluster, and
+// otherwise it records the notifications it sends in the ManagedNotification status.
+func buildOCMAgentRoleRules(ocmAgent ocmagentv1alpha1.OcmAgent) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{{
+		APIGroups: []string{corev1.GroupName},
+		Resources: []string{"services", "services/finalizers", "configmaps", "secrets"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	}}
+	if ocmAgent.Spec.FleetMode {
+		return append(rules,
+			rbacv1.PolicyRule{
+				APIGroups: []string{ocmagentv1alpha1.GroupVersion.Group},
+				Resources: []string{"managedfleetnotifications"},
+				Verbs:     []string{"get", "list", "watch", "patch", "update"},
+			},
+			rbacv1.PolicyRule{
+				APIGroups: []string{ocmagentv1alpha1.GroupVersion.Group},
+				Resources: []string{"managedfleetnotificationrecords", "managedfleetnotificationrecords/status"},
+				Verbs:     []string{"get", "list", "watch", "patch", "update", "create"},
+			},
+		)
+	}
+	return append(rules, rbacv1.PolicyRule{
+		APIGroups: []string{ocmagentv1alpha1.GroupVersion.Group},
+		Resources: []string{"managednotifications", "managednotifications/status"},
+		Verbs:     []string{"get", "list", "watch", "patch", "update"},
+	})
+}
+
+// managedKindVerbs are the verbs the handler uses on the resources of the managedKinds
+var managedKindVerbs = []string{"create", "delete", "get", "list", "patch"}
+
+// TargetNamespaceRules returns the permissions the operator needs in the target namespace of
+// the OcmAgent: those to manage the resources of the managedKinds that the cluster serves, and
+// those of the OCM Agent Role, which the operator can only grant while it holds them.
+func TargetNamespaceRules(mapper meta.RESTMapper, ocmAgent ocmagentv1alpha1.OcmAgent) ([]rbacv1.PolicyRule, error) {
+	var rules []rbacv1.PolicyRule
+	for _, gvk := range managedKinds {
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			// The ServiceMonitor and PrometheusRule kinds aren't served on clusters without the Prometheus Operator
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{gvk.Group},
+			Resources: []string{mapping.Resource.Resource},
+			Verbs:     managedKindVerbs,
+		})
+	}
+	return append(rules, buildOCMAgentRoleRules(ocmAgent)...), nil
+}
+
+func buildOCMAgentRole(ocmAgent ocmagentv1alpha1.OcmAgent) rbacv1.Role {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	return rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+			Labels:    map[string]string{"app": ocmAgent.Name},
+		},
+		Rules: buildOCMAgentRoleRules(ocmAgent),
+	}
+}
+
+func buildOCMAgentRoleBinding(ocmAgent ocmagentv1alpha1.OcmAgent) rbacv1.RoleBinding {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	return rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+			Labels:    map[string]string{"app": ocmAgent.Name},
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+		}},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     namespacedName.Name,
+		},
+	}
+}
+
+// ensureServiceAccount ensures that the ServiceAccount that runs the OCM Agent exists on the cluster
+func (o *ocmAgentHandler) ensureServiceAccount(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	resource := buildOCMAgentServiceAccount(ocmAgent)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		drift := fieldDrift{}
+		drift.subset("metadata.labels", resource.Labels, current.GetLabels())
+		return drift
+	})
+}
+
+// ensureRole ensures that the Role granting the permissions of the mode the OCM Agent runs in
+// exists on the cluster, and that it grants no other permissions.
+func (o *ocmAgentHandler) ensureRole(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	resource := buildOCMAgentRole(ocmAgent)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		cur := current.(*rbacv1.Role)
+		drift := fieldDrift{}
+		drift.subset("metadata.labels", resource.Labels, cur.Labels)
+		drift.equal("rules", resource.Rules, cur.Rules)
+		return drift
+	})
+}
+
+// ensureRoleBinding ensures that the RoleBinding of the OCM Agent Role to its ServiceAccount
+// exists on the cluster
+func (o *ocmAgentHandler) ensureRoleBinding(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	resource := buildOCMAgentRoleBinding(ocmAgent)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		cur := current.(*rbacv1.RoleBinding)
+		drift := fieldDrift{}
+		drift.subset("metadata.labels", resource.Labels, cur.Labels)
+		drift.equal("subjects", resource.Subjects, cur.Subjects)
+		drift.equal("roleRef", resource.RoleRef, cur.RoleRef)
+		return drift
+	})
+}
+
+// ensureRBACDeleted removes the RoleBinding, Role and ServiceAccount of the OCM Agent
+func (o *ocmAgentHandler) ensureRBACDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
+	for _, resource := range []client.Object{&rbacv1.RoleBinding{}, &rbacv1.Role{}, &corev1.ServiceAccount{}} {
+		if err := o.ensureRBACResourceDeleted(ocmAgent, namespacedName, resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *ocmAgentHandler) ensureRBACResourceDeleted(ocmAgent ocmagentv1alpha1.OcmAgent, namespacedName types.NamespacedName, foundResource client.Object) error {
+	// Does the resource already exist?
+	o.Log.Info("ensuring rbac resource removed", "resource", namespacedName.String())
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			// Return unexpected error
+			return err
+		} else {
+			// Resource deleted
+			return nil
+		}
+	}
+	err := o.deleteResource(ocmAgent, foundResource)
+	if err != nil {
+		return err
+	}
+	return nil
+}
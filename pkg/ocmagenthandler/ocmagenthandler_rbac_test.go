@@ -0,0 +1,235 @@
+package ocmagenthandler
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCM Agent RBAC Handler", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+		testNamespacedName  types.NamespacedName
+	)
+
+	// ruleResources returns the resources granted by the rules of the ocmagent API group
+	ruleResources := func(rules []rbacv1.PolicyRule) []string {
+		var resources []string
+		for _, rule := range rules {
+			if rule.APIGroups[0] == ocmagentv1alpha1.GroupVersion.Group {
+				resources = append(resources, rule.Resources...)
+			}
+		}
+		return resources
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = *testconst.TestOCMAgent.DeepCopy()
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
+		}
+		testNamespacedName = oah.BuildNamespacedName(testOcmAgent.Name)
+	})
+
+	Context("When building the OCM Agent Role", func() {
+		It("grants access to the ManagedNotification status in cluster mode", func() {
+			role := buildOCMAgentRole(testOcmAgent)
+			Expect(role.Name).To(Equal(testOcmAgent.Name))
+			resources := ruleResources(role.Rules)
+			Expect(resources).To(ContainElements("managednotifications", "managednotifications/status"))
+			Expect(resources).NotTo(ContainElement("managedfleetnotificationrecords"))
+		})
+		It("grants write access to the ManagedFleetNotificationRecords in fleet mode", func() {
+			testOcmAgent.Spec.FleetMode = true
+			role := buildOCMAgentRole(testOcmAgent)
+			resources := ruleResources(role.Rules)
+			Expect(resources).To(ContainElements("managedfleetnotificationrecords", "managedfleetnotificationrecords/status"))
+			Expect(resources).NotTo(ContainElement("managednotifications/status"))
+			for _, rule := range role.Rules {
+				if rule.Resources[0] == "managedfleetnotificationrecords" {
+					Expect(rule.Verbs).To(ContainElements("create", "update", "patch"))
+				}
+			}
+		})
+	})
+
+	Context("When determining the permissions needed in the target namespace", func() {
+		var mapper *meta.DefaultRESTMapper
+		BeforeEach(func() {
+			// The Prometheus Operator kinds aren't served
+			mapper = meta.NewDefaultRESTMapper(nil)
+			for _, gvk := range managedKinds {
+				if gvk.Group != monitorv1.SchemeGroupVersion.Group {
+					mapper.Add(gvk, meta.RESTScopeNamespace)
+				}
+			}
+		})
+		It("requires the permissions to manage the served kinds and those of the OCM Agent Role", func() {
+			rules, err := TargetNamespaceRules(mapper, testOcmAgent)
+			Expect(err).To(BeNil())
+			Expect(rules).To(ContainElement(rbacv1.PolicyRule{
+				APIGroups: []string{"apps"},
+				Resources: []string{"deployments"},
+				Verbs:     []string{"create", "delete", "get", "list", "patch"},
+			}))
+			Expect(rules).To(ContainElements(buildOCMAgentRoleRules(testOcmAgent)))
+			for _, rule := range rules {
+				Expect(rule.APIGroups).NotTo(ContainElement(monitorv1.SchemeGroupVersion.Group))
+			}
+		})
+	})
+
+	Context("When building the OCM Agent RoleBinding", func() {
+		It("binds the OCM Agent Role to the OCM Agent ServiceAccount", func() {
+			roleBinding := buildOCMAgentRoleBinding(testOcmAgent)
+			Expect(roleBinding.RoleRef.Kind).To(Equal("Role"))
+			Expect(roleBinding.RoleRef.Name).To(Equal(testOcmAgent.Name))
+			Expect(roleBinding.Subjects).To(HaveLen(1))
+			Expect(roleBinding.Subjects[0].Kind).To(Equal(rbacv1.ServiceAccountKind))
+			Expect(roleBinding.Subjects[0].Name).To(Equal(testOcmAgent.Name))
+			Expect(roleBinding.Subjects[0].Namespace).To(Equal(testNamespacedName.Namespace))
+		})
+		It("runs the OCM Agent Deployment with the OCM Agent ServiceAccount", func() {
+			deployment := buildOCMAgentDeployment(testOcmAgent)
+			Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(Equal(buildOCMAgentServiceAccount(testOcmAgent).Name))
+		})
+	})
+
+	Context("Managing the OCM Agent Role", func() {
+		var testRole rbacv1.Role
+		BeforeEach(func() {
+			testRole = buildOCMAgentRole(testOcmAgent)
+			withInstanceLabel(&testRole, testOcmAgent)
+		})
+		When("the Role does not exist", func() {
+			It("creates it", func() {
+				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testRole.Name)
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, r *rbacv1.Role, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(r.Rules).To(Equal(testRole.Rules))
+							Expect(r.OwnerReferences[0].Name).To(Equal(testOcmAgent.Name))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensureRole(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+		When("the Role matches what is expected", func() {
+			It("does not update it", func() {
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testRole)
+				err := testOcmAgentHandler.ensureRole(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+		When("the OCM Agent switches mode", func() {
+			It("updates the rules", func() {
+				testOcmAgent.Spec.FleetMode = true
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testRole),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, r *rbacv1.Role, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(ruleResources(r.Rules)).To(ContainElement("managedfleetnotificationrecords"))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensureRole(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Context("Managing the OCM Agent RoleBinding", func() {
+		var testRoleBinding rbacv1.RoleBinding
+		BeforeEach(func() {
+			testRoleBinding = buildOCMAgentRoleBinding(testOcmAgent)
+			withInstanceLabel(&testRoleBinding, testOcmAgent)
+		})
+		When("the RoleBinding matches what is expected", func() {
+			It("does not update it", func() {
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testRoleBinding)
+				err := testOcmAgentHandler.ensureRoleBinding(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+		When("the RoleBinding has extra subjects", func() {
+			It("restores the subjects", func() {
+				testRoleBinding.Subjects = append(testRoleBinding.Subjects, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "default"})
+				gomock.InOrder(
+					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testRoleBinding),
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, rb *rbacv1.RoleBinding, patch client.Patch, opts ...client.PatchOption) error {
+							Expect(rb.Subjects).To(HaveLen(1))
+							return nil
+						}),
+				)
+				err := testOcmAgentHandler.ensureRoleBinding(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Context("Managing the OCM Agent ServiceAccount", func() {
+		When("the ServiceAccount exists", func() {
+			It("does not update it", func() {
+				testServiceAccount := buildOCMAgentServiceAccount(testOcmAgent)
+				withInstanceLabel(&testServiceAccount, testOcmAgent)
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testServiceAccount)
+				err := testOcmAgentHandler.ensureServiceAccount(testOcmAgent)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Context("When removing the OCM Agent RBAC resources", func() {
+		It("removes the RoleBinding, Role and ServiceAccount", func() {
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.AssignableToTypeOf(&rbacv1.RoleBinding{})).Return(nil),
+				mockClient.EXPECT().Delete(gomock.Any(), gomock.AssignableToTypeOf(&rbacv1.RoleBinding{})).Return(nil),
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.AssignableToTypeOf(&rbacv1.Role{})).Return(nil),
+				mockClient.EXPECT().Delete(gomock.Any(), gomock.AssignableToTypeOf(&rbacv1.Role{})).Return(nil),
+				mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.AssignableToTypeOf(&corev1.ServiceAccount{})).Return(nil),
+				mockClient.EXPECT().Delete(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ServiceAccount{})).Return(nil),
+			)
+			err := testOcmAgentHandler.ensureRBACDeleted(testOcmAgent)
+			Expect(err).To(BeNil())
+		})
+		It("skips resources that are already removed", func() {
+			notFound := k8serrs.NewNotFound(schema.GroupResource{}, testNamespacedName.Name)
+			mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(3).Return(notFound)
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.ensureRBACDeleted(testOcmAgent)
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -3,12 +3,11 @@ package ocmagenthandler
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
@@ -16,7 +15,7 @@ import (
 )
 
 func buildOCMAgentAccessTokenSecret(accessToken []byte, ocmAgent ocmagentv1alpha1.OcmAgent) corev1.Secret {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Spec.TokenSecret)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Spec.TokenSecret)
 	secret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      namespacedName.Name,
@@ -32,60 +31,26 @@ func buildOCMAgentAccessTokenSecret(accessToken []byte, ocmAgent ocmagentv1alpha
 // ensureAccessTokenSecret ensures that an OCMAgent Secret exists on the cluster
 // and that its configuration matches what is expected.
 func (o *ocmAgentHandler) ensureAccessTokenSecret(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Spec.TokenSecret)
-	foundResource := &corev1.Secret{}
-
 	clusterPullSecret, err := o.fetchAccessTokenPullSecret()
 	if err != nil {
 		localmetrics.UpdateMetricPullSecretInvalid(ocmAgent.Name)
 		return err
 	}
 	localmetrics.ResetMetricPullSecretInvalid(ocmAgent.Name)
-	populationFunc := func() corev1.Secret {
-		return buildOCMAgentAccessTokenSecret(clusterPullSecret, ocmAgent)
-	}
-	// Does the resource already exist?
-	o.Log.Info("ensuring secret exists", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
-		if k8serrors.IsNotFound(err) {
-			// It does not exist, so must be created.
-			o.Log.Info("An OCMAgent access token secret does not exist; will be created.")
-			// Populate the resource with the template
-			resource := populationFunc()
-			// Set the controller reference
-			if err := controllerutil.SetControllerReference(&ocmAgent, &resource, o.Scheme); err != nil {
-				return err
-			}
-			// and create it
-			err = o.Client.Create(o.Ctx, &resource)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Return unexpectedly
-			return err
-		}
-	} else {
-		// It does exist, check if it is what we expected
-		resource := populationFunc()
-		if !reflect.DeepEqual(foundResource.Data, resource.Data) {
-			// Specs aren't equal, update and fix.
-			o.Log.Info("An OCMAgent access token secret exists but contains unexpected configuration. Restoring.")
-			foundResource = resource.DeepCopy()
-			if err = o.Client.Update(o.Ctx, foundResource); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	resource := buildOCMAgentAccessTokenSecret(clusterPullSecret, ocmAgent)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		drift := fieldDrift{}
+		drift.equal("data", resource.Data, current.(*corev1.Secret).Data)
+		return drift
+	})
 }
 
 func (o *ocmAgentHandler) ensureFleetClientSecret(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Spec.TokenSecret)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Spec.TokenSecret)
 	foundResource := &corev1.Secret{}
 	// Does the resource already exist?
 	o.Log.Info("ensuring fleetmode secret exists", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
 		if k8serrors.IsNotFound(err) {
 			// It does not exist, so must be created.
 			o.Log.Info("An OCMAgent secret for Hypershift does not exist. Fleet mode OCMAgent will not work as expected")
@@ -99,11 +64,11 @@ func (o *ocmAgentHandler) ensureFleetClientSecret(ocmAgent ocmagentv1alpha1.OcmA
 }
 
 func (o *ocmAgentHandler) ensureAccessTokenSecretDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Spec.TokenSecret)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Spec.TokenSecret)
 	foundResource := &corev1.Secret{}
 	// Does the resource already exist?
 	o.Log.Info("ensuring secret removed", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			// Return unexpected error
 			return err
@@ -112,16 +77,35 @@ func (o *ocmAgentHandler) ensureAccessTokenSecretDeleted(ocmAgent ocmagentv1alph
 			return nil
 		}
 	}
-	err := o.Client.Delete(o.Ctx, foundResource)
+	err := o.deleteResource(ocmAgent, foundResource)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// ensureOwnedAccessTokenSecretDeleted removes the access token secret if it was created for the
+// OcmAgent, leaving a fleet client secret of the same name that was provided by the user in place
+func (o *ocmAgentHandler) ensureOwnedAccessTokenSecretDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Spec.TokenSecret)
+	foundResource := &corev1.Secret{}
+	o.Log.Info("ensuring owned secret removed", "resource", namespacedName.String())
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	// A secret in a target namespace has no owner reference, but is labelled with the OcmAgent
+	if !metav1.IsControlledBy(foundResource, &ocmAgent) && foundResource.Labels[oah.OCMAgentInstanceLabel] != ocmAgent.Name {
+		return nil
+	}
+	return o.deleteResource(ocmAgent, foundResource)
+}
+
 func (o *ocmAgentHandler) fetchAccessTokenPullSecret() ([]byte, error) {
 	foundResource := &corev1.Secret{}
-	if err := o.Client.Get(o.Ctx, oah.PullSecretNamespacedName, foundResource); err != nil {
+	if err := o.reader().Get(o.Ctx, oah.PullSecretNamespacedName, foundResource); err != nil {
 		if k8serrors.IsNotFound(err) {
 			// There should always be a pull secret, log this
 			o.Log.Error(err, "Cluster pull secret was not found on the cluster.")
@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
@@ -53,10 +54,11 @@ var _ = Describe("OCM Agent Access Token Secret Handler", func() {
 		testOcmAgent = testconst.TestOCMAgent
 		testHSOcmAgent = testconst.TestHSOCMAgent
 		testOcmAgentHandler = ocmAgentHandler{
-			Client: mockClient,
-			Log:    testconst.Logger,
-			Ctx:    testconst.Context,
-			Scheme: testconst.Scheme,
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
 		}
 		testClusterPullSecretValue = []byte(fmt.Sprintf(`{
 			"auths": {
@@ -105,6 +107,7 @@ var _ = Describe("OCM Agent Access Token Secret Handler", func() {
 		BeforeEach(func() {
 			testNamespacedName = oahconst.BuildNamespacedName(testOcmAgent.Spec.TokenSecret)
 			testSecret = buildOCMAgentAccessTokenSecret(testOcmAccessTokenSecretValue, testOcmAgent)
+			withInstanceLabel(&testSecret, testOcmAgent)
 			testHSNamespacedName = oahconst.BuildNamespacedName(testHSOcmAgent.Spec.TokenSecret)
 		})
 		When("the OCM Agent secret already exists", func() {
@@ -117,8 +120,8 @@ var _ = Describe("OCM Agent Access Token Secret Handler", func() {
 					gomock.InOrder(
 						mockClient.EXPECT().Get(gomock.Any(), oahconst.PullSecretNamespacedName, gomock.Any()).Times(1).SetArg(2, testPullSecret),
 						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testSecret),
-						mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
-							func(ctx context.Context, d *corev1.Secret, opts ...client.UpdateOptions) error {
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *corev1.Secret, patch client.Patch, opts ...client.PatchOption) error {
 								Expect(d.Data).Should(HaveKey(oahconst.OCMAgentAccessTokenSecretKey))
 								Expect(bytes.Compare(d.Data[oahconst.OCMAgentAccessTokenSecretKey], goldenSecret.Data[oahconst.OCMAgentAccessTokenSecretKey])).To(BeZero())
 								return nil
@@ -154,8 +157,8 @@ var _ = Describe("OCM Agent Access Token Secret Handler", func() {
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), oahconst.PullSecretNamespacedName, gomock.Any()).Times(1).SetArg(2, testPullSecret),
 					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
-					mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
-						func(ctx context.Context, d *corev1.Secret, opts ...client.CreateOptions) error {
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, d *corev1.Secret, patch client.Patch, opts ...client.PatchOption) error {
 							Expect(reflect.DeepEqual(d.Data, testSecret.Data)).To(BeTrue())
 							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
 							Expect(*d.ObjectMeta.OwnerReferences[0].BlockOwnerDeletion).To(BeTrue())
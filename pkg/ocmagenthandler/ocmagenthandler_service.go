@@ -1,23 +1,18 @@
 package ocmagenthandler
 
 import (
-	"fmt"
-	"reflect"
-
-	"github.com/go-logr/logr"
-
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
 )
 
 func buildOCMAgentService(ocmAgent ocmagentv1alpha1.OcmAgent) corev1.Service {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name)
 	labels := map[string]string{
 		"app": ocmAgent.Name,
 	}
@@ -41,7 +36,7 @@ func buildOCMAgentService(ocmAgent ocmagentv1alpha1.OcmAgent) corev1.Service {
 
 func buildOCMAgentMetricsService(ocmAgent ocmagentv1alpha1.OcmAgent) corev1.Service {
 	metricsSVCname := ocmAgent.Name + "-metrics"
-	namespacedName := oah.BuildNamespacedName(metricsSVCname)
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, metricsSVCname)
 	labels := map[string]string{
 		"app": ocmAgent.Name,
 	}
@@ -64,51 +59,19 @@ func buildOCMAgentMetricsService(ocmAgent ocmagentv1alpha1.OcmAgent) corev1.Serv
 	return svc
 }
 
-// ensureConfigMap ensures that an OCMAgent ConfigMap exists on the cluster
-// and that its configuration matches what is expected.
+// ensureService ensures that the OCMAgent Services exist on the cluster
+// and that their configuration matches what is expected.
 func (o *ocmAgentHandler) ensureService(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	foundResource := &corev1.Service{}
-	buildOCMAgentSvcFunc := func() corev1.Service {
-		return buildOCMAgentService(ocmAgent)
-	}
-	buildOCMAgentMetricsSvcFunc := func() corev1.Service {
-		return buildOCMAgentMetricsService(ocmAgent)
-	}
-	oaSvc := buildOCMAgentSvcFunc()
-	oaMetricsSvc := buildOCMAgentMetricsSvcFunc()
+	oaSvc := buildOCMAgentService(ocmAgent)
+	oaMetricsSvc := buildOCMAgentMetricsService(ocmAgent)
 
-	// Does the resource already exist?
 	for _, svc := range []corev1.Service{oaSvc, oaMetricsSvc} {
 		svc := svc //prevent implicit memory aliasing
-		namespacedName := oah.BuildNamespacedName(svc.Name)
-		o.Log.Info("ensuring service exists", "resource", svc.Name)
-		if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
-			if k8serrors.IsNotFound(err) {
-				// It does not exist, so must be created.
-				o.Log.Info("An OCMAgent service does not exist; will be created.")
-
-				// Set the controller reference
-				if err := controllerutil.SetControllerReference(&ocmAgent, &svc, o.Scheme); err != nil {
-					return err
-				}
-				err = o.Client.Create(o.Ctx, &svc)
-				if err != nil {
-					return err
-				}
-			} else {
-				// Return unexpectedly
-				return err
-			}
-		} else {
-			// It does exist, check if it is what we expected
-			if serviceConfigChanged(foundResource, &svc, o.Log) {
-				// Specs aren't equal, update and fix.
-				o.Log.Info("An OCMAgent service exists but contains unexpected configuration. Restoring.")
-				foundResource.Spec = *svc.Spec.DeepCopy()
-				if err = o.Client.Update(o.Ctx, foundResource); err != nil {
-					return err
-				}
-			}
+		err := o.applyResource(ocmAgent, &svc, true, func(current client.Object) []string {
+			return serviceConfigChanged(current.(*corev1.Service), &svc)
+		})
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -118,11 +81,11 @@ func (o *ocmAgentHandler) ensureServiceDeleted(ocmAgent ocmagentv1alpha1.OcmAgen
 	OASvcName := ocmAgent.Name
 	OAMetricsSvcName := ocmAgent.Name + "-metrics"
 	for _, svcName := range []string{OASvcName, OAMetricsSvcName} {
-		namespacedName := oah.BuildNamespacedName(svcName)
+		namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, svcName)
 		foundResource := &corev1.Service{}
 		// Does the resource already exist?
 		o.Log.Info("ensuring service removed", "resource", namespacedName.String())
-		if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
+		if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
 			if !k8serrors.IsNotFound(err) {
 				// Return unexpected error
 				return err
@@ -131,7 +94,7 @@ func (o *ocmAgentHandler) ensureServiceDeleted(ocmAgent ocmagentv1alpha1.OcmAgen
 				return nil
 			}
 		}
-		err := o.Client.Delete(o.Ctx, foundResource)
+		err := o.deleteResource(ocmAgent, foundResource)
 		if err != nil {
 			return err
 		}
@@ -139,21 +102,12 @@ func (o *ocmAgentHandler) ensureServiceDeleted(ocmAgent ocmagentv1alpha1.OcmAgen
 	return nil
 }
 
-// serviceConfigChanged flags if the two supplied services differ in configuration
-// that the OCM Agent Operator manages
-func serviceConfigChanged(current, expected *corev1.Service, log logr.Logger) bool {
-	changed := false
-
-	if !reflect.DeepEqual(current.Labels, expected.Labels) {
-		changed = true
-	}
-	if !reflect.DeepEqual(current.Spec.Selector, expected.Spec.Selector) {
-		log.V(2).Info(fmt.Sprintf("current service %s/%s did not contain expected selectors", current.Namespace, current.Name))
-		changed = true
-	}
-	if !reflect.DeepEqual(current.Spec.Ports, expected.Spec.Ports) {
-		log.V(2).Info(fmt.Sprintf("current service %s/%s did not contain expected ports", current.Namespace, current.Name))
-		changed = true
-	}
-	return changed
+// serviceConfigChanged returns the paths of the fields of the current service that
+// differ from the configuration of the expected service that the OCM Agent Operator manages
+func serviceConfigChanged(current, expected *corev1.Service) []string {
+	drift := fieldDrift{}
+	drift.subset("metadata.labels", expected.Labels, current.Labels)
+	drift.equal("spec.selector", expected.Spec.Selector, current.Spec.Selector)
+	drift.derivative("spec.ports", expected.Spec.Ports, current.Spec.Ports)
+	return drift
 }
@@ -10,6 +10,7 @@ import (
 	k8serrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
@@ -35,10 +36,11 @@ var _ = Describe("OCM Agent Service Handler", func() {
 		mockClient = clientmocks.NewMockClient(mockCtrl)
 		testOcmAgent = testconst.TestOCMAgent
 		testOcmAgentHandler = ocmAgentHandler{
-			Client: mockClient,
-			Log:    testconst.Logger,
-			Ctx:    testconst.Context,
-			Scheme: testconst.Scheme,
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
 		}
 	})
 
@@ -58,6 +60,8 @@ var _ = Describe("OCM Agent Service Handler", func() {
 			testNamespacedName = oah.BuildNamespacedName(testOcmAgent.Name)
 			testService = buildOCMAgentService(testOcmAgent)
 			testMetricsService = buildOCMAgentMetricsService(testOcmAgent)
+			withInstanceLabel(&testService, testOcmAgent)
+			withInstanceLabel(&testMetricsService, testOcmAgent)
 			testMetricsNamespacedName = oah.BuildNamespacedName(testOcmAgent.Name + "-metrics")
 		})
 		When("the OCM Agent service already exists", func() {
@@ -69,8 +73,8 @@ var _ = Describe("OCM Agent Service Handler", func() {
 					goldenService := buildOCMAgentService(testOcmAgent)
 					gomock.InOrder(
 						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testService),
-						mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
-							func(ctx context.Context, d *corev1.Service, opts ...client.UpdateOptions) error {
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *corev1.Service, patch client.Patch, opts ...client.PatchOption) error {
 								Expect(reflect.DeepEqual(d.Spec, goldenService.Spec)).To(BeTrue())
 								return nil
 							}),
@@ -96,8 +100,8 @@ var _ = Describe("OCM Agent Service Handler", func() {
 				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testService.Name)
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
-					mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
-						func(ctx context.Context, d *corev1.Service, opts ...client.CreateOptions) error {
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *corev1.Service, patch client.Patch, opts ...client.PatchOption) error {
 							Expect(reflect.DeepEqual(d.Spec, testService.Spec)).To(BeTrue())
 							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
 							Expect(*d.ObjectMeta.OwnerReferences[0].BlockOwnerDeletion).To(BeTrue())
@@ -144,11 +148,21 @@ var _ = Describe("OCM Agent Service Handler", func() {
 		})
 		Context("When the labels are different", func() {
 			BeforeEach(func() {
+				expectedService.Labels = map[string]string{"app": testOcmAgent.Name}
 				currentService.Labels = map[string]string{"different": "value"}
 			})
 			It("flags them as different", func() {
-				r := serviceConfigChanged(&currentService, &expectedService, testconst.Logger)
-				Expect(r).To(BeTrue())
+				r := serviceConfigChanged(&currentService, &expectedService)
+				Expect(r).To(ConsistOf("metadata.labels"))
+			})
+		})
+		Context("When labels have been added by others", func() {
+			BeforeEach(func() {
+				currentService.Labels = map[string]string{"different": "value"}
+			})
+			It("does not flag them as different", func() {
+				r := serviceConfigChanged(&currentService, &expectedService)
+				Expect(r).To(BeEmpty())
 			})
 		})
 		Context("When the ports are different", func() {
@@ -156,14 +170,25 @@ var _ = Describe("OCM Agent Service Handler", func() {
 				currentService.Spec.Ports[0].Port = int32(9999)
 			})
 			It("flags them as different", func() {
-				r := serviceConfigChanged(&currentService, &expectedService, testconst.Logger)
-				Expect(r).To(BeTrue())
+				r := serviceConfigChanged(&currentService, &expectedService)
+				Expect(r).To(ConsistOf("spec.ports"))
+			})
+		})
+		Context("When the API server has defaulted fields", func() {
+			BeforeEach(func() {
+				currentService.Spec.ClusterIP = "172.30.0.10"
+				currentService.Spec.Type = corev1.ServiceTypeClusterIP
+				currentService.Spec.SessionAffinity = corev1.ServiceAffinityNone
+			})
+			It("flags that there are none", func() {
+				r := serviceConfigChanged(&currentService, &expectedService)
+				Expect(r).To(BeEmpty())
 			})
 		})
 		Context("When there are no differences", func() {
 			It("flags that there are none", func() {
-				r := serviceConfigChanged(&currentService, &expectedService, testconst.Logger)
-				Expect(r).To(BeFalse())
+				r := serviceConfigChanged(&currentService, &expectedService)
+				Expect(r).To(BeEmpty())
 			})
 		})
 	})
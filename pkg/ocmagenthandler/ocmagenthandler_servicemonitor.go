@@ -1,19 +1,17 @@
 package ocmagenthandler
 
 import (
-	"reflect"
-
 	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
 )
 
 func buildOCMAgentServiceMonitor(ocmAgent ocmagentv1alpha1.OcmAgent) monitorv1.ServiceMonitor {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name + "-metrics")
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name+"-metrics")
 	labels := map[string]string{
 		"app": ocmAgent.Name,
 	}
@@ -38,55 +36,22 @@ func buildOCMAgentServiceMonitor(ocmAgent ocmagentv1alpha1.OcmAgent) monitorv1.S
 // ensureServiceMonitor ensures that an OCMAgent serviceMonitor exists on the cluster
 // and that its configuration matches what is expected.
 func (o *ocmAgentHandler) ensureServiceMonitor(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name + "-metrics")
-	foundResource := &monitorv1.ServiceMonitor{}
-
-	populationFunc := func() monitorv1.ServiceMonitor {
-		return buildOCMAgentServiceMonitor(ocmAgent)
-	}
-
-	// Does the resource already exist?
-	o.Log.Info("ensuring serviceMonitor exists", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
-		if k8serrors.IsNotFound(err) {
-			// It does not exist, so must be created.
-			o.Log.Info("An OCMAgent serviceMonitor does not exist; will be created.")
-			// Populate the resource with the template
-			resource := populationFunc()
-			// Set the controller reference
-			if err := controllerutil.SetControllerReference(&ocmAgent, &resource, o.Scheme); err != nil {
-				return err
-			}
-			// and create it
-			err = o.Client.Create(o.Ctx, &resource)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Return unexpectedly
-			return err
-		}
-	} else {
-		// It does exist, check if it is what we expected
-		resource := populationFunc()
-		if !reflect.DeepEqual(foundResource.Spec, resource.Spec) {
-			// Specs aren't equal, update and fix.
-			o.Log.Info("An OCMAgent serviceMonitor exists but contains unexpected configuration. Restoring.")
-			foundResource = resource.DeepCopy()
-			if err = o.Client.Update(o.Ctx, foundResource); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	resource := buildOCMAgentServiceMonitor(ocmAgent)
+	return o.applyResource(ocmAgent, &resource, true, func(current client.Object) []string {
+		cur := current.(*monitorv1.ServiceMonitor)
+		drift := fieldDrift{}
+		drift.derivative("spec.selector", resource.Spec.Selector, cur.Spec.Selector)
+		drift.derivative("spec.endpoints", resource.Spec.Endpoints, cur.Spec.Endpoints)
+		return drift
+	})
 }
 
 func (o *ocmAgentHandler) ensureServiceMonitorDeleted(ocmAgent ocmagentv1alpha1.OcmAgent) error {
-	namespacedName := oah.BuildNamespacedName(ocmAgent.Name + "-metrics")
+	namespacedName := oah.BuildTargetNamespacedName(ocmAgent.Spec.TargetNamespace, ocmAgent.Name+"-metrics")
 	foundResource := &monitorv1.ServiceMonitor{}
 	// Does the resource already exist?
 	o.Log.Info("ensuring serviceMonitor removed", "resource", namespacedName.String())
-	if err := o.Client.Get(o.Ctx, namespacedName, foundResource); err != nil {
+	if err := o.reader().Get(o.Ctx, namespacedName, foundResource); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			// Return unexpected error
 			return err
@@ -95,7 +60,7 @@ func (o *ocmAgentHandler) ensureServiceMonitorDeleted(ocmAgent ocmagentv1alpha1.
 			return nil
 		}
 	}
-	err := o.Client.Delete(o.Ctx, foundResource)
+	err := o.deleteResource(ocmAgent, foundResource)
 	if err != nil {
 		return err
 	}
@@ -11,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
@@ -42,10 +43,11 @@ var _ = Describe("OCM Agent ServiceMonitor Handler", func() {
 			Status: ocmagentv1alpha1.OcmAgentStatus{},
 		}
 		testOcmAgentHandler = ocmAgentHandler{
-			Client: mockClient,
-			Log:    testconst.Logger,
-			Ctx:    testconst.Context,
-			Scheme: testconst.Scheme,
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
 		}
 	})
 
@@ -62,6 +64,7 @@ var _ = Describe("OCM Agent ServiceMonitor Handler", func() {
 		BeforeEach(func() {
 			testNamespacedName = oah.BuildNamespacedName(testOcmAgent.Name + "-metrics")
 			testServiceMonitor = buildOCMAgentServiceMonitor(testOcmAgent)
+			withInstanceLabel(&testServiceMonitor, testOcmAgent)
 		})
 		When("the OCM Agent serviceMonitor already exists", func() {
 			When("the serviceMonitor differs from what is expected", func() {
@@ -72,8 +75,8 @@ var _ = Describe("OCM Agent ServiceMonitor Handler", func() {
 					goldenSM := buildOCMAgentServiceMonitor(testOcmAgent)
 					gomock.InOrder(
 						mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).SetArg(2, testServiceMonitor),
-						mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
-							func(ctx context.Context, d *monitorv1.ServiceMonitor, opts ...client.UpdateOptions) error {
+						mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+							func(ctx context.Context, d *monitorv1.ServiceMonitor, patch client.Patch, opts ...client.PatchOption) error {
 								Expect(reflect.DeepEqual(d.Spec, goldenSM.Spec)).To(BeTrue())
 								return nil
 							}),
@@ -97,8 +100,8 @@ var _ = Describe("OCM Agent ServiceMonitor Handler", func() {
 				notFound := k8serrs.NewNotFound(schema.GroupResource{}, testServiceMonitor.Name)
 				gomock.InOrder(
 					mockClient.EXPECT().Get(gomock.Any(), testNamespacedName, gomock.Any()).Times(1).Return(notFound),
-					mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
-						func(ctx context.Context, d *monitorv1.ServiceMonitor, opts ...client.CreateOptions) error {
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).Times(1).DoAndReturn(
+						func(ctx context.Context, d *monitorv1.ServiceMonitor, patch client.Patch, opts ...client.PatchOption) error {
 							Expect(reflect.DeepEqual(d.Spec, testServiceMonitor.Spec)).To(BeTrue())
 							Expect(d.ObjectMeta.OwnerReferences[0].Kind).To(Equal("OcmAgent"))
 							Expect(*d.ObjectMeta.OwnerReferences[0].BlockOwnerDeletion).To(BeTrue())
@@ -0,0 +1,207 @@
+package ocmagenthandler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	oah "github.com/openshift/ocm-agent-operator/pkg/consts/ocmagenthandler"
+	testconst "github.com/openshift/ocm-agent-operator/pkg/consts/test/init"
+	"github.com/openshift/ocm-agent-operator/pkg/operatorconfig"
+	clientmocks "github.com/openshift/ocm-agent-operator/pkg/util/test/generated/mocks/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// withInstanceLabel labels a resource fixture as managed for the OcmAgent, as applyResource
+// does, without changing a label map that the fixture shares with its selectors
+func withInstanceLabel(obj client.Object, ocmAgent ocmagentv1alpha1.OcmAgent) {
+	labels := map[string]string{oah.OCMAgentInstanceLabel: ocmAgent.Name}
+	for k, v := range obj.GetLabels() {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+}
+
+var _ = Describe("OCM Agent Handler", func() {
+	var (
+		mockClient *clientmocks.MockClient
+		mockCtrl   *gomock.Controller
+
+		testOcmAgent        ocmagentv1alpha1.OcmAgent
+		testOcmAgentHandler ocmAgentHandler
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		testOcmAgent = *testconst.TestOCMAgent.DeepCopy()
+		testOcmAgentHandler = ocmAgentHandler{
+			Client:   mockClient,
+			Log:      testconst.Logger,
+			Ctx:      testconst.Context,
+			Scheme:   testconst.Scheme,
+			Recorder: &record.FakeRecorder{},
+		}
+	})
+
+	Context("When ensuring the OCM Agent resources exist", func() {
+		When("every resource fails to reconcile", func() {
+			It("attempts every resource and aggregates the errors", func() {
+				fakeError := fmt.Errorf("fake error")
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeError).AnyTimes()
+				err := testOcmAgentHandler.EnsureOCMAgentResourcesExist(testconst.Context, &testOcmAgent)
+				Expect(err).To(HaveOccurred())
+				Expect(testOcmAgent.Status.ManagedResources).To(HaveLen(12))
+				for _, s := range testOcmAgent.Status.ManagedResources {
+					Expect(s.State).To(Equal(ocmagentv1alpha1.ManagedResourceFailed))
+					Expect(s.LastError).To(Equal(fakeError.Error()))
+					Expect(s.LastSyncTime.IsZero()).To(BeFalse())
+					Expect(err.Error()).To(ContainSubstring(s.Kind + " " + s.Name))
+				}
+			})
+			It("keeps the sync time of the resources whose state is unchanged", func() {
+				fakeError := fmt.Errorf("fake error")
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeError).AnyTimes()
+				_ = testOcmAgentHandler.EnsureOCMAgentResourcesExist(testconst.Context, &testOcmAgent)
+				syncTime := metav1.NewTime(time.Now().Add(-time.Hour))
+				for i := range testOcmAgent.Status.ManagedResources {
+					testOcmAgent.Status.ManagedResources[i].LastSyncTime = syncTime
+				}
+				testOcmAgent.Status.ManagedResources[0].LastError = "previous error"
+				_ = testOcmAgentHandler.EnsureOCMAgentResourcesExist(testconst.Context, &testOcmAgent)
+				Expect(testOcmAgent.Status.ManagedResources[0].LastSyncTime).NotTo(Equal(syncTime))
+				for _, s := range testOcmAgent.Status.ManagedResources[1:] {
+					Expect(s.LastSyncTime).To(Equal(syncTime))
+				}
+			})
+		})
+	})
+
+	Context("When ensuring the OCM Agent resources in a target namespace", func() {
+		It("reconciles every resource into the target namespace", func() {
+			const targetNamespace = "ocm-agent-target"
+			testOcmAgent.Spec.TargetNamespace = targetNamespace
+			// The fleet mode resources can be ensured from empty objects, with the cluster
+			// resources and the fleet client secret of the target namespace provided
+			testOcmAgent.Spec.FleetMode = true
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if key.Namespace == "" {
+						return nil
+					}
+					Expect(key.Namespace).To(Equal(targetNamespace))
+					if _, ok := obj.(*corev1.Secret); ok {
+						return nil
+					}
+					return k8serrors.NewNotFound(schema.GroupResource{}, key.Name)
+				}).AnyTimes()
+			// The orphans of the OcmAgent are listed in the target namespace
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), client.InNamespace(targetNamespace), gomock.Any()).Return(nil).AnyTimes()
+			var applied []client.Object
+			mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), client.Apply, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					applied = append(applied, obj)
+					return nil
+				}).AnyTimes()
+			err := testOcmAgentHandler.EnsureOCMAgentResourcesExist(testconst.Context, &testOcmAgent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).NotTo(BeEmpty())
+			for _, obj := range applied {
+				Expect(obj.GetNamespace()).To(Equal(targetNamespace))
+				Expect(obj.GetOwnerReferences()).To(BeEmpty())
+				Expect(obj.GetLabels()).To(HaveKeyWithValue(oah.OCMAgentInstanceLabel, testOcmAgent.Name))
+			}
+			for _, s := range testOcmAgent.Status.ManagedResources {
+				Expect(s.State).To(Equal(ocmagentv1alpha1.ManagedResourceReconciled))
+			}
+			for _, ref := range testOcmAgent.Status.Inventory {
+				Expect(ref.Namespace).To(Equal(targetNamespace))
+			}
+		})
+	})
+
+	Context("When the OrphanCleanup feature is disabled", func() {
+		It("keeps the orphans in the inventory without removing them", func() {
+			cfg := operatorconfig.Default()
+			cfg.FeatureGates[ocmagentv1alpha1.FeatureOrphanCleanup] = false
+			testOcmAgentHandler.Config = operatorconfig.NewStore(cfg)
+			// The fleet mode resources can be ensured from empty objects
+			testOcmAgent.Spec.FleetMode = true
+			orphan := ocmagentv1alpha1.ManagedObjectReference{APIVersion: "v1", Kind: "Service", Namespace: oah.OCMAgentNamespace, Name: "orphan"}
+			testOcmAgent.Status.Inventory = []ocmagentv1alpha1.ManagedObjectReference{orphan}
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.EnsureOCMAgentResourcesExist(testconst.Context, &testOcmAgent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testOcmAgent.Status.Inventory).To(ContainElement(orphan))
+			Expect(len(testOcmAgent.Status.Inventory)).To(BeNumerically(">", 1))
+		})
+	})
+
+	Context("When ensuring the OCM Agent resources with a reconcile context", func() {
+		It("makes the API calls with that context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			mockClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(ctx.Err()).MinTimes(1)
+			mockClient.EXPECT().List(ctx, gomock.Any(), gomock.Any()).Return(ctx.Err()).AnyTimes()
+			err := testOcmAgentHandler.EnsureOCMAgentResourcesAbsent(ctx, testOcmAgent)
+			Expect(err).To(MatchError(ContainSubstring(context.Canceled.Error())))
+		})
+	})
+
+	Context("When reading the OCM Agent resources", func() {
+		var mockAPIReader *clientmocks.MockClient
+		BeforeEach(func() {
+			mockAPIReader = clientmocks.NewMockClient(mockCtrl)
+			testOcmAgentHandler.APIReader = mockAPIReader
+		})
+		It("reads from the cache while ensuring they exist", func() {
+			fakeError := fmt.Errorf("fake error")
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeError).AnyTimes()
+			mockAPIReader.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.EnsureOCMAgentResourcesExist(testconst.Context, &testOcmAgent)
+			Expect(err).To(HaveOccurred())
+		})
+		It("reads from the API server while ensuring they are absent", func() {
+			fakeError := fmt.Errorf("fake error")
+			mockAPIReader.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeError).MinTimes(1)
+			mockAPIReader.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeError).AnyTimes()
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			err := testOcmAgentHandler.EnsureOCMAgentResourcesAbsent(testconst.Context, testOcmAgent)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When reporting conflicts over singleton resources", func() {
+		It("reports a conflict", func() {
+			setResourceConflictCondition(&testOcmAgent, []string{"ConfigMap openshift-monitoring/ocm-agent is managed for OcmAgent other"})
+			condition := meta.FindStatusCondition(testOcmAgent.Status.Conditions, ocmagentv1alpha1.ConditionResourceConflict)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(reasonSingletonResourceConflict))
+			Expect(condition.Message).To(ContainSubstring("OcmAgent other"))
+		})
+		It("clears a resolved conflict", func() {
+			setResourceConflictCondition(&testOcmAgent, []string{"conflict"})
+			setResourceConflictCondition(&testOcmAgent, nil)
+			condition := meta.FindStatusCondition(testOcmAgent.Status.Conditions, ocmagentv1alpha1.ConditionResourceConflict)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Message).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,228 @@
+package operatorconfig
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+)
+
+const (
+	// MaxConcurrentReconcilesDefault is the default number of concurrent reconciles of a controller
+	MaxConcurrentReconcilesDefault = 1
+	// OcmAgentRequeueIntervalDefault is the default interval at which an OcmAgent is reconciled again
+	OcmAgentRequeueIntervalDefault = 5 * time.Minute
+	// FleetNotificationRequeueIntervalDefault is the default interval at which a
+	// ManagedFleetNotificationRecord is reconciled again, to remove its stale items
+	FleetNotificationRequeueIntervalDefault = time.Hour
+	// NotificationRecordStaleTimeoutDefault is the default time after the resend wait of a
+	// notification that a ManagedFleetNotificationRecord item is considered stale
+	NotificationRecordStaleTimeoutDefault = 360 * time.Hour
+	// PullSecretInvalidForDefault is the default time that the pull secret must be invalid before alerting
+	PullSecretInvalidForDefault = 15 * time.Minute
+	// AgentUnavailableForDefault is the default time that the OCM Agent must be unavailable before alerting
+	AgentUnavailableForDefault = 10 * time.Minute
+	// ReconcileErrorsDefault is the default number of failed operations within the reconcile
+	// error window that are alerted on
+	ReconcileErrorsDefault = 5
+	// ReconcileErrorWindowDefault is the default window over which failed operations are counted
+	ReconcileErrorWindowDefault = 30 * time.Minute
+	// FleetRecordsStaleForDefault is the default time since the last successful reconcile of the
+	// fleet notification records before alerting
+	FleetRecordsStaleForDefault = 3 * time.Hour
+	// FlappingWindowDefault is the default time after an alert resolves within which it flaps if it fires again
+	FlappingWindowDefault = time.Hour
+	// FlappingCountWindowDefault is the default window over which the flaps of an alert are counted
+	FlappingCountWindowDefault = 24 * time.Hour
+)
+
+// AllowedIngressNamespacesDefault are the namespaces allowed to reach an OCM Agent in fleet mode by default
+var AllowedIngressNamespacesDefault = []string{"observatorium-mst-production", "openshift-monitoring"}
+
+// Features are the optional features of the operator, which are all enabled by default
+var Features = []ocmagentv1alpha1.OperatorFeature{
+	ocmagentv1alpha1.FeatureOrphanCleanup,
+	ocmagentv1alpha1.FeatureStaleRecordCleanup,
+}
+
+// ControllerConfig is the effective configuration of a controller
+type ControllerConfig struct {
+	MaxConcurrentReconciles int
+	RequeueInterval         time.Duration
+}
+
+// AlertThresholds are the effective thresholds of the alerts managed for an OcmAgent
+type AlertThresholds struct {
+	PullSecretInvalidFor time.Duration
+	AgentUnavailableFor  time.Duration
+	ReconcileErrors      int
+	ReconcileErrorWindow time.Duration
+	FleetRecordsStaleFor time.Duration
+}
+
+// Config is the effective configuration of the operator
+type Config struct {
+	OcmAgentController             ControllerConfig
+	FleetNotificationController    ControllerConfig
+	NotificationRecordStaleTimeout time.Duration
+	FlappingWindow                 time.Duration
+	FlappingCountWindow            time.Duration
+	AllowedIngressNamespaces       []string
+	FeatureGates                   map[ocmagentv1alpha1.OperatorFeature]bool
+	Alerts                         AlertThresholds
+}
+
+// Default returns the configuration of the operator when no OcmAgentOperatorConfig is set
+func Default() Config {
+	return FromSpec(ocmagentv1alpha1.OcmAgentOperatorConfigSpec{})
+}
+
+// FromSpec returns the configuration of the operator defined by the spec of an
+// OcmAgentOperatorConfig, with the default value of any field that is not set
+func FromSpec(spec ocmagentv1alpha1.OcmAgentOperatorConfigSpec) Config {
+	cfg := Config{
+		OcmAgentController:             controllerConfig(spec.OcmAgentController, OcmAgentRequeueIntervalDefault),
+		FleetNotificationController:    controllerConfig(spec.FleetNotificationController, FleetNotificationRequeueIntervalDefault),
+		NotificationRecordStaleTimeout: NotificationRecordStaleTimeoutDefault,
+		FlappingWindow:                 durationOrDefault(spec.FlappingWindow, FlappingWindowDefault),
+		FlappingCountWindow:            durationOrDefault(spec.FlappingCountWindow, FlappingCountWindowDefault),
+		AllowedIngressNamespaces:       AllowedIngressNamespacesDefault,
+		FeatureGates:                   map[ocmagentv1alpha1.OperatorFeature]bool{},
+		Alerts:                         alertThresholds(spec.Alerts),
+	}
+	if spec.NotificationRecordStaleTimeout != nil {
+		cfg.NotificationRecordStaleTimeout = spec.NotificationRecordStaleTimeout.Duration
+	}
+	if len(spec.AllowedIngressNamespaces) > 0 {
+		cfg.AllowedIngressNamespaces = append([]string{}, spec.AllowedIngressNamespaces...)
+	}
+	for _, f := range Features {
+		cfg.FeatureGates[f] = true
+	}
+	for _, gate := range spec.FeatureGates {
+		cfg.FeatureGates[gate.Name] = gate.Enabled
+	}
+	return cfg
+}
+
+func controllerConfig(spec ocmagentv1alpha1.ControllerConfig, requeueInterval time.Duration) ControllerConfig {
+	cfg := ControllerConfig{
+		MaxConcurrentReconciles: MaxConcurrentReconcilesDefault,
+		RequeueInterval:         requeueInterval,
+	}
+	if spec.MaxConcurrentReconciles > 0 {
+		cfg.MaxConcurrentReconciles = int(spec.MaxConcurrentReconciles)
+	}
+	if spec.RequeueInterval != nil {
+		cfg.RequeueInterval = spec.RequeueInterval.Duration
+	}
+	return cfg
+}
+
+func alertThresholds(spec ocmagentv1alpha1.AlertThresholds) AlertThresholds {
+	thresholds := AlertThresholds{
+		PullSecretInvalidFor: durationOrDefault(spec.PullSecretInvalidFor, PullSecretInvalidForDefault),
+		AgentUnavailableFor:  durationOrDefault(spec.AgentUnavailableFor, AgentUnavailableForDefault),
+		ReconcileErrors:      ReconcileErrorsDefault,
+		ReconcileErrorWindow: durationOrDefault(spec.ReconcileErrorWindow, ReconcileErrorWindowDefault),
+		FleetRecordsStaleFor: durationOrDefault(spec.FleetRecordsStaleFor, FleetRecordsStaleForDefault),
+	}
+	if spec.ReconcileErrors > 0 {
+		thresholds.ReconcileErrors = int(spec.ReconcileErrors)
+	}
+	return thresholds
+}
+
+func durationOrDefault(d *metav1.Duration, defaultValue time.Duration) time.Duration {
+	if d == nil {
+		return defaultValue
+	}
+	return d.Duration
+}
+
+// Enabled returns whether the feature is enabled
+func (c Config) Enabled(feature ocmagentv1alpha1.OperatorFeature) bool {
+	return c.FeatureGates[feature]
+}
+
+// Spec returns the configuration as the spec of an OcmAgentOperatorConfig, with every field set
+func (c Config) Spec() ocmagentv1alpha1.OcmAgentOperatorConfigSpec {
+	spec := ocmagentv1alpha1.OcmAgentOperatorConfigSpec{
+		OcmAgentController:             c.OcmAgentController.spec(),
+		FleetNotificationController:    c.FleetNotificationController.spec(),
+		NotificationRecordStaleTimeout: &metav1.Duration{Duration: c.NotificationRecordStaleTimeout},
+		FlappingWindow:                 &metav1.Duration{Duration: c.FlappingWindow},
+		FlappingCountWindow:            &metav1.Duration{Duration: c.FlappingCountWindow},
+		AllowedIngressNamespaces:       append([]string{}, c.AllowedIngressNamespaces...),
+		Alerts: ocmagentv1alpha1.AlertThresholds{
+			PullSecretInvalidFor: &metav1.Duration{Duration: c.Alerts.PullSecretInvalidFor},
+			AgentUnavailableFor:  &metav1.Duration{Duration: c.Alerts.AgentUnavailableFor},
+			ReconcileErrors:      int32(c.Alerts.ReconcileErrors),
+			ReconcileErrorWindow: &metav1.Duration{Duration: c.Alerts.ReconcileErrorWindow},
+			FleetRecordsStaleFor: &metav1.Duration{Duration: c.Alerts.FleetRecordsStaleFor},
+		},
+	}
+	for _, f := range Features {
+		spec.FeatureGates = append(spec.FeatureGates, ocmagentv1alpha1.FeatureGate{Name: f, Enabled: c.Enabled(f)})
+	}
+	return spec
+}
+
+func (c ControllerConfig) spec() ocmagentv1alpha1.ControllerConfig {
+	return ocmagentv1alpha1.ControllerConfig{
+		MaxConcurrentReconciles: int32(c.MaxConcurrentReconciles),
+		RequeueInterval:         &metav1.Duration{Duration: c.RequeueInterval},
+	}
+}
+
+// Store holds the configuration of the operator, which is read by the controllers on each
+// reconcile and updated when the OcmAgentOperatorConfig changes
+type Store struct {
+	mu  sync.RWMutex
+	cfg Config
+}
+
+// NewStore returns a store holding the supplied configuration
+func NewStore(cfg Config) *Store {
+	return &Store{cfg: cfg}
+}
+
+// Get returns the current configuration, which must not be modified. A nil store holds
+// the default configuration.
+func (s *Store) Get() Config {
+	if s == nil {
+		return Default()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// Set replaces the current configuration
+func (s *Store) Set(cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+}
+
+// Load returns the configuration defined by the OcmAgentOperatorConfig of the operator,
+// or the default configuration if there is none
+func Load(ctx context.Context, c client.Reader) (Config, error) {
+	instance := &ocmagentv1alpha1.OcmAgentOperatorConfig{}
+	err := c.Get(ctx, types.NamespacedName{Name: ocmagentv1alpha1.OcmAgentOperatorConfigName}, instance)
+	if err != nil {
+		// The CRD may not be installed yet when the operator is upgraded
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return Default(), nil
+		}
+		return Config{}, err
+	}
+	return FromSpec(instance.Spec), nil
+}
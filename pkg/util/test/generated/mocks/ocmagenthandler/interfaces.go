@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,18 +37,18 @@ func (m *MockOcmAgentHandlerBuilder) EXPECT() *MockOcmAgentHandlerBuilderMockRec
 }
 
 // New mocks base method.
-func (m *MockOcmAgentHandlerBuilder) New() (ocmagenthandler.OCMAgentHandler, error) {
+func (m *MockOcmAgentHandlerBuilder) New(ctx context.Context) (ocmagenthandler.OCMAgentHandler, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "New")
+	ret := m.ctrl.Call(m, "New", ctx)
 	ret0, _ := ret[0].(ocmagenthandler.OCMAgentHandler)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // New indicates an expected call of New.
-func (mr *MockOcmAgentHandlerBuilderMockRecorder) New() *gomock.Call {
+func (mr *MockOcmAgentHandlerBuilderMockRecorder) New(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "New", reflect.TypeOf((*MockOcmAgentHandlerBuilder)(nil).New))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "New", reflect.TypeOf((*MockOcmAgentHandlerBuilder)(nil).New), ctx)
 }
 
 // MockOCMAgentHandler is a mock of OCMAgentHandler interface.
@@ -74,29 +75,29 @@ func (m *MockOCMAgentHandler) EXPECT() *MockOCMAgentHandlerMockRecorder {
 }
 
 // EnsureOCMAgentResourcesAbsent mocks base method.
-func (m *MockOCMAgentHandler) EnsureOCMAgentResourcesAbsent(arg0 v1alpha1.OcmAgent) error {
+func (m *MockOCMAgentHandler) EnsureOCMAgentResourcesAbsent(arg0 context.Context, arg1 v1alpha1.OcmAgent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EnsureOCMAgentResourcesAbsent", arg0)
+	ret := m.ctrl.Call(m, "EnsureOCMAgentResourcesAbsent", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // EnsureOCMAgentResourcesAbsent indicates an expected call of EnsureOCMAgentResourcesAbsent.
-func (mr *MockOCMAgentHandlerMockRecorder) EnsureOCMAgentResourcesAbsent(arg0 interface{}) *gomock.Call {
+func (mr *MockOCMAgentHandlerMockRecorder) EnsureOCMAgentResourcesAbsent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureOCMAgentResourcesAbsent", reflect.TypeOf((*MockOCMAgentHandler)(nil).EnsureOCMAgentResourcesAbsent), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureOCMAgentResourcesAbsent", reflect.TypeOf((*MockOCMAgentHandler)(nil).EnsureOCMAgentResourcesAbsent), arg0, arg1)
 }
 
 // EnsureOCMAgentResourcesExist mocks base method.
-func (m *MockOCMAgentHandler) EnsureOCMAgentResourcesExist(arg0 v1alpha1.OcmAgent) error {
+func (m *MockOCMAgentHandler) EnsureOCMAgentResourcesExist(arg0 context.Context, arg1 *v1alpha1.OcmAgent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EnsureOCMAgentResourcesExist", arg0)
+	ret := m.ctrl.Call(m, "EnsureOCMAgentResourcesExist", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // EnsureOCMAgentResourcesExist indicates an expected call of EnsureOCMAgentResourcesExist.
-func (mr *MockOCMAgentHandlerMockRecorder) EnsureOCMAgentResourcesExist(arg0 interface{}) *gomock.Call {
+func (mr *MockOCMAgentHandlerMockRecorder) EnsureOCMAgentResourcesExist(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureOCMAgentResourcesExist", reflect.TypeOf((*MockOCMAgentHandler)(nil).EnsureOCMAgentResourcesExist), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureOCMAgentResourcesExist", reflect.TypeOf((*MockOCMAgentHandler)(nil).EnsureOCMAgentResourcesExist), arg0, arg1)
 }